@@ -4,8 +4,11 @@ import (
 	"context"
 
 	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
 	httpInbound "github.com/DerBlum/filmkritiken-backend/http/inbound"
+	httpOutbound "github.com/DerBlum/filmkritiken-backend/http/outbound"
 	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage"
 	"github.com/caarlos0/env/v11"
 	log "github.com/sirupsen/logrus"
 )
@@ -32,14 +35,60 @@ func main() {
 	if err := env.Parse(&authConfig); err != nil {
 		panic(err)
 	}
+	if err := httpInbound.LoadOidcProviderConfigs(&authConfig); err != nil {
+		panic(err)
+	}
+
+	storageConfig := storage.Config{}
+	if err := env.Parse(&storageConfig); err != nil {
+		panic(err)
+	}
+
+	gcConfig := filmkritiken.ImageGarbageCollectorConfig{}
+	if err := env.Parse(&gcConfig); err != nil {
+		panic(err)
+	}
+
+	tmdbConfig := httpOutbound.TmdbConfig{}
+	if err := env.Parse(&tmdbConfig); err != nil {
+		panic(err)
+	}
 
 	mongoDbRepository, err := mongo.NewMongoDbRepository(context.Background(), &mongoConfig)
 	if err != nil {
 		panic(err)
 	}
-	filmkritikenService := filmkritiken.NewFilmkritikenService(mongoDbRepository, mongoDbRepository)
+	imageRepository, err := storage.NewImageStore(context.Background(), storageConfig.ImageStore, &storageConfig, mongoDbRepository, mongo.NewGridFsImageRepository(mongoDbRepository))
+	if err != nil {
+		panic(err)
+	}
+	log.Infof("using image store %s", storageConfig.ImageStore)
+	var filmMetadataProvider filmkritiken.FilmMetadataProvider
+	if tmdbConfig.AccessToken != "" {
+		filmMetadataProvider = httpOutbound.NewTmdbFilmMetadataProvider(&tmdbConfig)
+	} else {
+		log.Info("TMDB_ACCESS_TOKEN not set, film search is disabled")
+	}
+	filmkritikenService := filmkritiken.NewFilmkritikenService(mongoDbRepository, mongoDbRepository, mongoDbRepository, imageRepository, mongoDbRepository, mongoDbRepository, filmMetadataProvider)
+	permissionService := session.NewPermissionService(mongoDbRepository, mongoDbRepository)
+	tokenService := session.NewTokenService(mongoDbRepository, mongoDbRepository)
+
+	if gcConfig.Interval > 0 {
+		gc := filmkritiken.NewImageGarbageCollector(mongoDbRepository, imageRepository, mongoDbRepository, mongoDbRepository, gcConfig.GracePeriod)
+		go gc.RunPeriodically(context.Background(), gcConfig.Interval, func(result *filmkritiken.ImageGarbageCollectionResult, err error) {
+			if err != nil {
+				log.Errorf("image garbage collection failed: %v", err)
+				return
+			}
+			for _, missing := range result.MissingImages {
+				log.Warnf("image %s of '%s' (%s) does not exist", missing.ImageId, missing.Titel, missing.FilmkritikenId)
+			}
+			log.Infof("image garbage collection: %d deleted, %d orphaned, %d missing",
+				len(result.DeletedImages), len(result.OrphanedImages), len(result.MissingImages))
+		})
+	}
 
-	err = httpInbound.StartServer(&serverConfig, &authConfig, filmkritikenService, mongoDbRepository)
+	err = httpInbound.StartServer(&serverConfig, &authConfig, filmkritikenService, mongoDbRepository, mongoDbRepository, permissionService, tokenService)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+type Repository interface {
+	GetFilmkritiken(ctx context.Context, filter *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error)
+	// UpdateImagePlaceholder sets size and blurhash of the image wherever it is used
+	UpdateImagePlaceholder(ctx context.Context, image *filmkritiken.Image) error
+}
+
+type ImageRepository interface {
+	FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error)
+}
+
+func backfill(ctx context.Context, repo Repository, imageRepo ImageRepository, force bool) error {
+	allFilmkritiken, _, err := repo.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	updated := 0
+	// screenings of a film share its image, which is updated everywhere at once
+	updatedImageIds := make(map[string]bool)
+	for _, fk := range allFilmkritiken {
+		if fk.Film == nil || fk.Film.Image == nil || fk.Film.Image.Id == "" || updatedImageIds[fk.Film.Image.Id] {
+			continue
+		}
+		if !force && fk.Film.Image.Blurhash != "" {
+			log.Debugf("Placeholder for '%s' already exists, skipping", fk.Film.Titel)
+			continue
+		}
+
+		imageFile, err := imageRepo.FindImage(ctx, fk.Film.Image.Id)
+		if err != nil {
+			log.Warnf("Could not load image %s for '%s': %v", fk.Film.Image.Id, fk.Film.Titel, err)
+			continue
+		}
+		imageBites, err := imageFile.ReadContent()
+		if err != nil {
+			log.Warnf("Could not read image %s for '%s': %v", fk.Film.Image.Id, fk.Film.Titel, err)
+			continue
+		}
+
+		if err := filmkritiken.SetImagePlaceholder(fk.Film.Image, &imageBites); err != nil {
+			log.Warnf("Could not compute placeholder for image %s ('%s'): %v", fk.Film.Image.Id, fk.Film.Titel, err)
+			continue
+		}
+
+		if err := repo.UpdateImagePlaceholder(ctx, fk.Film.Image); err != nil {
+			return err
+		}
+		updatedImageIds[fk.Film.Image.Id] = true
+		updated++
+		log.Infof("Computed placeholder for '%s' (%dx%d)", fk.Film.Titel, fk.Film.Image.Width, fk.Film.Image.Height)
+	}
+
+	log.Infof("Computed placeholders for %d of %d filmkritiken", updated, len(allFilmkritiken))
+	return nil
+}
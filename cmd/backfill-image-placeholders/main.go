@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage"
+	"github.com/caarlos0/env/v11"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	force := flag.Bool("force", false, "recompute placeholders even if they already exist")
+	flag.Parse()
+
+	log.Info("Starting image placeholder backfill...")
+
+	mongoConfig := mongo.Config{}
+	if err := env.Parse(&mongoConfig); err != nil {
+		panic(err)
+	}
+
+	storageConfig := storage.Config{}
+	if err := env.Parse(&storageConfig); err != nil {
+		panic(err)
+	}
+
+	mongoDbRepository, err := mongo.NewMongoDbRepository(context.Background(), &mongoConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	imageRepository, err := storage.NewImageStore(context.Background(), storageConfig.ImageStore, &storageConfig, mongoDbRepository, mongo.NewGridFsImageRepository(mongoDbRepository))
+	if err != nil {
+		panic(err)
+	}
+
+	if err := backfill(context.Background(), mongoDbRepository, imageRepository, *force); err != nil {
+		log.Fatalf("Backfill failed: %v", err)
+	}
+
+	log.Info("Image placeholder backfill finished.")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"image"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+type Repository interface {
+	GetFilmkritiken(ctx context.Context, filter *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error)
+}
+
+type ImageRepository interface {
+	FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error)
+	FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error)
+	SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error
+}
+
+func backfill(ctx context.Context, repo Repository, imageRepo ImageRepository, force bool) error {
+	allFilmkritiken, _, err := repo.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	generated := 0
+	for _, fk := range allFilmkritiken {
+		if fk.Film == nil || fk.Film.Image == nil || fk.Film.Image.Id == "" {
+			continue
+		}
+		imageId := fk.Film.Image.Id
+
+		var imageBites []byte
+		if !force {
+			// small images have no variant of every width, so the expected variants depend on the original width
+			width := fk.Film.Image.Width
+			if width <= 0 {
+				// image saved before its dimensions were recorded
+				imageBites, err = loadImage(ctx, imageRepo, imageId)
+				if err != nil {
+					log.Warnf("Could not load image %s for '%s': %v", imageId, fk.Film.Titel, err)
+					continue
+				}
+				config, _, err := image.DecodeConfig(bytes.NewReader(imageBites))
+				if err != nil {
+					log.Warnf("Could not decode image %s for '%s': %v", imageId, fk.Film.Titel, err)
+					continue
+				}
+				width = config.Width
+			}
+
+			complete, err := variantsExist(ctx, imageRepo, imageId, filmkritiken.ImageVariantWidthsFor(width))
+			if err != nil {
+				return err
+			}
+			if complete {
+				log.Debugf("Variants for image %s ('%s') already exist, skipping", imageId, fk.Film.Titel)
+				continue
+			}
+		}
+
+		if imageBites == nil {
+			imageBites, err = loadImage(ctx, imageRepo, imageId)
+			if err != nil {
+				log.Warnf("Could not load image %s for '%s': %v", imageId, fk.Film.Titel, err)
+				continue
+			}
+		}
+
+		variants, err := filmkritiken.GenerateImageVariants(&imageBites)
+		if err != nil {
+			log.Warnf("Could not generate variants for image %s ('%s'): %v", imageId, fk.Film.Titel, err)
+			continue
+		}
+
+		if err := imageRepo.SaveImageVariants(ctx, imageId, variants); err != nil {
+			return err
+		}
+		generated++
+		log.Infof("Generated %d variants for '%s'", len(variants), fk.Film.Titel)
+	}
+
+	log.Infof("Generated variants for %d of %d filmkritiken", generated, len(allFilmkritiken))
+	return nil
+}
+
+func loadImage(ctx context.Context, imageRepo ImageRepository, imageId string) ([]byte, error) {
+	imageFile, err := imageRepo.FindImage(ctx, imageId)
+	if err != nil {
+		return nil, err
+	}
+	return imageFile.ReadContent()
+}
+
+// variantsExist reports whether the image store has a variant of every width.
+func variantsExist(ctx context.Context, imageRepo ImageRepository, imageId string, widths []int) (bool, error) {
+	for _, width := range widths {
+		variant, err := imageRepo.FindImageVariant(ctx, imageId, width)
+		var nfe *errors.NotFoundError
+		if stdErrors.As(err, &nfe) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		_ = variant.Content.Close()
+	}
+	return true, nil
+}
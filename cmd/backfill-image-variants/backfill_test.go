@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+)
+
+type memoryRepository struct {
+	filmkritiken []*filmkritiken.Filmkritiken
+}
+
+func (r *memoryRepository) GetFilmkritiken(_ context.Context, _ *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error) {
+	return r.filmkritiken, int64(len(r.filmkritiken)), nil
+}
+
+// memoryImageStore keeps images by id and their variants by id and width, it counts the saved variants
+type memoryImageStore struct {
+	images        map[string][]byte
+	variants      map[string]map[int][]byte
+	savedVariants int
+}
+
+func newMemoryImageStore() *memoryImageStore {
+	return &memoryImageStore{images: make(map[string][]byte), variants: make(map[string]map[int][]byte)}
+}
+
+func (s *memoryImageStore) FindImage(_ context.Context, imageId string) (*filmkritiken.ImageFile, error) {
+	bites, exists := s.images[imageId]
+	if !exists {
+		return nil, errors.NewNotFoundErrorFromString("Bild konnte nicht gefunden werden.")
+	}
+	return filmkritiken.NewImageFile("image/png", bites), nil
+}
+
+func (s *memoryImageStore) FindImageVariant(_ context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	bites, exists := s.variants[imageId][width]
+	if !exists {
+		return nil, errors.NewNotFoundErrorFromString("Bildvariante konnte nicht gefunden werden.")
+	}
+	return filmkritiken.NewImageFile("image/png", bites), nil
+}
+
+func (s *memoryImageStore) SaveImageVariants(_ context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	if s.variants[imageId] == nil {
+		s.variants[imageId] = make(map[int][]byte)
+	}
+	for _, variant := range variants {
+		s.variants[imageId][variant.Width] = *variant.Bites
+		s.savedVariants++
+	}
+	return nil
+}
+
+func newPng(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func newFilmkritik(imageId string, width int, height int) *filmkritiken.Filmkritiken {
+	return &filmkritiken.Filmkritiken{
+		Id:   "fk-" + imageId,
+		Film: &filmkritiken.Film{Titel: imageId, Image: &filmkritiken.Image{Id: imageId, Width: width, Height: height}},
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generates missing variants", func(t *testing.T) {
+		// given
+		repo := &memoryRepository{filmkritiken: []*filmkritiken.Filmkritiken{newFilmkritik("large", 600, 900)}}
+		imageStore := newMemoryImageStore()
+		imageStore.images["large"] = newPng(t, 600, 900)
+
+		// when
+		err := backfill(ctx, repo, imageStore, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(imageStore.variants["large"]) != 2 {
+			t.Errorf("expected variants of width 200 and 500, got %v", imageStore.variants["large"])
+		}
+	})
+
+	t.Run("images with all variants are skipped", func(t *testing.T) {
+		// given
+		repo := &memoryRepository{filmkritiken: []*filmkritiken.Filmkritiken{newFilmkritik("large", 600, 900)}}
+		imageStore := newMemoryImageStore()
+		imageStore.images["large"] = newPng(t, 600, 900)
+		imageStore.variants["large"] = map[int][]byte{200: []byte("small"), 500: []byte("medium")}
+
+		// when
+		err := backfill(ctx, repo, imageStore, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imageStore.savedVariants != 0 {
+			t.Errorf("expected no variants to be generated, got %d", imageStore.savedVariants)
+		}
+	})
+
+	t.Run("force regenerates existing variants", func(t *testing.T) {
+		// given
+		repo := &memoryRepository{filmkritiken: []*filmkritiken.Filmkritiken{newFilmkritik("large", 600, 900)}}
+		imageStore := newMemoryImageStore()
+		imageStore.images["large"] = newPng(t, 600, 900)
+		imageStore.variants["large"] = map[int][]byte{200: []byte("small"), 500: []byte("medium")}
+
+		// when
+		err := backfill(ctx, repo, imageStore, true)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imageStore.savedVariants != 2 || string(imageStore.variants["large"][200]) == "small" {
+			t.Errorf("expected variants to be regenerated, got %d", imageStore.savedVariants)
+		}
+	})
+
+	t.Run("small image without variant of every width is skipped", func(t *testing.T) {
+		// given
+		repo := &memoryRepository{filmkritiken: []*filmkritiken.Filmkritiken{newFilmkritik("small", 300, 450)}}
+		imageStore := newMemoryImageStore()
+		imageStore.images["small"] = newPng(t, 300, 450)
+		imageStore.variants["small"] = map[int][]byte{200: []byte("small")}
+
+		// when
+		err := backfill(ctx, repo, imageStore, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imageStore.savedVariants != 0 {
+			t.Errorf("expected no variants to be generated, got %d", imageStore.savedVariants)
+		}
+	})
+
+	t.Run("width of images without recorded dimensions is read from the image", func(t *testing.T) {
+		// given
+		repo := &memoryRepository{filmkritiken: []*filmkritiken.Filmkritiken{newFilmkritik("small", 0, 0)}}
+		imageStore := newMemoryImageStore()
+		imageStore.images["small"] = newPng(t, 300, 450)
+		imageStore.variants["small"] = map[int][]byte{200: []byte("small")}
+
+		// when
+		err := backfill(ctx, repo, imageStore, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if imageStore.savedVariants != 0 {
+			t.Errorf("expected no variants to be generated, got %d", imageStore.savedVariants)
+		}
+	})
+}
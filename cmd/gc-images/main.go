@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage"
+	"github.com/caarlos0/env/v11"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	gcConfig := filmkritiken.ImageGarbageCollectorConfig{}
+	if err := env.Parse(&gcConfig); err != nil {
+		panic(err)
+	}
+
+	deleteOrphans := flag.Bool("delete", false, "delete images that have been unreferenced for the grace period (otherwise only report)")
+	gracePeriod := flag.Duration("grace-period", gcConfig.GracePeriod, "how long an image has to be unreferenced before it is deleted")
+	flag.Parse()
+
+	log.Info("Starting image garbage collection...")
+
+	mongoConfig := mongo.Config{}
+	if err := env.Parse(&mongoConfig); err != nil {
+		panic(err)
+	}
+
+	storageConfig := storage.Config{}
+	if err := env.Parse(&storageConfig); err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	mongoDbRepository, err := mongo.NewMongoDbRepository(ctx, &mongoConfig)
+	if err != nil {
+		panic(err)
+	}
+	imageRepository, err := storage.NewImageStore(ctx, storageConfig.ImageStore, &storageConfig, mongoDbRepository, mongo.NewGridFsImageRepository(mongoDbRepository))
+	if err != nil {
+		panic(err)
+	}
+
+	gc := filmkritiken.NewImageGarbageCollector(mongoDbRepository, imageRepository, mongoDbRepository, mongoDbRepository, *gracePeriod)
+	result, err := gc.Collect(ctx, *deleteOrphans)
+	if err != nil {
+		log.Fatalf("Image garbage collection failed: %v", err)
+	}
+
+	for _, imageId := range result.DeletedImages {
+		log.Infof("Deleted orphaned image %s", imageId)
+	}
+	for _, imageId := range result.OrphanedImages {
+		log.Infof("Image %s is not referenced by any film", imageId)
+	}
+	for _, missing := range result.MissingImages {
+		log.Warnf("Image %s of '%s' (%s) does not exist", missing.ImageId, missing.Titel, missing.FilmkritikenId)
+	}
+
+	log.Infof("Image garbage collection finished: %d deleted, %d orphaned, %d missing",
+		len(result.DeletedImages), len(result.OrphanedImages), len(result.MissingImages))
+}
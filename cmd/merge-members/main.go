@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/caarlos0/env/v11"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	von := flag.String("von", "", "name of the member to rename")
+	nach := flag.String("nach", "", "new name, or name of the member to merge into")
+	regel := flag.String("regel", string(filmkritiken.KonfliktRegel_Abbrechen), "rule for Filmkritiken rated by both members: abbrechen, ziel or quelle")
+	apply := flag.Bool("apply", false, "apply the changes, without this only a preview is shown")
+	flag.Parse()
+
+	mongoConfig := mongo.Config{}
+	if err := env.Parse(&mongoConfig); err != nil {
+		panic(err)
+	}
+
+	mongoDbRepository, err := mongo.NewMongoDbRepository(context.Background(), &mongoConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	// images and the movie database are not needed to merge members
+	filmkritikenService := filmkritiken.NewFilmkritikenService(mongoDbRepository, mongoDbRepository, mongoDbRepository, nil, mongoDbRepository, mongoDbRepository, nil)
+
+	ctx := context.WithValue(context.Background(), filmkritiken.Context_Username, "merge-members")
+	zusammenfuehrung := &filmkritiken.MitgliedZusammenfuehrung{Von: *von, Nach: *nach, Regel: filmkritiken.KonfliktRegel(*regel)}
+	if err := mergeMembers(ctx, filmkritikenService, zusammenfuehrung, *apply); err != nil {
+		log.Fatalf("Merging members failed: %v", err)
+	}
+}
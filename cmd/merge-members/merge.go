@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+type Service interface {
+	MergeMitglied(ctx context.Context, zusammenfuehrung *filmkritiken.MitgliedZusammenfuehrung, vorschau bool) (*filmkritiken.ZusammenfuehrungErgebnis, error)
+}
+
+// mergeMembers always shows the preview first and only applies the changes if requested.
+func mergeMembers(ctx context.Context, service Service, zusammenfuehrung *filmkritiken.MitgliedZusammenfuehrung, apply bool) error {
+	vorschau, err := service.MergeMitglied(ctx, zusammenfuehrung, true)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("'%s' -> '%s': %d Bewertungen and %d Beiträge in %d Filmkritiken", vorschau.Von, vorschau.Nach, vorschau.AnzahlBewertungen, vorschau.AnzahlBeitraege, len(vorschau.FilmkritikenIds))
+	for _, konflikt := range vorschau.Konflikte {
+		log.Infof("Conflict in '%s' (%s): %d by '%s', %d by '%s'", konflikt.Titel, konflikt.FilmkritikenId,
+			konflikt.BewertungVon.Wertung, konflikt.BewertungVon.Von, konflikt.BewertungNach.Wertung, konflikt.BewertungNach.Von)
+	}
+
+	if !apply {
+		log.Info("Preview only, run with -apply to merge.")
+		return nil
+	}
+
+	ergebnis, err := service.MergeMitglied(ctx, zusammenfuehrung, false)
+	if err != nil {
+		return err
+	}
+	log.Infof("Merged '%s' into '%s' in %d Filmkritiken (rule: %s).", ergebnis.Von, ergebnis.Nach, len(ergebnis.FilmkritikenIds), ergebnis.Regel)
+	if ergebnis.BenutzerId != "" {
+		log.Infof("All Bewertungen of both members belong to user %s now.", ergebnis.BenutzerId)
+	}
+	if ergebnis.VonBenutzerId != "" {
+		log.Warnf("'%s' has its own login (user %s), its Bewertungen after the next login are not merged.", ergebnis.Von, ergebnis.VonBenutzerId)
+	}
+	log.Infof("The running backend caches its filter options, the new name shows up there within %s.", filmkritiken.FilterOptionsTTL)
+	return nil
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/caarlos0/env/v11"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "only report which films would be created and merged")
+	flag.Parse()
+
+	log.Info("Starting film migration...")
+
+	mongoConfig := mongo.Config{}
+	if err := env.Parse(&mongoConfig); err != nil {
+		panic(err)
+	}
+
+	mongoDbRepository, err := mongo.NewMongoDbRepository(context.Background(), &mongoConfig)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := migrateFilms(context.Background(), mongoDbRepository, *dryRun); err != nil {
+		log.Fatalf("Film migration failed: %v", err)
+	}
+
+	log.Info("Film migration finished.")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+type Repository interface {
+	GetFilmkritiken(ctx context.Context, filter *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error)
+	SaveFilmkritiken(ctx context.Context, filmkritiken *filmkritiken.Filmkritiken) error
+	SaveFilm(ctx context.Context, film *filmkritiken.Film) error
+	filmkritiken.ImageReferenceRepository
+}
+
+// migrateFilms moves the embedded Film of every Filmkritiken without a Film id into the films collection.
+// Filmkritiken with the same title and year are screenings of the same Film, the oldest one provides it.
+// A Film gets the id of the Filmkritiken it is created from, so a run that stopped between saving the Film
+// and its Filmkritiken saves the same Film again when it is repeated.
+func migrateFilms(ctx context.Context, repo Repository, dryRun bool) error {
+	allFilmkritiken, _, err := repo.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(allFilmkritiken, func(i, j int) bool {
+		a, b := allFilmkritiken[i].Details, allFilmkritiken[j].Details
+		if a == nil || a.BesprochenAm == nil || b == nil || b.BesprochenAm == nil {
+			return a != nil && a.BesprochenAm != nil
+		}
+		return a.BesprochenAm.Before(*b.BesprochenAm)
+	})
+
+	// films migrated before (or created since the Film entity exists) are reused for rewatches
+	films := make(map[string]*filmkritiken.Film)
+	for _, fk := range allFilmkritiken {
+		if fk.Film != nil && fk.Film.Id != "" {
+			if _, exists := films[filmKey(fk.Film)]; !exists {
+				films[filmKey(fk.Film)] = fk.Film
+			}
+		}
+	}
+
+	created, merged := 0, 0
+	for _, fk := range allFilmkritiken {
+		if fk.Film == nil || fk.Film.Id != "" {
+			continue
+		}
+
+		film, exists := films[filmKey(fk.Film)]
+		if exists {
+			log.Infof("'%s' (%s) is a rewatch of '%s' (%d)", fk.Film.Titel, fk.Id, film.Titel, film.Erscheinungsjahr)
+			merged++
+		} else {
+			film = fk.Film
+			film.Id = fk.Id
+			if !dryRun {
+				if err := repo.SaveFilm(ctx, film); err != nil {
+					return err
+				}
+			}
+			films[filmKey(film)] = film
+			log.Infof("Created film '%s' (%d) from %s", film.Titel, film.Erscheinungsjahr, fk.Id)
+			created++
+		}
+
+		sharedImageId, replacedImageId := "", ""
+		if exists {
+			sharedImageId, replacedImageId = posters(fk.Film, film)
+			if replacedImageId != "" {
+				log.Infof("Poster %s of '%s' (%s) is replaced by the poster of the film", replacedImageId, fk.Film.Titel, fk.Id)
+			}
+		}
+
+		if dryRun {
+			continue
+		}
+
+		// the reference to the poster of the Film is added before the Filmkritiken is saved and the reference
+		// to the replaced poster is removed afterwards, a stopped run leaves an unused image but no missing one
+		if sharedImageId != "" {
+			if err := filmkritiken.AddSharedImageReference(ctx, repo, sharedImageId); err != nil {
+				return err
+			}
+		}
+		fk.Film = film
+		if err := repo.SaveFilmkritiken(ctx, fk); err != nil {
+			return err
+		}
+		if replacedImageId != "" {
+			if _, err := repo.RemoveImageReference(ctx, replacedImageId); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dryRun {
+		log.Infof("Dry run: would create %d films and merge %d rewatches", created, merged)
+		return nil
+	}
+	log.Infof("Created %d films and merged %d rewatches", created, merged)
+	return nil
+}
+
+// posters returns the poster of the Film a rewatch references from now on and its own poster it replaces,
+// an empty id if the rewatch showed the poster of the Film already
+func posters(screened *filmkritiken.Film, film *filmkritiken.Film) (sharedImageId string, replacedImageId string) {
+	screenedImageId, filmImageId := "", ""
+	if screened.Image != nil {
+		screenedImageId = screened.Image.Id
+	}
+	if film.Image != nil {
+		filmImageId = film.Image.Id
+	}
+	if screenedImageId == filmImageId {
+		return "", ""
+	}
+	return filmImageId, screenedImageId
+}
+
+func filmKey(film *filmkritiken.Film) string {
+	titel := strings.ToLower(strings.Join(strings.Fields(film.Titel), " "))
+	return fmt.Sprintf("%s|%d", titel, film.Erscheinungsjahr)
+}
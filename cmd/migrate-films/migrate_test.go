@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+)
+
+// memoryRepository keeps Filmkritiken, films and image references by id. Images missing in references are
+// untracked like images stored before reference counting.
+type memoryRepository struct {
+	filmkritiken []*filmkritiken.Filmkritiken
+	films        map[string]*filmkritiken.Film
+	references   map[string]int64
+	savedFilms   int
+}
+
+func newMemoryRepository(allFilmkritiken ...*filmkritiken.Filmkritiken) *memoryRepository {
+	return &memoryRepository{
+		filmkritiken: allFilmkritiken,
+		films:        make(map[string]*filmkritiken.Film),
+		references:   make(map[string]int64),
+	}
+}
+
+func (r *memoryRepository) GetFilmkritiken(_ context.Context, _ *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error) {
+	// the migration works on copies, like on documents read from the database
+	copies := make([]*filmkritiken.Filmkritiken, 0, len(r.filmkritiken))
+	for _, fk := range r.filmkritiken {
+		fkCopy := *fk
+		filmCopy := *fk.Film
+		fkCopy.Film = &filmCopy
+		copies = append(copies, &fkCopy)
+	}
+	return copies, int64(len(copies)), nil
+}
+
+func (r *memoryRepository) SaveFilmkritiken(_ context.Context, saved *filmkritiken.Filmkritiken) error {
+	for i, fk := range r.filmkritiken {
+		if fk.Id == saved.Id {
+			r.filmkritiken[i] = saved
+		}
+	}
+	return nil
+}
+
+func (r *memoryRepository) SaveFilm(_ context.Context, film *filmkritiken.Film) error {
+	r.films[film.Id] = film
+	r.savedFilms++
+	return nil
+}
+
+func (r *memoryRepository) AddImageReference(_ context.Context, imageId string) (int64, error) {
+	r.references[imageId]++
+	return r.references[imageId], nil
+}
+
+func (r *memoryRepository) RemoveImageReference(_ context.Context, imageId string) (int64, error) {
+	if r.references[imageId] <= 1 {
+		delete(r.references, imageId)
+		return 0, nil
+	}
+	r.references[imageId]--
+	return r.references[imageId], nil
+}
+
+func (r *memoryRepository) ReserveImageDeletion(_ context.Context, imageId string) (bool, error) {
+	return r.references[imageId] == 0, nil
+}
+
+func (r *memoryRepository) DeleteImageReferences(_ context.Context, imageId string) error {
+	delete(r.references, imageId)
+	return nil
+}
+
+func newScreening(id string, titel string, imageId string, besprochenAm time.Time) *filmkritiken.Filmkritiken {
+	return &filmkritiken.Filmkritiken{
+		Id:      id,
+		Film:    &filmkritiken.Film{Titel: titel, Erscheinungsjahr: 1979, Image: &filmkritiken.Image{Id: imageId}},
+		Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &besprochenAm},
+	}
+}
+
+func TestMigrateFilms(t *testing.T) {
+	ctx := context.Background()
+	first := time.Date(2020, 1, 10, 20, 0, 0, 0, time.UTC)
+	second := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+
+	t.Run("creates a film with the id of its first screening", func(t *testing.T) {
+		// given
+		repo := newMemoryRepository(newScreening("fk_1", "Alien", "image_1", first))
+
+		// when
+		err := migrateFilms(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.films["fk_1"] == nil || repo.filmkritiken[0].Film.Id != "fk_1" {
+			t.Errorf("expected film fk_1 to be created and referenced, got %v", repo.films)
+		}
+		if len(repo.references) != 0 {
+			t.Errorf("expected no references to be added, got %v", repo.references)
+		}
+	})
+
+	t.Run("rewatch references the poster of the film", func(t *testing.T) {
+		// given
+		repo := newMemoryRepository(
+			newScreening("fk_2", "alien ", "image_2", second),
+			newScreening("fk_1", "Alien", "image_1", first),
+		)
+		repo.references["image_2"] = 1
+
+		// when
+		err := migrateFilms(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.films) != 1 || repo.films["fk_1"] == nil {
+			t.Fatalf("expected only film fk_1 to be created, got %v", repo.films)
+		}
+		for _, fk := range repo.filmkritiken {
+			if fk.Film.Id != "fk_1" || fk.Film.Image.Id != "image_1" {
+				t.Errorf("expected %s to screen film fk_1 with image_1, got %s with %s", fk.Id, fk.Film.Id, fk.Film.Image.Id)
+			}
+		}
+		// the untracked poster counts the reference of its first screening as well
+		if repo.references["image_1"] != 2 {
+			t.Errorf("expected 2 references to image_1, got %d", repo.references["image_1"])
+		}
+		if _, exists := repo.references["image_2"]; exists {
+			t.Errorf("expected the references to the replaced image_2 to be removed, got %d", repo.references["image_2"])
+		}
+	})
+
+	t.Run("repeated run after an interruption saves the same film", func(t *testing.T) {
+		// given
+		repo := newMemoryRepository(newScreening("fk_1", "Alien", "image_1", first))
+		// the film was saved but its Filmkritiken was not
+		repo.films["fk_1"] = &filmkritiken.Film{Id: "fk_1", Titel: "Alien", Erscheinungsjahr: 1979}
+
+		// when
+		err := migrateFilms(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.films) != 1 || repo.filmkritiken[0].Film.Id != "fk_1" {
+			t.Errorf("expected film fk_1 to be saved again, got %v", repo.films)
+		}
+
+		// when
+		err = migrateFilms(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.savedFilms != 1 {
+			t.Errorf("expected migrated Filmkritiken to be skipped, got %d saved films", repo.savedFilms)
+		}
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		// given
+		repo := newMemoryRepository(
+			newScreening("fk_1", "Alien", "image_1", first),
+			newScreening("fk_2", "Alien", "image_2", second),
+		)
+
+		// when
+		err := migrateFilms(ctx, repo, true)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(repo.films) != 0 || len(repo.references) != 0 {
+			t.Errorf("expected no films and references, got %v and %v", repo.films, repo.references)
+		}
+		for _, fk := range repo.filmkritiken {
+			if fk.Film.Id != "" {
+				t.Errorf("expected %s to be unchanged, got film %s", fk.Id, fk.Film.Id)
+			}
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage"
+	"github.com/caarlos0/env/v11"
+	log "github.com/sirupsen/logrus"
+)
+
+func main() {
+	from := flag.String("from", storage.ImageStoreMongo, "image store to copy from (mongo, gridfs, filesystem, s3)")
+	to := flag.String("to", storage.ImageStoreGridFs, "image store to copy to (mongo, gridfs, filesystem, s3)")
+	deleteSource := flag.Bool("delete-source", false, "delete images from the source store after they have been copied and verified")
+	flag.Parse()
+
+	if *from == *to {
+		log.Fatalf("Source and target image store must differ")
+	}
+	log.Infof("Starting image migration from %s to %s...", *from, *to)
+
+	mongoConfig := mongo.Config{}
+	if err := env.Parse(&mongoConfig); err != nil {
+		panic(err)
+	}
+
+	storageConfig := storage.Config{}
+	if err := env.Parse(&storageConfig); err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	mongoDbRepository, err := mongo.NewMongoDbRepository(ctx, &mongoConfig)
+	if err != nil {
+		panic(err)
+	}
+	gridFsImageRepository := mongo.NewGridFsImageRepository(mongoDbRepository)
+
+	source, err := storage.NewImageStore(ctx, *from, &storageConfig, mongoDbRepository, gridFsImageRepository)
+	if err != nil {
+		log.Fatalf("Could not create source image store: %v", err)
+	}
+	target, err := storage.NewImageStore(ctx, *to, &storageConfig, mongoDbRepository, gridFsImageRepository)
+	if err != nil {
+		log.Fatalf("Could not create target image store: %v", err)
+	}
+
+	if err := migrate(ctx, source, target, *deleteSource); err != nil {
+		log.Fatalf("Image migration failed: %v", err)
+	}
+
+	log.Info("Image migration finished.")
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	stdErrors "errors"
+	"fmt"
+	"io"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+type SourceRepository interface {
+	GetImageIds(ctx context.Context) ([]string, error)
+	FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error)
+	FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error)
+	DeleteImage(ctx context.Context, imageId string) error
+}
+
+type TargetRepository interface {
+	ImageExists(ctx context.Context, imageId string) (bool, error)
+	SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error
+	SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error
+	FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error)
+	FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error)
+}
+
+// migrate copies every image (one at a time) including its variants to the target and verifies the
+// checksums of the copies. If deleteSource is set, the image is removed from the source afterwards.
+func migrate(ctx context.Context, source SourceRepository, target TargetRepository, deleteSource bool) error {
+	imageIds, err := source.GetImageIds(ctx)
+	if err != nil {
+		return err
+	}
+	log.Infof("Found %d images to migrate", len(imageIds))
+
+	migrated := 0
+	for _, imageId := range imageIds {
+		if err := migrateImage(ctx, source, target, imageId); err != nil {
+			return fmt.Errorf("could not migrate image %s: %w", imageId, err)
+		}
+
+		if deleteSource {
+			if err := source.DeleteImage(ctx, imageId); err != nil {
+				return fmt.Errorf("could not delete migrated image %s: %w", imageId, err)
+			}
+		}
+
+		migrated++
+		log.Infof("Migrated image %s (%d/%d)", imageId, migrated, len(imageIds))
+	}
+
+	return nil
+}
+
+func migrateImage(ctx context.Context, source SourceRepository, target TargetRepository, imageId string) error {
+	exists, err := target.ImageExists(ctx, imageId)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		imageFile, err := source.FindImage(ctx, imageId)
+		if err != nil {
+			return err
+		}
+		imageFile.ContentType, err = filmkritiken.DetectImageContentType(imageFile)
+		if err != nil {
+			_ = imageFile.Content.Close()
+			return err
+		}
+
+		err = target.SaveImage(ctx, imageId, imageFile)
+		_ = imageFile.Content.Close()
+		if err != nil {
+			return err
+		}
+	} else {
+		log.Debugf("Image %s already exists in target, only checking variants", imageId)
+	}
+
+	variants := make([]*filmkritiken.ImageVariant, 0, len(filmkritiken.ImageVariantWidths))
+	for _, width := range filmkritiken.ImageVariantWidths {
+		variantFile, err := source.FindImageVariant(ctx, imageId, width)
+		if err != nil {
+			var nfe *errors.NotFoundError
+			if stdErrors.As(err, &nfe) {
+				continue
+			}
+			return err
+		}
+
+		variantBites, err := variantFile.ReadContent()
+		if err != nil {
+			return err
+		}
+		variants = append(variants, &filmkritiken.ImageVariant{
+			Width:       width,
+			ContentType: variantFile.ContentType,
+			Bites:       &variantBites,
+		})
+	}
+	if err := target.SaveImageVariants(ctx, imageId, variants); err != nil {
+		return err
+	}
+
+	return verify(ctx, source, target, imageId, variants)
+}
+
+// verify compares the SHA-256 checksums of the original and every copied variant in source and target.
+func verify(ctx context.Context, source SourceRepository, target TargetRepository, imageId string, variants []*filmkritiken.ImageVariant) error {
+	sourceFile, err := source.FindImage(ctx, imageId)
+	if err != nil {
+		return err
+	}
+	sourceChecksum, err := checksum(sourceFile.Content)
+	if err != nil {
+		return err
+	}
+
+	targetFile, err := target.FindImage(ctx, imageId)
+	if err != nil {
+		return err
+	}
+	targetChecksum, err := checksum(targetFile.Content)
+	if err != nil {
+		return err
+	}
+
+	if sourceChecksum != targetChecksum {
+		return fmt.Errorf("checksum mismatch after migration: %s != %s", sourceChecksum, targetChecksum)
+	}
+
+	for _, variant := range variants {
+		variantChecksum, err := checksum(io.NopCloser(bytes.NewReader(*variant.Bites)))
+		if err != nil {
+			return err
+		}
+
+		targetVariant, err := target.FindImageVariant(ctx, imageId, variant.Width)
+		if err != nil {
+			return err
+		}
+		targetVariantChecksum, err := checksum(targetVariant.Content)
+		if err != nil {
+			return err
+		}
+
+		if variantChecksum != targetVariantChecksum {
+			return fmt.Errorf("checksum mismatch for variant %d after migration: %s != %s", variant.Width, variantChecksum, targetVariantChecksum)
+		}
+	}
+
+	return nil
+}
+
+// checksum returns the hex encoded SHA-256 of the content and closes it.
+func checksum(content io.ReadCloser) (string, error) {
+	defer content.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+)
+
+type storedFile struct {
+	contentType string
+	bites       []byte
+}
+
+// memoryStore keeps images and variants by their file id. If corrupt is set, every read returns altered
+// content, like a broken copy in the target.
+type memoryStore struct {
+	files   map[string]*storedFile
+	corrupt bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{files: make(map[string]*storedFile)}
+}
+
+func (s *memoryStore) GetImageIds(_ context.Context) ([]string, error) {
+	imageIds := make([]string, 0)
+	for fileId := range s.files {
+		if !strings.Contains(fileId, "_") {
+			imageIds = append(imageIds, fileId)
+		}
+	}
+	slices.Sort(imageIds)
+	return imageIds, nil
+}
+
+func (s *memoryStore) ImageExists(_ context.Context, imageId string) (bool, error) {
+	_, exists := s.files[imageId]
+	return exists, nil
+}
+
+func (s *memoryStore) SaveImage(_ context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	bites, err := io.ReadAll(imageFile.Content)
+	if err != nil {
+		return err
+	}
+	s.files[imageId] = &storedFile{contentType: imageFile.ContentType, bites: bites}
+	return nil
+}
+
+func (s *memoryStore) SaveImageVariants(_ context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	for _, variant := range variants {
+		s.files[filmkritiken.ImageVariantId(imageId, variant.Width)] = &storedFile{contentType: variant.ContentType, bites: *variant.Bites}
+	}
+	return nil
+}
+
+func (s *memoryStore) FindImage(_ context.Context, imageId string) (*filmkritiken.ImageFile, error) {
+	return s.find(imageId)
+}
+
+func (s *memoryStore) FindImageVariant(_ context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	return s.find(filmkritiken.ImageVariantId(imageId, width))
+}
+
+func (s *memoryStore) DeleteImage(_ context.Context, imageId string) error {
+	for fileId := range s.files {
+		if fileId == imageId || strings.HasPrefix(fileId, imageId+"_") {
+			delete(s.files, fileId)
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) find(fileId string) (*filmkritiken.ImageFile, error) {
+	file, exists := s.files[fileId]
+	if !exists {
+		return nil, errors.NewNotFoundErrorFromString("Bild konnte nicht gefunden werden.")
+	}
+	bites := slices.Clone(file.bites)
+	if s.corrupt {
+		bites = append(bites, '!')
+	}
+	imageFile := filmkritiken.NewImageFile(file.contentType, bites)
+	imageFile.Id = fileId
+	return imageFile, nil
+}
+
+func newSource() *memoryStore {
+	source := newMemoryStore()
+	source.files["image1"] = &storedFile{contentType: "image/jpeg", bites: []byte("original1")}
+	source.files["image1_w200"] = &storedFile{contentType: "image/jpeg", bites: []byte("small1")}
+	source.files["image2"] = &storedFile{bites: []byte("\x89PNG\r\n\x1a\n0000")}
+	return source
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("copies images and variants", func(t *testing.T) {
+		// given
+		source := newSource()
+		target := newMemoryStore()
+
+		// when
+		err := migrate(ctx, source, target, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(target.files) != 3 || string(target.files["image1_w200"].bites) != "small1" {
+			t.Errorf("unexpected target %v", target.files)
+		}
+		if target.files["image2"].contentType != "image/png" {
+			t.Errorf("expected detected content type for image without one, got %q", target.files["image2"].contentType)
+		}
+		if len(source.files) != 3 {
+			t.Errorf("expected source to be kept, got %v", source.files)
+		}
+	})
+
+	t.Run("checksum mismatch is detected", func(t *testing.T) {
+		// given
+		source := newSource()
+		target := newMemoryStore()
+		target.corrupt = true
+
+		// when
+		err := migrate(ctx, source, target, true)
+
+		// then
+		if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("expected checksum mismatch, got %v", err)
+		}
+		if len(source.files) != 3 {
+			t.Errorf("expected source to be kept after a failed migration, got %v", source.files)
+		}
+	})
+
+	t.Run("delete source removes migrated images", func(t *testing.T) {
+		// given
+		source := newSource()
+		target := newMemoryStore()
+
+		// when
+		err := migrate(ctx, source, target, true)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(source.files) != 0 {
+			t.Errorf("expected source to be empty, got %v", source.files)
+		}
+		if len(target.files) != 3 {
+			t.Errorf("unexpected target %v", target.files)
+		}
+	})
+}
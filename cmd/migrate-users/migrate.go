@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	log "github.com/sirupsen/logrus"
+)
+
+type Repository interface {
+	GetFilmkritiken(ctx context.Context, filter *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error)
+	SaveFilmkritiken(ctx context.Context, filmkritiken *filmkritiken.Filmkritiken) error
+	GetUsers(ctx context.Context) ([]*session.User, error)
+}
+
+// migrateUsers assigns Bewertungen without a user id to the user who used the name of the Bewertung.
+// Users are only known after their first login, Bewertungen of members who never logged in stay untouched
+// and can be migrated by running this again later.
+func migrateUsers(ctx context.Context, repo Repository, dryRun bool) error {
+	users, err := repo.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	usersByName := make(map[string]*session.User)
+	ambiguous := make(map[string]bool)
+	for _, user := range users {
+		for _, name := range user.Names {
+			key := strings.ToLower(name)
+			if existing, exists := usersByName[key]; exists && existing.ID != user.ID {
+				ambiguous[key] = true
+				continue
+			}
+			usersByName[key] = user
+		}
+	}
+	for name := range ambiguous {
+		log.Warnf("Name '%s' was used by more than one user, its Bewertungen are not migrated", name)
+		delete(usersByName, name)
+	}
+
+	allFilmkritiken, _, err := repo.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	migrated, unknown := 0, 0
+	for _, fk := range allFilmkritiken {
+		changed := false
+		for _, bewertung := range fk.Bewertungen {
+			if bewertung.VonId != "" {
+				continue
+			}
+
+			user, exists := usersByName[strings.ToLower(bewertung.Von)]
+			if !exists {
+				log.Infof("No user found for Bewertung of '%s' (%s)", bewertung.Von, fk.Id)
+				unknown++
+				continue
+			}
+
+			bewertung.VonId = user.ID
+			bewertung.Von = user.Name
+			changed = true
+			migrated++
+		}
+
+		if changed && !dryRun {
+			if err := repo.SaveFilmkritiken(ctx, fk); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Infof("%d Bewertungen assigned to a user, %d without known user", migrated, unknown)
+	return nil
+}
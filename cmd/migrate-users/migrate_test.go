@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+)
+
+type memoryRepository struct {
+	filmkritiken []*filmkritiken.Filmkritiken
+	users        []*session.User
+	saved        map[string]int
+}
+
+func newMemoryRepository(users []*session.User, allFilmkritiken ...*filmkritiken.Filmkritiken) *memoryRepository {
+	return &memoryRepository{filmkritiken: allFilmkritiken, users: users, saved: make(map[string]int)}
+}
+
+func (r *memoryRepository) GetFilmkritiken(_ context.Context, _ *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error) {
+	return r.filmkritiken, int64(len(r.filmkritiken)), nil
+}
+
+func (r *memoryRepository) SaveFilmkritiken(_ context.Context, fk *filmkritiken.Filmkritiken) error {
+	r.saved[fk.Id]++
+	return nil
+}
+
+func (r *memoryRepository) GetUsers(_ context.Context) ([]*session.User, error) {
+	return r.users, nil
+}
+
+func newFilmkritiken(id string, bewertungen ...*filmkritiken.Bewertung) *filmkritiken.Filmkritiken {
+	return &filmkritiken.Filmkritiken{Id: id, Bewertungen: bewertungen}
+}
+
+func TestMigrateUsers(t *testing.T) {
+	ctx := context.Background()
+	users := []*session.User{
+		{ID: "user_1", Name: "Stefan", Names: []string{"Stefan", "Steff"}},
+		{ID: "user_2", Name: "Alex", Names: []string{"Alex"}},
+		{ID: "user_3", Name: "Alexander", Names: []string{"Alexander", "Alex"}},
+	}
+
+	t.Run("assigns Bewertungen to the user who used the name", func(t *testing.T) {
+		// given
+		steff := &filmkritiken.Bewertung{Von: "steff", Wertung: 7}
+		repo := newMemoryRepository(users, newFilmkritiken("fk_1", steff))
+
+		// when
+		err := migrateUsers(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if steff.VonId != "user_1" || steff.Von != "Stefan" {
+			t.Errorf("expected Bewertung of user_1 named Stefan, got %s named %s", steff.VonId, steff.Von)
+		}
+		if repo.saved["fk_1"] != 1 {
+			t.Errorf("expected fk_1 to be saved once, got %d", repo.saved["fk_1"])
+		}
+	})
+
+	t.Run("ambiguous and unknown names are not assigned", func(t *testing.T) {
+		// given
+		alex := &filmkritiken.Bewertung{Von: "Alex", Wertung: 5}
+		unbekannt := &filmkritiken.Bewertung{Von: "Gast", Wertung: 3}
+		repo := newMemoryRepository(users, newFilmkritiken("fk_1", alex, unbekannt))
+
+		// when
+		err := migrateUsers(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if alex.VonId != "" || unbekannt.VonId != "" {
+			t.Errorf("expected Bewertungen without user, got %s and %s", alex.VonId, unbekannt.VonId)
+		}
+		if repo.saved["fk_1"] != 0 {
+			t.Errorf("expected fk_1 not to be saved, got %d", repo.saved["fk_1"])
+		}
+	})
+
+	t.Run("assigned Bewertungen stay untouched", func(t *testing.T) {
+		// given
+		assigned := &filmkritiken.Bewertung{VonId: "user_3", Von: "Stefan", Wertung: 6}
+		repo := newMemoryRepository(users, newFilmkritiken("fk_1", assigned))
+
+		// when
+		err := migrateUsers(ctx, repo, false)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if assigned.VonId != "user_3" || repo.saved["fk_1"] != 0 {
+			t.Errorf("expected Bewertung of user_3 to be unchanged, got %s", assigned.VonId)
+		}
+	})
+
+	t.Run("dry run saves nothing", func(t *testing.T) {
+		// given
+		repo := newMemoryRepository(users, newFilmkritiken("fk_1", &filmkritiken.Bewertung{Von: "Stefan", Wertung: 7}))
+
+		// when
+		err := migrateUsers(ctx, repo, true)
+
+		// then
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if repo.saved["fk_1"] != 0 {
+			t.Errorf("expected fk_1 not to be saved, got %d", repo.saved["fk_1"])
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/DerBlum/filmkritiken-backend/infrastructure/db/mongo"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage"
 	"github.com/caarlos0/env/v11"
 	log "github.com/sirupsen/logrus"
 )
@@ -16,12 +17,22 @@ func main() {
 		panic(err)
 	}
 
+	storageConfig := storage.Config{}
+	if err := env.Parse(&storageConfig); err != nil {
+		panic(err)
+	}
+
 	mongoDbRepository, err := mongo.NewMongoDbRepository(context.Background(), &mongoConfig)
 	if err != nil {
 		panic(err)
 	}
 
-	if err := seedIfEmpty(context.Background(), mongoDbRepository); err != nil {
+	imageRepository, err := storage.NewImageStore(context.Background(), storageConfig.ImageStore, &storageConfig, mongoDbRepository, mongo.NewGridFsImageRepository(mongoDbRepository))
+	if err != nil {
+		panic(err)
+	}
+
+	if err := seedIfEmpty(context.Background(), mongoDbRepository, imageRepository); err != nil {
 		log.Fatalf("Seeding failed: %v", err)
 	}
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"path/filepath"
 	"time"
@@ -12,11 +13,17 @@ import (
 
 type Repository interface {
 	GetFilmkritiken(ctx context.Context, filter *filmkritiken.FilmkritikenFilter) ([]*filmkritiken.Filmkritiken, int64, error)
-	SaveImage(ctx context.Context, imageBites *[]byte) (string, error)
 	SaveFilmkritiken(ctx context.Context, filmkritiken *filmkritiken.Filmkritiken) error
+	SaveFilm(ctx context.Context, film *filmkritiken.Film) error
+	AddImageReference(ctx context.Context, imageId string) (int64, error)
 }
 
-func seedIfEmpty(ctx context.Context, repo Repository) error {
+type ImageRepository interface {
+	ImageExists(ctx context.Context, imageId string) (bool, error)
+	SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error
+}
+
+func seedIfEmpty(ctx context.Context, repo Repository, imageRepo ImageRepository) error {
 	existing, _, err := repo.GetFilmkritiken(ctx, &filmkritiken.FilmkritikenFilter{Limit: 1})
 	if err != nil {
 		log.Warnf("Could not check if database is empty: %v", err)
@@ -26,10 +33,10 @@ func seedIfEmpty(ctx context.Context, repo Repository) error {
 	}
 
 	log.Info("Database is empty. Populating initial film collection from Bruno requests and posters...")
-	return seed(ctx, repo)
+	return seed(ctx, repo, imageRepo)
 }
 
-func seed(ctx context.Context, repo Repository) error {
+func seed(ctx context.Context, repo Repository, imageRepo ImageRepository) error {
 	// 1x1 transparent PNG fallback image
 	dummyImage := []byte{
 		0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0x00, 0x00, 0x00, 0x0d,
@@ -371,13 +378,20 @@ func seed(ctx context.Context, repo Repository) error {
 
 	for _, item := range items {
 		imgBytes := loadImage(item.posterFilename)
-		imageId, err := repo.SaveImage(ctx, &imgBytes)
+		imageId, err := saveImage(ctx, repo, imageRepo, imgBytes)
 		if err != nil {
 			log.Warnf("Could not save image for '%s': %v", item.fk.Film.Titel, err)
 		} else {
 			item.fk.Film.Image.Id = imageId
 		}
+		if err := filmkritiken.SetImagePlaceholder(item.fk.Film.Image, &imgBytes); err != nil {
+			log.Warnf("Could not compute placeholder for '%s': %v", item.fk.Film.Titel, err)
+		}
 
+		if err := repo.SaveFilm(ctx, item.fk.Film); err != nil {
+			log.Errorf("Failed to save seed film '%s': %v", item.fk.Film.Titel, err)
+			continue
+		}
 		if err := repo.SaveFilmkritiken(ctx, item.fk); err != nil {
 			log.Errorf("Failed to save seed filmkritik '%s': %v", item.fk.Film.Titel, err)
 		}
@@ -386,3 +400,22 @@ func seed(ctx context.Context, repo Repository) error {
 	log.Infof("Successfully seeded database with %d filmkritiken with posters!", len(items))
 	return nil
 }
+
+// saveImage stores the image unless it exists already (e.g. the fallback image) and adds a reference to it.
+func saveImage(ctx context.Context, repo Repository, imageRepo ImageRepository, imgBytes []byte) (string, error) {
+	imageId := filmkritiken.ImageContentId(imgBytes)
+
+	exists, err := imageRepo.ImageExists(ctx, imageId)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		err = imageRepo.SaveImage(ctx, imageId, filmkritiken.NewImageFile(http.DetectContentType(imgBytes), imgBytes))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	_, err = repo.AddImageReference(ctx, imageId)
+	return imageId, err
+}
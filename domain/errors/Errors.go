@@ -17,6 +17,11 @@ type (
 	InvalidInputError struct {
 		err error
 	}
+
+	// UnavailableError is returned for features that are not configured, e.g. without an external service
+	UnavailableError struct {
+		err error
+	}
 )
 
 func NewRepositoryError(err error) *RepositoryError {
@@ -54,3 +59,15 @@ func (iie *InvalidInputError) Error() string {
 func (iie *InvalidInputError) Unwrap() error {
 	return iie.err
 }
+
+func NewUnavailableErrorFromString(err string) *UnavailableError {
+	return &UnavailableError{errors.New(err)}
+}
+
+func (ue *UnavailableError) Error() string {
+	return ue.err.Error()
+}
+
+func (ue *UnavailableError) Unwrap() error {
+	return ue.err
+}
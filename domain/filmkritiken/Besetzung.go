@@ -0,0 +1,94 @@
+package filmkritiken
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const (
+	maxDarsteller       = 50
+	maxDarstellerLength = 100
+)
+
+// NormalizeBesetzung trims names and roles, drops entries without a name and sorts the cast by its
+// Reihenfolge.
+func NormalizeBesetzung(besetzung []*Darsteller) ([]*Darsteller, error) {
+	normalized := make([]*Darsteller, 0, len(besetzung))
+	for _, darsteller := range besetzung {
+		if darsteller == nil {
+			continue
+		}
+		name := strings.Join(strings.Fields(darsteller.Name), " ")
+		rolle := strings.Join(strings.Fields(darsteller.Rolle), " ")
+		if name == "" {
+			continue
+		}
+		if len([]rune(name)) > maxDarstellerLength || len([]rune(rolle)) > maxDarstellerLength {
+			return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Namen und Rollen dürfen höchstens %d Zeichen lang sein.", maxDarstellerLength))
+		}
+		normalized = append(normalized, &Darsteller{Name: name, Rolle: rolle, Reihenfolge: darsteller.Reihenfolge})
+	}
+
+	if len(normalized) > maxDarsteller {
+		return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Es sind höchstens %d Darsteller erlaubt.", maxDarsteller))
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return normalized[i].Reihenfolge < normalized[j].Reihenfolge
+	})
+	return normalized, nil
+}
+
+// CollectPersonFilme returns the films of the given Filmkritiken in which the person directed
+// (Regie lists directors separated by commas) or acted, with the club's average rating of each film.
+func CollectPersonFilme(name string, allFilmkritiken []*Filmkritiken) *Person {
+	person := &Person{Name: name, Filme: make([]*PersonFilm, 0)}
+	for _, fk := range allFilmkritiken {
+		if fk.Film == nil {
+			continue
+		}
+
+		personFilm := &PersonFilm{
+			FilmkritikenId:   fk.Id,
+			Titel:            fk.Film.Titel,
+			Erscheinungsjahr: fk.Film.Erscheinungsjahr,
+		}
+		if fk.Details != nil {
+			personFilm.BesprochenAm = fk.Details.BesprochenAm
+		}
+
+		for _, regie := range strings.Split(fk.Film.Regie, ",") {
+			if strings.EqualFold(strings.TrimSpace(regie), name) {
+				personFilm.Regie = true
+				person.Name = strings.TrimSpace(regie)
+			}
+		}
+		for _, darsteller := range fk.Film.Besetzung {
+			if strings.EqualFold(darsteller.Name, name) {
+				personFilm.Darsteller = true
+				personFilm.Rolle = darsteller.Rolle
+				person.Name = darsteller.Name
+				break
+			}
+		}
+		if !personFilm.Regie && !personFilm.Darsteller {
+			continue
+		}
+
+		summe := &wertungSumme{}
+		for _, bewertung := range fk.Bewertungen {
+			if !bewertung.Enthaltung {
+				summe.anzahl++
+				summe.summe += bewertung.Wertung
+			}
+		}
+		personFilm.AnzahlBewertungen = summe.anzahl
+		personFilm.Durchschnitt = summe.durchschnitt()
+
+		person.Filme = append(person.Filme, personFilm)
+	}
+
+	return person
+}
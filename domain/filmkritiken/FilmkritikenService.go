@@ -2,14 +2,19 @@ package filmkritiken
 
 import (
 	"context"
+	stdErrors "errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/DerBlum/filmkritiken-backend/domain/errors"
 )
 
-const filterOptionsTTL = 5 * time.Minute
+// FilterOptionsTTL is how long the filter options are cached, changes by other processes show up after it
+const FilterOptionsTTL = 5 * time.Minute
+
+const Aktion_BewertungenErfassen = "bewertungen.erfassen"
 
 type (
 	FilmkritikenService interface {
@@ -18,9 +23,26 @@ type (
 		GetFilterOptions(ctx context.Context) (*FilterOptions, error)
 		CreateFilm(ctx context.Context, film *Film, filmkritikenDetails *FilmkritikenDetails, imageBites *[]byte) (*Filmkritiken, error)
 		OpenCloseBewertungen(ctx context.Context, filmkritikenId string, offen bool) error
-		SetKritik(ctx context.Context, filmkritikenId string, von string, bewertung int, enthaltung bool) error
-		LoadImage(ctx context.Context, imageId string) (*[]byte, error)
+		SetKritik(ctx context.Context, filmkritikenId string, vonId string, von string, bewertung int, enthaltung bool) error
+		// SetKritiken stores all Bewertungen collected by the moderator at the meeting at once
+		SetKritiken(ctx context.Context, filmkritikenId string, eingaben []*BewertungEingabe) error
+		// RenameBenutzer updates the display name on all Bewertungen of the user
+		RenameBenutzer(ctx context.Context, vonId string, von string) error
+		LoadImage(ctx context.Context, imageId string, width int) (*ImageFile, error)
 		UpdateBesprochenAm(ctx context.Context, filmkritikenId string, besprochenAm time.Time) error
+		SearchFilms(ctx context.Context, query string) ([]*FilmCandidate, error)
+		DownloadPoster(ctx context.Context, posterRef string) (*[]byte, error)
+		UpdateKategorien(ctx context.Context, filmkritikenId string, genres []string, tags []string) error
+		GetMitgliederStatistiken(ctx context.Context) ([]*MitgliedStatistik, error)
+		GetPerson(ctx context.Context, name string) (*Person, error)
+		GetFilm(ctx context.Context, filmId string) (*FilmUebersicht, error)
+		CreateReihe(ctx context.Context, name string) (*Reihe, error)
+		GetReihen(ctx context.Context) ([]*Reihe, error)
+		GetReihe(ctx context.Context, reiheId string) (*ReiheUebersicht, error)
+		SetReiheEintrag(ctx context.Context, reiheId string, filmkritikenId string, position int) error
+		RemoveReiheEintrag(ctx context.Context, reiheId string, filmkritikenId string) error
+		// MergeMitglied renames or merges a member on all Filmkritiken, a preview (vorschau) changes nothing
+		MergeMitglied(ctx context.Context, zusammenfuehrung *MitgliedZusammenfuehrung, vorschau bool) (*ZusammenfuehrungErgebnis, error)
 	}
 
 	FilmkritikenRepository interface {
@@ -29,27 +51,101 @@ type (
 		GetFilterOptions(ctx context.Context) (*FilterOptions, error)
 		SaveFilmkritiken(ctx context.Context, filmkritiken *Filmkritiken) error
 		UpdateBesprochenAm(ctx context.Context, filmkritikenId string, besprochenAm time.Time) error
+		UpdateKategorien(ctx context.Context, filmkritikenId string, genres []string, tags []string) error
+		// GetFilmkritikenByPerson returns all Filmkritiken of films the person directed or acted in (ignoring case)
+		GetFilmkritikenByPerson(ctx context.Context, name string) ([]*Filmkritiken, error)
+		GetFilmkritikenByFilm(ctx context.Context, filmId string) ([]*Filmkritiken, error)
+		UpdateBewertungenVon(ctx context.Context, vonId string, von string) error
+		// GetFilmkritikenByMitglied returns all Filmkritiken rated or contributed by one of the members (ignoring case)
+		GetFilmkritikenByMitglied(ctx context.Context, names []string) ([]*Filmkritiken, error)
+		// SaveFilmkritikenWithAudit saves all Filmkritiken and the AuditEintrag in one transaction
+		SaveFilmkritikenWithAudit(ctx context.Context, filmkritiken []*Filmkritiken, auditEintrag *AuditEintrag) error
+	}
+
+	// FilmRepository stores every Film once, no matter how often it was screened.
+	FilmRepository interface {
+		FindFilm(ctx context.Context, filmId string) (*Film, error)
+		SaveFilm(ctx context.Context, film *Film) error
+		DeleteFilm(ctx context.Context, filmId string) error
+	}
+
+	ReiheRepository interface {
+		FindReihe(ctx context.Context, reiheId string) (*Reihe, error)
+		GetReihen(ctx context.Context) ([]*Reihe, error)
+		SaveReihe(ctx context.Context, reihe *Reihe) error
 	}
 
 	ImageRepository interface {
-		FindImage(ctx context.Context, imageId string) (*[]byte, error)
-		SaveImage(ctx context.Context, imageBites *[]byte) (string, error)
+		FindImage(ctx context.Context, imageId string) (*ImageFile, error)
+		FindImageVariant(ctx context.Context, imageId string, width int) (*ImageFile, error)
+		// GetImageIds returns the ids of all stored images (without variants)
+		GetImageIds(ctx context.Context) ([]string, error)
+		ImageExists(ctx context.Context, imageId string) (bool, error)
+		// SaveImage returns ErrImageAlreadyStored if the image has been stored concurrently
+		SaveImage(ctx context.Context, imageId string, imageFile *ImageFile) error
+		SaveImageVariants(ctx context.Context, imageId string, variants []*ImageVariant) error
 		DeleteImage(ctx context.Context, id string) error
 	}
 
+	// ImageReferenceRepository counts how many Filmkritiken reference an image, so images shared by
+	// identical uploads are only deleted once the last reference is gone.
+	ImageReferenceRepository interface {
+		// AddImageReference fails with an UnavailableError while the image is reserved for its deletion.
+		AddImageReference(ctx context.Context, imageId string) (int64, error)
+		// RemoveImageReference returns the number of remaining references (0 for untracked images).
+		RemoveImageReference(ctx context.Context, imageId string) (int64, error)
+		// ReserveImageDeletion reserves an image without references for its deletion until DeleteImageReferences,
+		// it returns false if the image is referenced.
+		ReserveImageDeletion(ctx context.Context, imageId string) (bool, error)
+		DeleteImageReferences(ctx context.Context, imageId string) error
+	}
+
+	// BenutzerRepository knows the users that logged in, their ids are the VonIds of Bewertungen.
+	BenutzerRepository interface {
+		BenutzerExists(ctx context.Context, vonId string) (bool, error)
+		// MergeBenutzer adds the names (and those of the user mergedBenutzerId, if set) to the names of the
+		// user benutzerId, so Bewertungen still stored under one of them are assigned to it
+		MergeBenutzer(ctx context.Context, benutzerId string, mergedBenutzerId string, names []string) error
+	}
+
+	// OrphanedImageRepository remembers since when images have been unreferenced.
+	OrphanedImageRepository interface {
+		GetOrphanedImages(ctx context.Context) (map[string]time.Time, error)
+		MarkOrphanedImage(ctx context.Context, imageId string, since time.Time) error
+		UnmarkOrphanedImage(ctx context.Context, imageId string) error
+	}
+
+	// FilmMetadataProvider looks up films in an external movie database.
+	FilmMetadataProvider interface {
+		SearchFilms(ctx context.Context, query string) ([]*FilmCandidate, error)
+		// DownloadPoster loads the poster of a FilmCandidate, only posters of the provider can be loaded
+		DownloadPoster(ctx context.Context, posterRef string) ([]byte, error)
+	}
+
 	filmkritikenServiceImpl struct {
-		filmkritikenRepository FilmkritikenRepository
-		imageRepository        ImageRepository
-		cacheMutex             sync.RWMutex
-		filterOptionsCache     *FilterOptions
-		cacheExpiry            time.Time
+		filmkritikenRepository   FilmkritikenRepository
+		filmRepository           FilmRepository
+		reiheRepository          ReiheRepository
+		imageRepository          ImageRepository
+		imageReferenceRepository ImageReferenceRepository
+		benutzerRepository       BenutzerRepository
+		filmMetadataProvider     FilmMetadataProvider
+		cacheMutex               sync.RWMutex
+		filterOptionsCache       *FilterOptions
+		cacheExpiry              time.Time
 	}
 )
 
-func NewFilmkritikenService(filmkritikenRepository FilmkritikenRepository, imageRepository ImageRepository) FilmkritikenService {
+// NewFilmkritikenService creates the service, filmMetadataProvider may be nil if no movie database is configured.
+func NewFilmkritikenService(filmkritikenRepository FilmkritikenRepository, filmRepository FilmRepository, reiheRepository ReiheRepository, imageRepository ImageRepository, imageReferenceRepository ImageReferenceRepository, benutzerRepository BenutzerRepository, filmMetadataProvider FilmMetadataProvider) FilmkritikenService {
 	return &filmkritikenServiceImpl{
-		filmkritikenRepository: filmkritikenRepository,
-		imageRepository:        imageRepository,
+		filmkritikenRepository:   filmkritikenRepository,
+		filmRepository:           filmRepository,
+		reiheRepository:          reiheRepository,
+		imageRepository:          imageRepository,
+		imageReferenceRepository: imageReferenceRepository,
+		benutzerRepository:       benutzerRepository,
+		filmMetadataProvider:     filmMetadataProvider,
 	}
 }
 
@@ -87,28 +183,78 @@ func (f *filmkritikenServiceImpl) GetFilterOptions(ctx context.Context) (*Filter
 	if opts.Beitragende == nil {
 		opts.Beitragende = make([]string, 0)
 	}
+	if opts.Genres == nil {
+		opts.Genres = make([]string, 0)
+	}
+	if opts.Tags == nil {
+		opts.Tags = make([]string, 0)
+	}
 
 	f.filterOptionsCache = opts
-	f.cacheExpiry = time.Now().Add(filterOptionsTTL)
+	f.cacheExpiry = time.Now().Add(FilterOptionsTTL)
 	return opts, nil
 }
 
+// CreateFilm creates Filmkritiken for a new Film, or another screening of a stored Film if its Id is set
+// (the stored Film is used then, imageBites are ignored).
 func (f *filmkritikenServiceImpl) CreateFilm(ctx context.Context, film *Film, filmkritikenDetails *FilmkritikenDetails, imageBites *[]byte) (*Filmkritiken, error) {
+	if film.Id != "" {
+		return f.createVorfuehrung(ctx, film.Id, filmkritikenDetails)
+	}
+
+	contentType, err := ValidateImage(imageBites)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := GenerateImageVariants(imageBites)
+	if err != nil {
+		return nil, errors.NewInvalidInputErrorFromString("Das Bild ist beschädigt und kann nicht gelesen werden.")
+	}
+	film.Genres, err = NormalizeKategorien(film.Genres)
+	if err != nil {
+		return nil, err
+	}
+	film.Tags, err = NormalizeKategorien(film.Tags)
+	if err != nil {
+		return nil, err
+	}
+	film.Besetzung, err = NormalizeBesetzung(film.Besetzung)
+	if err != nil {
+		return nil, err
+	}
+
+	err = SetImagePlaceholder(film.Image, imageBites)
+	if err != nil {
+		return nil, errors.NewInvalidInputErrorFromString("Das Bild ist beschädigt und kann nicht gelesen werden.")
+	}
+
 	filmkritiken := &Filmkritiken{
 		Film:        film,
 		Details:     filmkritikenDetails,
 		Bewertungen: make([]*Bewertung, 0),
 	}
 
-	imageId, err := f.imageRepository.SaveImage(ctx, imageBites)
+	imageId := ImageContentId(*imageBites)
+	err = f.storeImage(ctx, imageId, contentType, imageBites, variants)
 	if err != nil {
+		if unavailableErr, ok := err.(*errors.UnavailableError); ok {
+			return nil, unavailableErr
+		}
 		return nil, errors.NewRepositoryError(err)
 	}
 	film.Image.Id = imageId
 
+	err = f.filmRepository.SaveFilm(ctx, film)
+	if err != nil {
+		_ = f.releaseImage(ctx, imageId)
+		return nil, errors.NewRepositoryError(err)
+	}
+
 	err = f.filmkritikenRepository.SaveFilmkritiken(ctx, filmkritiken)
 	if err != nil {
-		_ = f.imageRepository.DeleteImage(ctx, imageId)
+		_ = f.filmRepository.DeleteFilm(ctx, film.Id)
+		_ = f.releaseImage(ctx, imageId)
 		return nil, errors.NewRepositoryError(err)
 	}
 
@@ -119,6 +265,100 @@ func (f *filmkritikenServiceImpl) CreateFilm(ctx context.Context, film *Film, fi
 	return filmkritiken, nil
 }
 
+func (f *filmkritikenServiceImpl) createVorfuehrung(ctx context.Context, filmId string, filmkritikenDetails *FilmkritikenDetails) (*Filmkritiken, error) {
+	film, err := f.filmRepository.FindFilm(ctx, filmId)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return nil, errors.NewInvalidInputErrorFromString("Der Film konnte nicht gefunden werden.")
+		}
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	// the screening references the poster of the Film as well, it must outlive the other screenings
+	imageId := ""
+	if film.Image != nil {
+		imageId = film.Image.Id
+	}
+	if imageId != "" {
+		if err := AddSharedImageReference(ctx, f.imageReferenceRepository, imageId); err != nil {
+			return nil, errors.NewRepositoryError(err)
+		}
+	}
+
+	filmkritiken := &Filmkritiken{
+		Film:        film,
+		Details:     filmkritikenDetails,
+		Bewertungen: make([]*Bewertung, 0),
+	}
+	err = f.filmkritikenRepository.SaveFilmkritiken(ctx, filmkritiken)
+	if err != nil {
+		if imageId != "" {
+			_ = f.releaseImage(ctx, imageId)
+		}
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	f.cacheMutex.Lock()
+	f.filterOptionsCache = nil
+	f.cacheMutex.Unlock()
+
+	return filmkritiken, nil
+}
+
+// storeImage adds a reference to the image and stores it with its variants, unless an identical image
+// has been stored before.
+func (f *filmkritikenServiceImpl) storeImage(ctx context.Context, imageId string, contentType string, imageBites *[]byte, variants []*ImageVariant) error {
+	_, err := f.imageReferenceRepository.AddImageReference(ctx, imageId)
+	if err != nil {
+		return err
+	}
+
+	exists, err := f.imageRepository.ImageExists(ctx, imageId)
+	if err == nil && !exists {
+		err = f.imageRepository.SaveImage(ctx, imageId, NewImageFile(contentType, *imageBites))
+		if stdErrors.Is(err, ErrImageAlreadyStored) {
+			// an identical upload stores the image and its variants, the added reference counts this upload
+			return nil
+		}
+		if err == nil {
+			err = f.imageRepository.SaveImageVariants(ctx, imageId, variants)
+		}
+	}
+	if err != nil {
+		_ = f.releaseImage(ctx, imageId)
+		return err
+	}
+
+	return nil
+}
+
+// releaseImage removes a reference to the image and deletes the image once it is no longer referenced.
+func (f *filmkritikenServiceImpl) releaseImage(ctx context.Context, imageId string) error {
+	remaining, err := f.imageReferenceRepository.RemoveImageReference(ctx, imageId)
+	if err != nil {
+		return err
+	}
+	if remaining > 0 {
+		return nil
+	}
+
+	return f.imageRepository.DeleteImage(ctx, imageId)
+}
+
+// AddSharedImageReference adds a reference to an image that is referenced already, like the poster of a Film
+// screened again. Images stored before reference counting are untracked and hold their first reference
+// implicitly, it is counted as well then.
+func AddSharedImageReference(ctx context.Context, imageReferenceRepository ImageReferenceRepository, imageId string) error {
+	count, err := imageReferenceRepository.AddImageReference(ctx, imageId)
+	if err != nil {
+		return err
+	}
+	if count == 1 {
+		_, err = imageReferenceRepository.AddImageReference(ctx, imageId)
+	}
+	return err
+}
+
 func (f *filmkritikenServiceImpl) OpenCloseBewertungen(ctx context.Context, filmkritikenId string, offen bool) error {
 
 	filmkritiken, err := f.filmkritikenRepository.FindFilmkritiken(ctx, filmkritikenId)
@@ -138,10 +378,10 @@ func (f *filmkritikenServiceImpl) OpenCloseBewertungen(ctx context.Context, film
 
 }
 
-func (f *filmkritikenServiceImpl) SetKritik(ctx context.Context, filmkritikenId string, von string, bewertung int, enthaltung bool) error {
+func (f *filmkritikenServiceImpl) SetKritik(ctx context.Context, filmkritikenId string, vonId string, von string, bewertung int, enthaltung bool) error {
 
-	if !enthaltung && (bewertung < 1 || bewertung > 10) {
-		return errors.NewInvalidInputErrorFromString("Wertung muss zwischen 1 und 10 liegen.")
+	if err := validateWertung(bewertung, enthaltung); err != nil {
+		return err
 	}
 
 	filmkritiken, err := f.filmkritikenRepository.FindFilmkritiken(ctx, filmkritikenId)
@@ -153,41 +393,166 @@ func (f *filmkritikenServiceImpl) SetKritik(ctx context.Context, filmkritikenId
 		return errors.NewInvalidInputErrorFromString(fmt.Sprintf("Die Bewertung von %s ist nicht mehr möglich.", filmkritiken.Film.Titel))
 	}
 
-	found := false
+	setBewertung(filmkritiken, vonId, von, bewertung, enthaltung, false)
+
+	err = f.filmkritikenRepository.SaveFilmkritiken(ctx, filmkritiken)
+	if err != nil {
+		// TODO: anderer error string?
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
+
+func (f *filmkritikenServiceImpl) SetKritiken(ctx context.Context, filmkritikenId string, eingaben []*BewertungEingabe) error {
+	if len(eingaben) == 0 {
+		return errors.NewInvalidInputErrorFromString("Es muss mindestens eine Bewertung angegeben werden.")
+	}
+	// everything is validated before the first change, so either all or no Bewertungen are stored
+	seen := make(map[string]bool)
+	for _, eingabe := range eingaben {
+		eingabe.VonId = strings.TrimSpace(eingabe.VonId)
+		eingabe.Von = strings.TrimSpace(eingabe.Von)
+		if eingabe.Von == "" {
+			return errors.NewInvalidInputErrorFromString("Für jede Bewertung muss das Mitglied angegeben werden.")
+		}
+		if err := validateWertung(eingabe.Wertung, eingabe.Enthaltung); err != nil {
+			return errors.NewInvalidInputErrorFromString(fmt.Sprintf("%s: %s", eingabe.Von, err.Error()))
+		}
+		// an entry with id and one with only the name may be the same member
+		keys := []string{"name:" + strings.ToLower(eingabe.Von)}
+		if eingabe.VonId != "" {
+			keys = append(keys, "id:"+eingabe.VonId)
+		}
+		for _, key := range keys {
+			if seen[key] {
+				return errors.NewInvalidInputErrorFromString(fmt.Sprintf("%s ist mehrfach angegeben.", eingabe.Von))
+			}
+		}
+		for _, key := range keys {
+			seen[key] = true
+		}
+	}
+	for _, eingabe := range eingaben {
+		if eingabe.VonId == "" {
+			continue
+		}
+		exists, err := f.benutzerRepository.BenutzerExists(ctx, eingabe.VonId)
+		if err != nil {
+			return errors.NewRepositoryError(err)
+		}
+		if !exists {
+			return errors.NewInvalidInputErrorFromString(fmt.Sprintf("%s ist kein bekannter Benutzer.", eingabe.Von))
+		}
+	}
+
+	filmkritiken, err := f.filmkritikenRepository.FindFilmkritiken(ctx, filmkritikenId)
+	if err != nil {
+		return err
+	}
+
+	if !filmkritiken.Details.BewertungOffen {
+		return errors.NewInvalidInputErrorFromString(fmt.Sprintf("Die Bewertung von %s ist nicht mehr möglich.", filmkritiken.Film.Titel))
+	}
+
+	moderator, _ := ctx.Value(Context_Username).(string)
+	erfassung := &BewertungenErfassung{FilmkritikenId: filmkritikenId}
+	for _, eingabe := range eingaben {
+		bewertung := setBewertung(filmkritiken, eingabe.VonId, eingabe.Von, eingabe.Wertung, eingabe.Enthaltung, true)
+		bewertung.ErfasstVon = moderator
+		erfasst := *bewertung
+		erfassung.Bewertungen = append(erfassung.Bewertungen, &erfasst)
+	}
+
+	auditEintrag := &AuditEintrag{
+		Zeitpunkt: time.Now(),
+		Benutzer:  moderator,
+		Aktion:    Aktion_BewertungenErfassen,
+		Details:   erfassung,
+	}
+	err = f.filmkritikenRepository.SaveFilmkritikenWithAudit(ctx, []*Filmkritiken{filmkritiken}, auditEintrag)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
+
+func validateWertung(bewertung int, enthaltung bool) error {
+	if !enthaltung && (bewertung < 1 || bewertung > 10) {
+		return errors.NewInvalidInputErrorFromString("Wertung muss zwischen 1 und 10 liegen.")
+	}
+	return nil
+}
+
+// setBewertung updates the Bewertung of the member or adds a new one. Bewertungen stored before user ids
+// existed only know the display name. Entries of the moderator (moderiert) may only know the name as well, they
+// also update the Bewertung a member with login stored under this name.
+func setBewertung(filmkritiken *Filmkritiken, vonId string, von string, bewertung int, enthaltung bool, moderiert bool) *Bewertung {
 	for _, existingBewertung := range filmkritiken.Bewertungen {
-		if existingBewertung.Von == von {
+		sameMember := vonId != "" && existingBewertung.VonId == vonId
+		if !sameMember && strings.EqualFold(existingBewertung.Von, von) {
+			sameMember = existingBewertung.VonId == "" || (moderiert && vonId == "")
+		}
+		if sameMember {
+			if vonId != "" {
+				existingBewertung.VonId = vonId
+			}
+			existingBewertung.Von = von
 			existingBewertung.Wertung = bewertung
 			existingBewertung.Enthaltung = enthaltung
-			found = true
-			break
+			existingBewertung.ErfasstVon = ""
+			return existingBewertung
 		}
 	}
-	if !found {
-		filmkritiken.Bewertungen = append(
-			filmkritiken.Bewertungen, &Bewertung{
-				Von:        von,
-				Wertung:    bewertung,
-				Enthaltung: enthaltung,
-			},
-		)
+
+	newBewertung := &Bewertung{
+		VonId:      vonId,
+		Von:        von,
+		Wertung:    bewertung,
+		Enthaltung: enthaltung,
+	}
+	filmkritiken.Bewertungen = append(filmkritiken.Bewertungen, newBewertung)
+	return newBewertung
+}
+
+func (f *filmkritikenServiceImpl) RenameBenutzer(ctx context.Context, vonId string, von string) error {
+	if vonId == "" || von == "" {
+		return errors.NewInvalidInputErrorFromString("Benutzer und Name müssen angegeben werden.")
 	}
 
-	err = f.filmkritikenRepository.SaveFilmkritiken(ctx, filmkritiken)
+	err := f.filmkritikenRepository.UpdateBewertungenVon(ctx, vonId, von)
 	if err != nil {
-		// TODO: anderer error string?
 		return errors.NewRepositoryError(err)
 	}
-
 	return nil
 }
 
-func (f *filmkritikenServiceImpl) LoadImage(ctx context.Context, imageId string) (*[]byte, error) {
-	imageBites, err := f.imageRepository.FindImage(ctx, imageId)
+func (f *filmkritikenServiceImpl) LoadImage(ctx context.Context, imageId string, width int) (*ImageFile, error) {
+	if variantWidth := closestImageVariantWidth(width); variantWidth > 0 {
+		variant, err := f.imageRepository.FindImageVariant(ctx, imageId, variantWidth)
+		if err == nil {
+			return variant, nil
+		}
+		var nfe *errors.NotFoundError
+		if !stdErrors.As(err, &nfe) {
+			return nil, err
+		}
+		// variant not (yet) generated, e.g. image smaller than the variant -> fall back to original
+	}
+
+	imageFile, err := f.imageRepository.FindImage(ctx, imageId)
 	if err != nil {
 		return nil, err
 	}
 
-	return imageBites, nil
+	imageFile.ContentType, err = DetectImageContentType(imageFile)
+	if err != nil {
+		_ = imageFile.Content.Close()
+		return nil, err
+	}
+
+	return imageFile, nil
 }
 
 func (f *filmkritikenServiceImpl) UpdateBesprochenAm(ctx context.Context, filmkritikenId string, besprochenAm time.Time) error {
@@ -202,3 +567,196 @@ func (f *filmkritikenServiceImpl) UpdateBesprochenAm(ctx context.Context, filmkr
 
 	return nil
 }
+
+func (f *filmkritikenServiceImpl) UpdateKategorien(ctx context.Context, filmkritikenId string, genres []string, tags []string) error {
+	genres, err := NormalizeKategorien(genres)
+	if err != nil {
+		return err
+	}
+	tags, err = NormalizeKategorien(tags)
+	if err != nil {
+		return err
+	}
+
+	err = f.filmkritikenRepository.UpdateKategorien(ctx, filmkritikenId, genres, tags)
+	if err != nil {
+		return err
+	}
+
+	f.cacheMutex.Lock()
+	f.filterOptionsCache = nil
+	f.cacheMutex.Unlock()
+
+	return nil
+}
+
+func (f *filmkritikenServiceImpl) GetMitgliederStatistiken(ctx context.Context) ([]*MitgliedStatistik, error) {
+	allFilmkritiken, _, err := f.filmkritikenRepository.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return CalculateMitgliederStatistiken(allFilmkritiken), nil
+}
+
+func (f *filmkritikenServiceImpl) GetPerson(ctx context.Context, name string) (*Person, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return nil, errors.NewInvalidInputErrorFromString("Name muss angegeben werden.")
+	}
+
+	allFilmkritiken, err := f.filmkritikenRepository.GetFilmkritikenByPerson(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	person := CollectPersonFilme(name, allFilmkritiken)
+	if len(person.Filme) == 0 {
+		return nil, errors.NewNotFoundErrorFromString("Person konnte nicht gefunden werden.")
+	}
+	return person, nil
+}
+
+func (f *filmkritikenServiceImpl) GetFilm(ctx context.Context, filmId string) (*FilmUebersicht, error) {
+	film, err := f.filmRepository.FindFilm(ctx, filmId)
+	if err != nil {
+		return nil, err
+	}
+
+	screenings, err := f.filmkritikenRepository.GetFilmkritikenByFilm(ctx, filmId)
+	if err != nil {
+		return nil, err
+	}
+
+	return CompareVorfuehrungen(film, screenings), nil
+}
+
+func (f *filmkritikenServiceImpl) CreateReihe(ctx context.Context, name string) (*Reihe, error) {
+	name, err := NormalizeReiheName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reihe := &Reihe{Name: name, Eintraege: make([]*ReiheEintrag, 0)}
+	err = f.reiheRepository.SaveReihe(ctx, reihe)
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	return reihe, nil
+}
+
+func (f *filmkritikenServiceImpl) GetReihen(ctx context.Context) ([]*Reihe, error) {
+	return f.reiheRepository.GetReihen(ctx)
+}
+
+func (f *filmkritikenServiceImpl) GetReihe(ctx context.Context, reiheId string) (*ReiheUebersicht, error) {
+	reihe, err := f.reiheRepository.FindReihe(ctx, reiheId)
+	if err != nil {
+		return nil, err
+	}
+
+	allFilmkritiken, _, err := f.filmkritikenRepository.GetFilmkritiken(ctx, &FilmkritikenFilter{Reihe: reiheId})
+	if err != nil {
+		return nil, err
+	}
+
+	return CalculateReiheUebersicht(reihe, allFilmkritiken), nil
+}
+
+func (f *filmkritikenServiceImpl) SetReiheEintrag(ctx context.Context, reiheId string, filmkritikenId string, position int) error {
+	reihe, err := f.reiheRepository.FindReihe(ctx, reiheId)
+	if err != nil {
+		return err
+	}
+	_, err = f.filmkritikenRepository.FindFilmkritiken(ctx, filmkritikenId)
+	if err != nil {
+		return err
+	}
+
+	SetReiheEintrag(reihe, filmkritikenId, position)
+	return f.reiheRepository.SaveReihe(ctx, reihe)
+}
+
+func (f *filmkritikenServiceImpl) RemoveReiheEintrag(ctx context.Context, reiheId string, filmkritikenId string) error {
+	reihe, err := f.reiheRepository.FindReihe(ctx, reiheId)
+	if err != nil {
+		return err
+	}
+
+	if !RemoveReiheEintrag(reihe, filmkritikenId) {
+		return errors.NewNotFoundErrorFromString("Die Filmkritiken gehören nicht zur Reihe.")
+	}
+	return f.reiheRepository.SaveReihe(ctx, reihe)
+}
+
+func (f *filmkritikenServiceImpl) MergeMitglied(ctx context.Context, zusammenfuehrung *MitgliedZusammenfuehrung, vorschau bool) (*ZusammenfuehrungErgebnis, error) {
+	if err := NormalizeMitgliedZusammenfuehrung(zusammenfuehrung); err != nil {
+		return nil, err
+	}
+
+	allFilmkritiken, err := f.filmkritikenRepository.GetFilmkritikenByMitglied(ctx, []string{zusammenfuehrung.Von, zusammenfuehrung.Nach})
+	if err != nil {
+		return nil, err
+	}
+
+	ergebnis, changed := MergeMitglied(allFilmkritiken, zusammenfuehrung)
+	if len(changed) == 0 && len(ergebnis.Konflikte) == 0 {
+		return nil, errors.NewNotFoundErrorFromString(fmt.Sprintf("Es gibt keine Bewertungen oder Beiträge von %s.", zusammenfuehrung.Von))
+	}
+	if vorschau {
+		return ergebnis, nil
+	}
+	if zusammenfuehrung.Regel == KonfliktRegel_Abbrechen && len(ergebnis.Konflikte) > 0 {
+		return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("%d Filmkritiken wurden von beiden Mitgliedern bewertet, bitte eine Regel für Konflikte wählen.", len(ergebnis.Konflikte)))
+	}
+
+	benutzer, _ := ctx.Value(Context_Username).(string)
+	auditEintrag := &AuditEintrag{
+		Zeitpunkt: time.Now(),
+		Benutzer:  benutzer,
+		Aktion:    Aktion_MitgliedZusammenfuehren,
+		Details:   ergebnis,
+	}
+	ergebnis.Ausgefuehrt = true
+
+	err = f.filmkritikenRepository.SaveFilmkritikenWithAudit(ctx, changed, auditEintrag)
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	if ergebnis.BenutzerId != "" {
+		err = f.benutzerRepository.MergeBenutzer(ctx, ergebnis.BenutzerId, ergebnis.VonBenutzerId, []string{zusammenfuehrung.Von, zusammenfuehrung.Nach})
+		if err != nil {
+			return nil, errors.NewRepositoryError(err)
+		}
+	}
+
+	f.cacheMutex.Lock()
+	f.filterOptionsCache = nil
+	f.cacheMutex.Unlock()
+
+	return ergebnis, nil
+}
+
+func (f *filmkritikenServiceImpl) SearchFilms(ctx context.Context, query string) ([]*FilmCandidate, error) {
+	if f.filmMetadataProvider == nil {
+		return nil, errors.NewUnavailableErrorFromString("Die Filmsuche ist nicht konfiguriert.")
+	}
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.NewInvalidInputErrorFromString("Suchbegriff muss angegeben werden.")
+	}
+
+	return f.filmMetadataProvider.SearchFilms(ctx, query)
+}
+
+func (f *filmkritikenServiceImpl) DownloadPoster(ctx context.Context, posterRef string) (*[]byte, error) {
+	if f.filmMetadataProvider == nil {
+		return nil, errors.NewUnavailableErrorFromString("Die Filmsuche ist nicht konfiguriert.")
+	}
+
+	imageBites, err := f.filmMetadataProvider.DownloadPoster(ctx, posterRef)
+	if err != nil {
+		return nil, err
+	}
+	return &imageBites, nil
+}
@@ -1,8 +1,12 @@
 package filmkritiken_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
 	"testing"
 	"time"
 
@@ -19,7 +23,10 @@ func TestFilmkritikenServiceImpl_CreateFilm(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	film := &filmkritiken.Film{
@@ -28,16 +35,30 @@ func TestFilmkritikenServiceImpl_CreateFilm(t *testing.T) {
 		},
 	}
 	details := &filmkritiken.FilmkritikenDetails{}
-	image := []byte("img")
+	image := testPng(t, 300, 450)
 
-	expectedImageId := "image_1"
+	expectedImageId := filmkritiken.ImageContentId(image)
 	expectedFilmkritiken := &filmkritiken.Filmkritiken{
 		Details:     details,
 		Film:        film,
 		Bewertungen: make([]*filmkritiken.Bewertung, 0),
 	}
 
-	imageRepository.EXPECT().SaveImage(ctx, &image).Return(expectedImageId, nil)
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(1), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(false, nil)
+	imageRepository.EXPECT().SaveImage(ctx, expectedImageId, gomock.Any()).
+		DoAndReturn(func(c context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+			if imageFile.ContentType != "image/png" || imageFile.Size != int64(len(image)) {
+				t.Errorf("unexpected image file %+v", imageFile)
+			}
+			return nil
+		})
+	imageRepository.EXPECT().SaveImageVariants(ctx, expectedImageId, gomock.Len(1)).Return(nil)
+	filmRepository.EXPECT().SaveFilm(ctx, film).
+		DoAndReturn(func(c context.Context, f *filmkritiken.Film) error {
+			f.Id = "film_1"
+			return nil
+		})
 	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Eq(expectedFilmkritiken)).
 		DoAndReturn(func(c context.Context, f *filmkritiken.Filmkritiken) error {
 			if f.Film.Image.Id != expectedImageId {
@@ -47,7 +68,7 @@ func TestFilmkritikenServiceImpl_CreateFilm(t *testing.T) {
 			return nil
 		})
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	response, err := service.CreateFilm(ctx, film, details, &image)
@@ -60,6 +81,47 @@ func TestFilmkritikenServiceImpl_CreateFilm(t *testing.T) {
 	if !gomock.Eq(expectedFilmkritiken).Matches(response) {
 		t.Errorf("expected filmkritiken to be %+v but was %+v", expectedFilmkritiken, response)
 	}
+	if response.Film.Image.Width != 300 || response.Film.Image.Height != 450 || response.Film.Image.Blurhash == "" {
+		t.Errorf("expected image dimensions and blurhash to be set, got %+v", response.Film.Image)
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_ImageStoredConcurrently(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	film := &filmkritiken.Film{Image: &filmkritiken.Image{}}
+	details := &filmkritiken.FilmkritikenDetails{}
+	image := testPng(t, 300, 450)
+
+	expectedImageId := filmkritiken.ImageContentId(image)
+
+	// an identical upload stores the image at the same time, its variants are stored by that upload as well
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(2), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(false, nil)
+	imageRepository.EXPECT().SaveImage(ctx, expectedImageId, gomock.Any()).Return(filmkritiken.ErrImageAlreadyStored)
+	filmRepository.EXPECT().SaveFilm(ctx, film).Return(nil)
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	response, err := service.CreateFilm(ctx, film, details, &image)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Film.Image.Id != expectedImageId {
+		t.Errorf("expected imageId to be %s but was %s", expectedImageId, response.Film.Image.Id)
+	}
 }
 
 func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveImage(t *testing.T) {
@@ -67,7 +129,10 @@ func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveImage(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	film := &filmkritiken.Film{
@@ -76,11 +141,17 @@ func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveImage(t *testing.T) {
 		},
 	}
 	details := &filmkritiken.FilmkritikenDetails{}
-	image := []byte("img")
+	image := testPng(t, 300, 450)
+
+	expectedImageId := filmkritiken.ImageContentId(image)
 
-	imageRepository.EXPECT().SaveImage(ctx, &image).Return("", errors.New(""))
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(1), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(false, nil)
+	imageRepository.EXPECT().SaveImage(ctx, expectedImageId, gomock.Any()).Return(errors.New(""))
+	imageReferenceRepository.EXPECT().RemoveImageReference(ctx, expectedImageId).Return(int64(0), nil)
+	imageRepository.EXPECT().DeleteImage(ctx, expectedImageId).Return(nil)
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	_, err := service.CreateFilm(ctx, film, details, &image)
@@ -101,7 +172,10 @@ func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveFilmkritiken(t *testing.T)
 	ctrl := gomock.NewController(t)
 
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	film := &filmkritiken.Film{
@@ -110,20 +184,30 @@ func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveFilmkritiken(t *testing.T)
 		},
 	}
 	details := &filmkritiken.FilmkritikenDetails{}
-	image := []byte("img")
+	image := testPng(t, 300, 450)
 
-	expectedImageId := "image_1"
+	expectedImageId := filmkritiken.ImageContentId(image)
 	expectedFilmkritiken := &filmkritiken.Filmkritiken{
 		Details:     details,
 		Film:        film,
 		Bewertungen: make([]*filmkritiken.Bewertung, 0),
 	}
 
-	imageRepository.EXPECT().SaveImage(ctx, &image).Return(expectedImageId, nil)
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(1), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(false, nil)
+	imageRepository.EXPECT().SaveImage(ctx, expectedImageId, gomock.Any()).Return(nil)
+	imageRepository.EXPECT().SaveImageVariants(ctx, expectedImageId, gomock.Any()).Return(nil)
+	filmRepository.EXPECT().SaveFilm(ctx, film).
+		DoAndReturn(func(c context.Context, f *filmkritiken.Film) error {
+			f.Id = "film_1"
+			return nil
+		})
 	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Eq(expectedFilmkritiken)).Return(errors.New(""))
+	filmRepository.EXPECT().DeleteFilm(ctx, "film_1").Return(nil)
+	imageReferenceRepository.EXPECT().RemoveImageReference(ctx, expectedImageId).Return(int64(0), nil)
 	imageRepository.EXPECT().DeleteImage(ctx, expectedImageId).Return(nil)
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	_, err := service.CreateFilm(ctx, film, details, &image)
@@ -139,12 +223,280 @@ func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveFilmkritiken(t *testing.T)
 	}
 }
 
+func TestFilmkritikenServiceImpl_CreateFilm_DuplicateImage(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	film := &filmkritiken.Film{
+		Image: &filmkritiken.Image{
+			Copyright: "IMDb",
+		},
+	}
+	details := &filmkritiken.FilmkritikenDetails{}
+	image := testPng(t, 300, 450)
+	expectedImageId := filmkritiken.ImageContentId(image)
+
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(2), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(true, nil)
+	filmRepository.EXPECT().SaveFilm(ctx, film).
+		DoAndReturn(func(c context.Context, f *filmkritiken.Film) error {
+			f.Id = "film_1"
+			return nil
+		})
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	response, err := service.CreateFilm(ctx, film, details, &image)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Film.Image.Id != expectedImageId {
+		t.Errorf("expected imageId to be %s but was %s", expectedImageId, response.Film.Image.Id)
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_ErrorSaveFilmkritikenKeepsSharedImage(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	film := &filmkritiken.Film{
+		Image: &filmkritiken.Image{
+			Copyright: "IMDb",
+		},
+	}
+	details := &filmkritiken.FilmkritikenDetails{}
+	image := testPng(t, 300, 450)
+	expectedImageId := filmkritiken.ImageContentId(image)
+
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, expectedImageId).Return(int64(2), nil)
+	imageRepository.EXPECT().ImageExists(ctx, expectedImageId).Return(true, nil)
+	filmRepository.EXPECT().SaveFilm(ctx, film).
+		DoAndReturn(func(c context.Context, f *filmkritiken.Film) error {
+			f.Id = "film_1"
+			return nil
+		})
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(errors.New(""))
+	filmRepository.EXPECT().DeleteFilm(ctx, "film_1").Return(nil)
+	imageReferenceRepository.EXPECT().RemoveImageReference(ctx, expectedImageId).Return(int64(1), nil)
+	// no DeleteImage: the image is still referenced by other Filmkritiken
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, err := service.CreateFilm(ctx, film, details, &image)
+
+	// then
+	var re *domainErrors.RepositoryError
+	if !errors.As(err, &re) {
+		t.Errorf("Expected RepositoryError but got %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_InvalidImage(t *testing.T) {
+	tests := map[string][]byte{
+		"no image":      {},
+		"not an image":  []byte("%PDF-1.4 not a poster"),
+		"corrupt image": append([]byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a}, []byte("garbage")...),
+		"too large":     append([]byte{0xff, 0xd8, 0xff}, make([]byte, filmkritiken.MaxImageSize)...),
+	}
+
+	for name, imageBites := range tests {
+		t.Run(name, func(t *testing.T) {
+			// given
+			ctrl := gomock.NewController(t)
+			filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+			filmRepository := mocks.NewMockFilmRepository(ctrl)
+			reiheRepository := mocks.NewMockReiheRepository(ctrl)
+			imageRepository := mocks.NewMockImageRepository(ctrl)
+			imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+			film := &filmkritiken.Film{Image: &filmkritiken.Image{}}
+			service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+			// when
+			_, err := service.CreateFilm(context.Background(), film, &filmkritiken.FilmkritikenDetails{}, &imageBites)
+
+			// then
+			var iie *domainErrors.InvalidInputError
+			if !errors.As(err, &iie) {
+				t.Errorf("Expected InvalidInputError but got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_Vorfuehrung(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	storedFilm := &filmkritiken.Film{Id: "film_1", Titel: "Alien", Image: &filmkritiken.Image{Id: "image_1"}}
+	details := &filmkritiken.FilmkritikenDetails{BeitragVon: "Alice"}
+
+	// no image is stored, the screening shows the stored Film with its poster and references it
+	filmRepository.EXPECT().FindFilm(ctx, "film_1").Return(storedFilm, nil)
+	imageReferenceRepository.EXPECT().AddImageReference(ctx, "image_1").Return(int64(3), nil)
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	response, err := service.CreateFilm(ctx, &filmkritiken.Film{Id: "film_1", Titel: "ignored"}, details, nil)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Film != storedFilm || response.Details != details {
+		t.Errorf("expected a screening of the stored film, got %+v", response)
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_VorfuehrungUntrackedImage(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	storedFilm := &filmkritiken.Film{Id: "film_1", Titel: "Alien", Image: &filmkritiken.Image{Id: "image_1"}}
+
+	// the poster was stored before reference counting, the first screening holds its reference implicitly
+	filmRepository.EXPECT().FindFilm(ctx, "film_1").Return(storedFilm, nil)
+	gomock.InOrder(
+		imageReferenceRepository.EXPECT().AddImageReference(ctx, "image_1").Return(int64(1), nil),
+		imageReferenceRepository.EXPECT().AddImageReference(ctx, "image_1").Return(int64(2), nil),
+	)
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, err := service.CreateFilm(ctx, &filmkritiken.Film{Id: "film_1"}, &filmkritiken.FilmkritikenDetails{}, nil)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_CreateFilm_VorfuehrungUnknownFilm(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	filmRepository.EXPECT().FindFilm(ctx, "film_unknown").Return(nil, domainErrors.NewNotFoundErrorFromString("Film konnte nicht gefunden werden."))
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, err := service.CreateFilm(ctx, &filmkritiken.Film{Id: "film_unknown"}, &filmkritiken.FilmkritikenDetails{}, nil)
+
+	// then
+	var iie *domainErrors.InvalidInputError
+	if !errors.As(err, &iie) {
+		t.Errorf("expected InvalidInputError but got %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_GetFilm(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	erste := time.Date(2015, 3, 1, 20, 0, 0, 0, time.UTC)
+	zweite := time.Date(2024, 3, 1, 20, 0, 0, 0, time.UTC)
+	film := &filmkritiken.Film{Id: "film_1", Titel: "Alien"}
+
+	filmRepository.EXPECT().FindFilm(ctx, "film_1").Return(film, nil)
+	filmkritikenRepository.EXPECT().GetFilmkritikenByFilm(ctx, "film_1").Return([]*filmkritiken.Filmkritiken{
+		{
+			Id:      "fk_2",
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &zweite},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{Von: "Alice", Wertung: 9},
+				{Von: "Bob", Enthaltung: true},
+			},
+		},
+		{
+			Id:      "fk_1",
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &erste},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{Von: "Alice", Wertung: 6},
+				{Von: "Bob", Wertung: 8},
+			},
+		},
+	}, nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	uebersicht, err := service.GetFilm(ctx, "film_1")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uebersicht.Vorfuehrungen) != 2 || uebersicht.Vorfuehrungen[0].FilmkritikenId != "fk_1" || uebersicht.Vorfuehrungen[0].Durchschnitt != 7 {
+		t.Errorf("unexpected vorfuehrungen %+v", uebersicht.Vorfuehrungen)
+	}
+	if len(uebersicht.Mitglieder) != 2 || uebersicht.Mitglieder[0].Name != "Alice" || uebersicht.Mitglieder[0].Veraenderung != 3 {
+		t.Fatalf("unexpected mitglieder %+v", uebersicht.Mitglieder)
+	}
+	if bob := uebersicht.Mitglieder[1]; len(bob.Wertungen) != 2 || bob.Veraenderung != 0 {
+		t.Errorf("unexpected verlauf for Bob %+v", bob)
+	}
+}
+
 func TestFilmkritikenServiceImpl_UpdateBesprochenAm(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	filmkritikenId := "fk_1"
@@ -152,7 +504,7 @@ func TestFilmkritikenServiceImpl_UpdateBesprochenAm(t *testing.T) {
 
 	filmkritikenRepository.EXPECT().UpdateBesprochenAm(ctx, filmkritikenId, besprochenAm).Return(nil)
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	err := service.UpdateBesprochenAm(ctx, filmkritikenId, besprochenAm)
@@ -168,7 +520,10 @@ func TestFilmkritikenServiceImpl_UpdateBesprochenAm_NotFound(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	filmkritikenId := "fk_doesnotexist"
@@ -178,7 +533,7 @@ func TestFilmkritikenServiceImpl_UpdateBesprochenAm_NotFound(t *testing.T) {
 		UpdateBesprochenAm(ctx, filmkritikenId, besprochenAm).
 		Return(domainErrors.NewNotFoundErrorFromString("Filmkritiken konnten nicht gefunden werden."))
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	err := service.UpdateBesprochenAm(ctx, filmkritikenId, besprochenAm)
@@ -198,7 +553,10 @@ func TestFilmkritikenServiceImpl_SetKritik_Success(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	fkID := "fk_1"
@@ -215,16 +573,16 @@ func TestFilmkritikenServiceImpl_SetKritik_Success(t *testing.T) {
 		if len(fk.Bewertungen) != 1 {
 			t.Errorf("expected 1 bewertung, got %d", len(fk.Bewertungen))
 		}
-		if fk.Bewertungen[0].Wertung != 8 || fk.Bewertungen[0].Enthaltung != false {
+		if fk.Bewertungen[0].VonId != "oid-stefan" || fk.Bewertungen[0].Wertung != 8 || fk.Bewertungen[0].Enthaltung != false {
 			t.Errorf("unexpected bewertung values: %+v", fk.Bewertungen[0])
 		}
 		return nil
 	})
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
-	err := service.SetKritik(ctx, fkID, user, 8, false)
+	err := service.SetKritik(ctx, fkID, "oid-stefan", user, 8, false)
 
 	// then
 	if err != nil {
@@ -236,7 +594,10 @@ func TestFilmkritikenServiceImpl_SetKritik_Enthaltung(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	fkID := "fk_1"
@@ -259,10 +620,10 @@ func TestFilmkritikenServiceImpl_SetKritik_Enthaltung(t *testing.T) {
 		return nil
 	})
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
-	err := service.SetKritik(ctx, fkID, user, 0, true)
+	err := service.SetKritik(ctx, fkID, "oid-stefan", user, 0, true)
 
 	// then
 	if err != nil {
@@ -270,17 +631,60 @@ func TestFilmkritikenServiceImpl_SetKritik_Enthaltung(t *testing.T) {
 	}
 }
 
+func TestFilmkritikenServiceImpl_SetKritik_LegacyBewertungGetsUserId(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	existingFK := &filmkritiken.Filmkritiken{
+		Id:      "fk_1",
+		Film:    &filmkritiken.Film{Titel: "Test Film"},
+		Details: &filmkritiken.FilmkritikenDetails{BewertungOffen: true},
+		Bewertungen: []*filmkritiken.Bewertung{
+			{Von: "stefan", Wertung: 5},
+			{VonId: "oid-other", Von: "Stefan", Wertung: 3},
+		},
+	}
+
+	filmkritikenRepository.EXPECT().FindFilmkritiken(ctx, "fk_1").Return(existingFK, nil)
+	filmkritikenRepository.EXPECT().SaveFilmkritiken(ctx, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	err := service.SetKritik(ctx, "fk_1", "oid-stefan", "Stefan", 8, false)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(existingFK.Bewertungen) != 2 || existingFK.Bewertungen[0].VonId != "oid-stefan" || existingFK.Bewertungen[0].Wertung != 8 {
+		t.Errorf("expected the name based bewertung to be updated, got %+v", existingFK.Bewertungen[0])
+	}
+	if existingFK.Bewertungen[1].Wertung != 3 {
+		t.Errorf("expected the bewertung of the other user to be unchanged, got %+v", existingFK.Bewertungen[1])
+	}
+}
+
 func TestFilmkritikenServiceImpl_SetKritik_InvalidWertung(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
-	err := service.SetKritik(ctx, "fk_1", "Stefan", 15, false)
+	err := service.SetKritik(ctx, "fk_1", "oid-stefan", "Stefan", 15, false)
 
 	// then
 	if err == nil {
@@ -292,7 +696,10 @@ func TestFilmkritikenServiceImpl_GetFilmkritiken(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	filter := &filmkritiken.FilmkritikenFilter{
@@ -307,7 +714,7 @@ func TestFilmkritikenServiceImpl_GetFilmkritiken(t *testing.T) {
 
 	filmkritikenRepository.EXPECT().GetFilmkritiken(ctx, filter).Return(expectedResult, int64(1), nil)
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// when
 	result, totalCount, err := service.GetFilmkritiken(ctx, filter)
@@ -328,7 +735,10 @@ func TestFilmkritikenServiceImpl_GetFilterOptions_Caching(t *testing.T) {
 	// given
 	ctrl := gomock.NewController(t)
 	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
 	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
 
 	ctx := context.Background()
 	expectedOpts := &filmkritiken.FilterOptions{
@@ -339,7 +749,7 @@ func TestFilmkritikenServiceImpl_GetFilterOptions_Caching(t *testing.T) {
 	// Expect GetFilterOptions to be called ONLY ONCE on repo due to caching
 	filmkritikenRepository.EXPECT().GetFilterOptions(ctx).Return(expectedOpts, nil).Times(1)
 
-	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, imageRepository)
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
 
 	// First call -> fetches from repo
 	opts1, err1 := service.GetFilterOptions(ctx)
@@ -357,3 +767,981 @@ func TestFilmkritikenServiceImpl_GetFilterOptions_Caching(t *testing.T) {
 		t.Errorf("cached options mismatch: %+v vs %+v", opts1, opts2)
 	}
 }
+
+func TestFilmkritikenServiceImpl_UpdateKategorien(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	filmkritikenId := "fk_1"
+
+	// the cached filter options have to be reloaded after the update
+	filmkritikenRepository.EXPECT().GetFilterOptions(ctx).Return(&filmkritiken.FilterOptions{}, nil).Times(2)
+	filmkritikenRepository.EXPECT().
+		UpdateKategorien(ctx, filmkritikenId, []string{"Action", "Science Fiction"}, []string{"Zeitreise"}).
+		Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+	_, _ = service.GetFilterOptions(ctx)
+
+	// when
+	err := service.UpdateKategorien(ctx, filmkritikenId, []string{" Action ", "Science  Fiction", "action", ""}, []string{"Zeitreise"})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = service.GetFilterOptions(ctx)
+}
+
+func TestNormalizeKategorien_Invalid(t *testing.T) {
+	tooMany := make([]string, 21)
+	for i := range tooMany {
+		tooMany[i] = string(rune('a' + i))
+	}
+
+	for _, kategorien := range [][]string{tooMany, {"Ein Genre mit einem viel zu langen Namen, der nicht passt"}} {
+		_, err := filmkritiken.NormalizeKategorien(kategorien)
+		var iie *domainErrors.InvalidInputError
+		if !errors.As(err, &iie) {
+			t.Errorf("expected InvalidInputError for %v, got %v", kategorien, err)
+		}
+	}
+}
+
+func TestCalculateMitgliederStatistiken(t *testing.T) {
+	// given
+	allFilmkritiken := []*filmkritiken.Filmkritiken{
+		{
+			Film: &filmkritiken.Film{Genres: []string{"Horror", "Science Fiction"}},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{Von: "Alice", Wertung: 8},
+				{Von: "Bob", Enthaltung: true},
+			},
+		},
+		{
+			Film: &filmkritiken.Film{Genres: []string{"Horror"}},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{Von: "Alice", Wertung: 4},
+				{Von: "Bob", Wertung: 7},
+			},
+		},
+	}
+
+	// when
+	statistiken := filmkritiken.CalculateMitgliederStatistiken(allFilmkritiken)
+
+	// then
+	if len(statistiken) != 2 || statistiken[0].Name != "Alice" || statistiken[1].Name != "Bob" {
+		t.Fatalf("unexpected statistiken %+v", statistiken)
+	}
+	alice := statistiken[0]
+	if alice.AnzahlBewertungen != 2 || alice.Durchschnitt != 6 {
+		t.Errorf("unexpected statistik for Alice %+v", alice)
+	}
+	if len(alice.Genres) != 2 || alice.Genres[0].Genre != "Horror" || alice.Genres[0].Durchschnitt != 6 || alice.Genres[1].Durchschnitt != 8 {
+		t.Errorf("unexpected genres for Alice %+v", alice.Genres)
+	}
+	bob := statistiken[1]
+	if bob.AnzahlBewertungen != 1 || bob.AnzahlEnthaltungen != 1 || bob.Durchschnitt != 7 {
+		t.Errorf("unexpected statistik for Bob %+v", bob)
+	}
+}
+
+func TestCalculateMitgliederStatistiken_BenutzerIds(t *testing.T) {
+	// given
+	frueher := time.Date(2020, 1, 10, 20, 0, 0, 0, time.UTC)
+	spaeter := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+	allFilmkritiken := []*filmkritiken.Filmkritiken{
+		{
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &spaeter},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{VonId: "user_1", Von: "Alice", Wertung: 8},
+				{VonId: "user_2", Von: "Alice", Wertung: 2},
+			},
+		},
+		{
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &frueher},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{VonId: "user_1", Von: "Ali", Wertung: 4},
+				{Von: "Alice", Wertung: 5},
+			},
+		},
+	}
+
+	// when
+	statistiken := filmkritiken.CalculateMitgliederStatistiken(allFilmkritiken)
+
+	// then
+	if len(statistiken) != 3 {
+		t.Fatalf("expected 3 members, got %+v", statistiken)
+	}
+	// the renamed user is named after the latest Bewertung, members of the same name are kept apart
+	if statistiken[0].VonId != "" || statistiken[0].AnzahlBewertungen != 1 || statistiken[0].Durchschnitt != 5 {
+		t.Errorf("unexpected statistik for Alice without login %+v", statistiken[0])
+	}
+	if statistiken[1].VonId != "user_1" || statistiken[1].Name != "Alice" || statistiken[1].AnzahlBewertungen != 2 || statistiken[1].Durchschnitt != 6 {
+		t.Errorf("unexpected statistik for user_1 %+v", statistiken[1])
+	}
+	if statistiken[2].VonId != "user_2" || statistiken[2].AnzahlBewertungen != 1 || statistiken[2].Durchschnitt != 2 {
+		t.Errorf("unexpected statistik for user_2 %+v", statistiken[2])
+	}
+}
+
+func TestCompareVorfuehrungen_BenutzerIds(t *testing.T) {
+	// given
+	frueher := time.Date(2020, 1, 10, 20, 0, 0, 0, time.UTC)
+	spaeter := time.Date(2024, 3, 5, 20, 0, 0, 0, time.UTC)
+	screenings := []*filmkritiken.Filmkritiken{
+		{
+			Id:      "fk_2",
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &spaeter},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{VonId: "user_1", Von: "Alice", Wertung: 9},
+			},
+		},
+		{
+			Id:      "fk_1",
+			Details: &filmkritiken.FilmkritikenDetails{BesprochenAm: &frueher},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{VonId: "user_1", Von: "Ali", Wertung: 4},
+				{Von: "Alice", Wertung: 7},
+			},
+		},
+	}
+
+	// when
+	uebersicht := filmkritiken.CompareVorfuehrungen(&filmkritiken.Film{Id: "film_1"}, screenings)
+
+	// then
+	if len(uebersicht.Mitglieder) != 2 {
+		t.Fatalf("expected 2 members, got %+v", uebersicht.Mitglieder)
+	}
+	ohneLogin, alice := uebersicht.Mitglieder[0], uebersicht.Mitglieder[1]
+	if ohneLogin.VonId != "" || len(ohneLogin.Wertungen) != 1 {
+		t.Errorf("unexpected verlauf for Alice without login %+v", ohneLogin)
+	}
+	if alice.VonId != "user_1" || alice.Name != "Alice" || len(alice.Wertungen) != 2 || alice.Veraenderung != 5 {
+		t.Errorf("unexpected verlauf for user_1 %+v", alice)
+	}
+}
+
+func TestFilmkritikenServiceImpl_GetPerson(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	filmkritikenRepository.EXPECT().GetFilmkritikenByPerson(ctx, "ridley scott").Return([]*filmkritiken.Filmkritiken{
+		{
+			Id:   "fk_1",
+			Film: &filmkritiken.Film{Titel: "Alien", Regie: "Ridley Scott"},
+			Bewertungen: []*filmkritiken.Bewertung{
+				{Von: "Alice", Wertung: 9},
+				{Von: "Bob", Wertung: 6},
+				{Von: "Carol", Enthaltung: true},
+			},
+		},
+		{
+			Id: "fk_2",
+			Film: &filmkritiken.Film{
+				Titel:     "Cameo",
+				Regie:     "Someone Else",
+				Besetzung: []*filmkritiken.Darsteller{{Name: "Ridley Scott", Rolle: "Er selbst"}},
+			},
+		},
+	}, nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	person, err := service.GetPerson(ctx, " ridley  scott ")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if person.Name != "Ridley Scott" || len(person.Filme) != 2 {
+		t.Fatalf("unexpected person %+v", person)
+	}
+	if !person.Filme[0].Regie || person.Filme[0].Darsteller || person.Filme[0].AnzahlBewertungen != 2 || person.Filme[0].Durchschnitt != 7.5 {
+		t.Errorf("unexpected first film %+v", person.Filme[0])
+	}
+	if person.Filme[1].Regie || !person.Filme[1].Darsteller || person.Filme[1].Rolle != "Er selbst" {
+		t.Errorf("unexpected second film %+v", person.Filme[1])
+	}
+}
+
+func TestFilmkritikenServiceImpl_GetPerson_NotFound(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	filmkritikenRepository.EXPECT().GetFilmkritikenByPerson(ctx, "Scott").Return([]*filmkritiken.Filmkritiken{}, nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, err := service.GetPerson(ctx, "Scott")
+
+	// then
+	var nfe *domainErrors.NotFoundError
+	if !errors.As(err, &nfe) {
+		t.Errorf("expected NotFoundError but got %v", err)
+	}
+}
+
+func TestNormalizeBesetzung(t *testing.T) {
+	// when
+	besetzung, err := filmkritiken.NormalizeBesetzung([]*filmkritiken.Darsteller{
+		{Name: " Sigourney  Weaver ", Rolle: "Ripley", Reihenfolge: 1},
+		{Name: "", Rolle: "Jones"},
+		{Name: "Tom Skerritt", Rolle: "Dallas", Reihenfolge: 0},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(besetzung) != 2 || besetzung[0].Name != "Tom Skerritt" || besetzung[1].Name != "Sigourney Weaver" {
+		t.Errorf("unexpected besetzung %+v", besetzung)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetReiheEintrag(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	reihe := &filmkritiken.Reihe{
+		Id:   "reihe_1",
+		Name: "Alien",
+		Eintraege: []*filmkritiken.ReiheEintrag{
+			{FilmkritikenId: "fk_1", Position: 1},
+			{FilmkritikenId: "fk_3", Position: 3},
+		},
+	}
+
+	reiheRepository.EXPECT().FindReihe(ctx, "reihe_1").Return(reihe, nil)
+	filmkritikenRepository.EXPECT().FindFilmkritiken(ctx, "fk_2").Return(&filmkritiken.Filmkritiken{Id: "fk_2"}, nil)
+	reiheRepository.EXPECT().SaveReihe(ctx, reihe).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	err := service.SetReiheEintrag(ctx, "reihe_1", "fk_2", 2)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reihe.Eintraege) != 3 || reihe.Eintraege[1].FilmkritikenId != "fk_2" {
+		t.Errorf("unexpected eintraege %+v", reihe.Eintraege)
+	}
+}
+
+func TestFilmkritikenServiceImpl_GetReihe(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	reihe := &filmkritiken.Reihe{
+		Id:   "reihe_1",
+		Name: "Alien",
+		Eintraege: []*filmkritiken.ReiheEintrag{
+			{FilmkritikenId: "fk_1", Position: 1},
+			{FilmkritikenId: "fk_2", Position: 2},
+			{FilmkritikenId: "fk_3", Position: 3},
+		},
+	}
+
+	reiheRepository.EXPECT().FindReihe(ctx, "reihe_1").Return(reihe, nil)
+	filmkritikenRepository.EXPECT().GetFilmkritiken(ctx, &filmkritiken.FilmkritikenFilter{Reihe: "reihe_1"}).Return([]*filmkritiken.Filmkritiken{
+		{Id: "fk_3", Film: &filmkritiken.Film{Titel: "Alien vs. Predator 2"}, Bewertungen: []*filmkritiken.Bewertung{{Von: "Alice", Wertung: 2}}},
+		{Id: "fk_1", Film: &filmkritiken.Film{Titel: "Alien"}, Bewertungen: []*filmkritiken.Bewertung{{Von: "Alice", Wertung: 9}, {Von: "Bob", Wertung: 7}}},
+		{Id: "fk_2", Film: &filmkritiken.Film{Titel: "Aliens"}, Bewertungen: []*filmkritiken.Bewertung{{Von: "Bob", Enthaltung: true}}},
+	}, int64(3), nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	uebersicht, err := service.GetReihe(ctx, "reihe_1")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(uebersicht.Filme) != 3 || uebersicht.Filme[0].Titel != "Alien" || uebersicht.Filme[2].Titel != "Alien vs. Predator 2" {
+		t.Fatalf("unexpected filme %+v", uebersicht.Filme)
+	}
+	if uebersicht.AnzahlBewertungen != 3 || uebersicht.Durchschnitt != 6 {
+		t.Errorf("unexpected statistik %d / %f", uebersicht.AnzahlBewertungen, uebersicht.Durchschnitt)
+	}
+	if uebersicht.Beste.FilmkritikenId != "fk_1" || uebersicht.Schlechteste.FilmkritikenId != "fk_3" {
+		t.Errorf("unexpected beste / schlechteste %+v / %+v", uebersicht.Beste, uebersicht.Schlechteste)
+	}
+}
+
+func TestFilmkritikenServiceImpl_MergeMitglied(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	benutzerRepository := mocks.NewMockBenutzerRepository(ctrl)
+
+	ctx := context.WithValue(context.Background(), filmkritiken.Context_Username, "Admin")
+	fkNur := &filmkritiken.Filmkritiken{
+		Id:          "fk_1",
+		Film:        &filmkritiken.Film{Titel: "Alien"},
+		Details:     &filmkritiken.FilmkritikenDetails{BeitragVon: "nico"},
+		Bewertungen: []*filmkritiken.Bewertung{{Von: "Nico", Wertung: 7}},
+	}
+	fkBeide := &filmkritiken.Filmkritiken{
+		Id:      "fk_2",
+		Film:    &filmkritiken.Film{Titel: "Aliens"},
+		Details: &filmkritiken.FilmkritikenDetails{BeitragVon: "Stefan"},
+		Bewertungen: []*filmkritiken.Bewertung{
+			{Von: "Nico", Wertung: 9},
+			{VonId: "oid-nico", Von: "Nico B.", Wertung: 4},
+		},
+	}
+
+	filmkritikenRepository.EXPECT().GetFilmkritikenByMitglied(ctx, []string{"Nico", "Nico B."}).Return([]*filmkritiken.Filmkritiken{fkNur, fkBeide}, nil)
+	var auditEintrag *filmkritiken.AuditEintrag
+	filmkritikenRepository.EXPECT().SaveFilmkritikenWithAudit(ctx, []*filmkritiken.Filmkritiken{fkNur, fkBeide}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ []*filmkritiken.Filmkritiken, eintrag *filmkritiken.AuditEintrag) error {
+			auditEintrag = eintrag
+			return nil
+		})
+	benutzerRepository.EXPECT().MergeBenutzer(ctx, "oid-nico", "", []string{"Nico", "Nico B."}).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, benutzerRepository, nil)
+
+	// when
+	ergebnis, err := service.MergeMitglied(ctx, &filmkritiken.MitgliedZusammenfuehrung{Von: " Nico ", Nach: "Nico B.", Regel: filmkritiken.KonfliktRegel_Quelle}, false)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ergebnis.Ausgefuehrt || ergebnis.AnzahlBewertungen != 2 || ergebnis.AnzahlBeitraege != 1 || len(ergebnis.Konflikte) != 1 {
+		t.Errorf("unexpected ergebnis: %+v", ergebnis)
+	}
+	if fkNur.Details.BeitragVon != "Nico B." || fkNur.Bewertungen[0].Von != "Nico B." || fkNur.Bewertungen[0].VonId != "oid-nico" {
+		t.Errorf("expected fk_1 to be renamed, got %+v %+v", fkNur.Details, fkNur.Bewertungen[0])
+	}
+	if len(fkBeide.Bewertungen) != 1 || fkBeide.Bewertungen[0].Von != "Nico B." || fkBeide.Bewertungen[0].VonId != "oid-nico" || fkBeide.Bewertungen[0].Wertung != 9 {
+		t.Errorf("expected one bewertung with the wertung of the source, got %+v", fkBeide.Bewertungen)
+	}
+	if auditEintrag == nil || auditEintrag.Benutzer != "Admin" || auditEintrag.Aktion != filmkritiken.Aktion_MitgliedZusammenfuehren {
+		t.Errorf("unexpected audit entry: %+v", auditEintrag)
+	}
+}
+
+func TestFilmkritikenServiceImpl_MergeMitglied_BenutzerIds(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	benutzerRepository := mocks.NewMockBenutzerRepository(ctrl)
+
+	ctx := context.Background()
+	fkVon := &filmkritiken.Filmkritiken{
+		Id:          "fk_1",
+		Details:     &filmkritiken.FilmkritikenDetails{},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "oid-nico", Von: "Nico", Wertung: 7}},
+	}
+	fkNachOhneId := &filmkritiken.Filmkritiken{
+		Id:          "fk_2",
+		Details:     &filmkritiken.FilmkritikenDetails{},
+		Bewertungen: []*filmkritiken.Bewertung{{Von: "Nico B.", Wertung: 4}},
+	}
+	fkAndere := &filmkritiken.Filmkritiken{
+		Id:          "fk_3",
+		Details:     &filmkritiken.FilmkritikenDetails{},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "oid-stefan", Von: "Stefan", Wertung: 5}},
+	}
+
+	filmkritikenRepository.EXPECT().GetFilmkritikenByMitglied(ctx, gomock.Any()).Return([]*filmkritiken.Filmkritiken{fkVon, fkNachOhneId, fkAndere}, nil)
+	filmkritikenRepository.EXPECT().SaveFilmkritikenWithAudit(ctx, []*filmkritiken.Filmkritiken{fkVon, fkNachOhneId}, gomock.Any()).Return(nil)
+	benutzerRepository.EXPECT().MergeBenutzer(ctx, "oid-nico", "", []string{"Nico", "Nico B."}).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, benutzerRepository, nil)
+
+	// when
+	ergebnis, err := service.MergeMitglied(ctx, &filmkritiken.MitgliedZusammenfuehrung{Von: "Nico", Nach: "Nico B."}, false)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ergebnis.BenutzerId != "oid-nico" || ergebnis.VonBenutzerId != "" {
+		t.Errorf("expected the user of the source to be kept, got %+v", ergebnis)
+	}
+	if b := fkVon.Bewertungen[0]; b.Von != "Nico B." || b.VonId != "oid-nico" {
+		t.Errorf("unexpected bewertung of the source: %+v", b)
+	}
+	// otherwise the next rename of the user would only rename its own Bewertungen
+	if b := fkNachOhneId.Bewertungen[0]; b.VonId != "oid-nico" {
+		t.Errorf("expected the bewertung of the target without id to get the user, got %+v", b)
+	}
+	if b := fkAndere.Bewertungen[0]; b.VonId != "oid-stefan" {
+		t.Errorf("expected other bewertungen to be unchanged, got %+v", b)
+	}
+}
+
+func TestMergeMitglied_VerschiedeneBenutzer(t *testing.T) {
+	// given
+	fk := &filmkritiken.Filmkritiken{
+		Id:          "fk_1",
+		Details:     &filmkritiken.FilmkritikenDetails{},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "entra:nico", Von: "Nico", Wertung: 7}},
+	}
+	fkNach := &filmkritiken.Filmkritiken{
+		Id:          "fk_2",
+		Details:     &filmkritiken.FilmkritikenDetails{},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "keycloak:nico", Von: "Nico B.", Wertung: 4}},
+	}
+
+	// when
+	ergebnis, changed := filmkritiken.MergeMitglied([]*filmkritiken.Filmkritiken{fk, fkNach}, &filmkritiken.MitgliedZusammenfuehrung{Von: "Nico", Nach: "Nico B.", Regel: filmkritiken.KonfliktRegel_Abbrechen})
+
+	// then
+	if ergebnis.BenutzerId != "keycloak:nico" || ergebnis.VonBenutzerId != "entra:nico" {
+		t.Errorf("unexpected users %q / %q", ergebnis.BenutzerId, ergebnis.VonBenutzerId)
+	}
+	if len(changed) != 1 || fk.Bewertungen[0].VonId != "keycloak:nico" {
+		t.Errorf("expected the bewertung of the source to move to the target user, got %+v", fk.Bewertungen[0])
+	}
+}
+
+func TestFilmkritikenServiceImpl_MergeMitglied_KonflikteAbbrechen(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	newFilmkritiken := func() []*filmkritiken.Filmkritiken {
+		return []*filmkritiken.Filmkritiken{{
+			Id:          "fk_1",
+			Film:        &filmkritiken.Film{Titel: "Alien"},
+			Details:     &filmkritiken.FilmkritikenDetails{},
+			Bewertungen: []*filmkritiken.Bewertung{{Von: "Nico", Wertung: 9}, {Von: "Nico B.", Wertung: 4}},
+		}}
+	}
+	filmkritikenRepository.EXPECT().GetFilmkritikenByMitglied(ctx, gomock.Any()).Return(newFilmkritiken(), nil)
+	filmkritikenRepository.EXPECT().GetFilmkritikenByMitglied(ctx, gomock.Any()).Return(newFilmkritiken(), nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	vorschau, vorschauErr := service.MergeMitglied(ctx, &filmkritiken.MitgliedZusammenfuehrung{Von: "Nico", Nach: "Nico B."}, true)
+	_, err := service.MergeMitglied(ctx, &filmkritiken.MitgliedZusammenfuehrung{Von: "Nico", Nach: "Nico B."}, false)
+
+	// then
+	if vorschauErr != nil {
+		t.Fatalf("unexpected error in preview: %v", vorschauErr)
+	}
+	if vorschau.Ausgefuehrt || len(vorschau.Konflikte) != 1 || vorschau.Konflikte[0].BewertungVon.Wertung != 9 {
+		t.Errorf("expected a preview with one conflict, got %+v", vorschau)
+	}
+	if _, ok := err.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError, got %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetKritiken(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	benutzerRepository := mocks.NewMockBenutzerRepository(ctrl)
+
+	ctx := context.WithValue(context.Background(), filmkritiken.Context_Username, "Moderator")
+	existingFK := &filmkritiken.Filmkritiken{
+		Id:          "fk_1",
+		Film:        &filmkritiken.Film{Titel: "Alien"},
+		Details:     &filmkritiken.FilmkritikenDetails{BewertungOffen: true},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "oid-stefan", Von: "Stefan", Wertung: 3}},
+	}
+
+	benutzerRepository.EXPECT().BenutzerExists(ctx, "oid-stefan").Return(true, nil)
+	filmkritikenRepository.EXPECT().FindFilmkritiken(ctx, "fk_1").Return(existingFK, nil)
+	var auditEintrag *filmkritiken.AuditEintrag
+	filmkritikenRepository.EXPECT().SaveFilmkritikenWithAudit(ctx, []*filmkritiken.Filmkritiken{existingFK}, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ []*filmkritiken.Filmkritiken, eintrag *filmkritiken.AuditEintrag) error {
+			auditEintrag = eintrag
+			return nil
+		})
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, benutzerRepository, nil)
+
+	// when
+	err := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{
+		{VonId: "oid-stefan", Von: "Stefan", Wertung: 8},
+		{Von: " Nico ", Enthaltung: true},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(existingFK.Bewertungen) != 2 {
+		t.Fatalf("expected 2 bewertungen, got %d", len(existingFK.Bewertungen))
+	}
+	if b := existingFK.Bewertungen[0]; b.VonId != "oid-stefan" || b.Wertung != 8 || b.ErfasstVon != "Moderator" {
+		t.Errorf("unexpected bewertung of Stefan: %+v", b)
+	}
+	if b := existingFK.Bewertungen[1]; b.Von != "Nico" || !b.Enthaltung || b.ErfasstVon != "Moderator" {
+		t.Errorf("unexpected bewertung of Nico: %+v", b)
+	}
+	if auditEintrag == nil || auditEintrag.Benutzer != "Moderator" || auditEintrag.Aktion != filmkritiken.Aktion_BewertungenErfassen {
+		t.Errorf("unexpected audit entry: %+v", auditEintrag)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetKritiken_InvalidWertungChangesNothing(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	invalidErr := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{
+		{Von: "Stefan", Wertung: 8},
+		{Von: "Nico", Wertung: 11},
+	})
+	duplicateErr := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{
+		{Von: "Stefan", Wertung: 8},
+		{Von: "stefan", Wertung: 5},
+	})
+	duplicateWithIdErr := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{
+		{VonId: "u1", Von: "Anna", Wertung: 8},
+		{Von: "anna", Wertung: 5},
+	})
+
+	// then
+	if _, ok := invalidErr.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError for invalid wertung, got %v", invalidErr)
+	}
+	if _, ok := duplicateErr.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError for duplicate member, got %v", duplicateErr)
+	}
+	if _, ok := duplicateWithIdErr.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError for member given with and without id, got %v", duplicateWithIdErr)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetKritiken_NameMatchesMemberWithLogin(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	existingFK := &filmkritiken.Filmkritiken{
+		Id:          "fk_1",
+		Film:        &filmkritiken.Film{Titel: "Alien"},
+		Details:     &filmkritiken.FilmkritikenDetails{BewertungOffen: true},
+		Bewertungen: []*filmkritiken.Bewertung{{VonId: "oid-stefan", Von: "Stefan", Wertung: 3}},
+	}
+
+	filmkritikenRepository.EXPECT().FindFilmkritiken(ctx, "fk_1").Return(existingFK, nil)
+	filmkritikenRepository.EXPECT().SaveFilmkritikenWithAudit(ctx, []*filmkritiken.Filmkritiken{existingFK}, gomock.Any()).Return(nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	err := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{{Von: "stefan", Wertung: 9}})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(existingFK.Bewertungen) != 1 {
+		t.Fatalf("expected the bewertung of Stefan to be updated, got %d bewertungen", len(existingFK.Bewertungen))
+	}
+	if b := existingFK.Bewertungen[0]; b.VonId != "oid-stefan" || b.Wertung != 9 {
+		t.Errorf("unexpected bewertung of Stefan: %+v", b)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetKritiken_UnknownVonId(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	benutzerRepository := mocks.NewMockBenutzerRepository(ctrl)
+
+	ctx := context.Background()
+	benutzerRepository.EXPECT().BenutzerExists(ctx, "oid-stefan").Return(true, nil)
+	benutzerRepository.EXPECT().BenutzerExists(ctx, "oid-typo").Return(false, nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, benutzerRepository, nil)
+
+	// when
+	err := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{
+		{VonId: "oid-stefan", Von: "Stefan", Wertung: 8},
+		{VonId: "oid-typo", Von: "Nico", Wertung: 5},
+	})
+
+	// then
+	if _, ok := err.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError for unknown user id, got %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SetKritiken_BewertungGeschlossen(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	filmkritikenRepository.EXPECT().FindFilmkritiken(ctx, "fk_1").Return(&filmkritiken.Filmkritiken{
+		Id:      "fk_1",
+		Film:    &filmkritiken.Film{Titel: "Alien"},
+		Details: &filmkritiken.FilmkritikenDetails{BewertungOffen: false},
+	}, nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	err := service.SetKritiken(ctx, "fk_1", []*filmkritiken.BewertungEingabe{{Von: "Stefan", Wertung: 8}})
+
+	// then
+	if _, ok := err.(*domainErrors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError, got %v", err)
+	}
+}
+
+func TestFilmkritikenServiceImpl_SearchFilms_NotConfigured(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, searchErr := service.SearchFilms(ctx, "Alien")
+	_, posterErr := service.DownloadPoster(ctx, "/poster.jpg")
+
+	// then
+	if _, ok := searchErr.(*domainErrors.UnavailableError); !ok {
+		t.Errorf("expected UnavailableError for search, got %v", searchErr)
+	}
+	if _, ok := posterErr.(*domainErrors.UnavailableError); !ok {
+		t.Errorf("expected UnavailableError for poster, got %v", posterErr)
+	}
+}
+
+func TestGenerateImageVariants(t *testing.T) {
+	// given
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, 600, 900)), nil); err != nil {
+		t.Fatalf("could not encode test image: %v", err)
+	}
+	imageBites := buf.Bytes()
+
+	// when
+	variants, err := filmkritiken.GenerateImageVariants(&imageBites)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(variants) != len(filmkritiken.ImageVariantWidths) {
+		t.Fatalf("expected %d variants, got %d", len(filmkritiken.ImageVariantWidths), len(variants))
+	}
+	for i, variant := range variants {
+		config, err := jpeg.DecodeConfig(bytes.NewReader(*variant.Bites))
+		if err != nil {
+			t.Fatalf("could not decode variant %d: %v", variant.Width, err)
+		}
+		if config.Width != filmkritiken.ImageVariantWidths[i] || config.Height != filmkritiken.ImageVariantWidths[i]*3/2 {
+			t.Errorf("unexpected size of variant %d: %dx%d", variant.Width, config.Width, config.Height)
+		}
+	}
+}
+
+func TestGenerateImageVariants_NoUpscaling(t *testing.T) {
+	// given
+	imageBites := testPng(t, 300, 450)
+
+	// when
+	variants, err := filmkritiken.GenerateImageVariants(&imageBites)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(variants) != 1 || variants[0].Width != 200 {
+		t.Errorf("expected only the 200px variant, got %+v", variants)
+	}
+}
+
+func TestFilmkritikenServiceImpl_LoadImage_Variant(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	variant := []byte("variant")
+
+	imageRepository.EXPECT().FindImageVariant(ctx, "image_1", 500).Return(filmkritiken.NewImageFile("image/jpeg", variant), nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	result, err := service.LoadImage(ctx, "image_1", 300)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Size != int64(len(variant)) || result.ContentType != "image/jpeg" {
+		t.Errorf("expected variant, got %+v", result)
+	}
+}
+
+func TestFilmkritikenServiceImpl_LoadImage_VariantMissingFallsBackToOriginal(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	original := testPng(t, 10, 10)
+
+	imageRepository.EXPECT().FindImageVariant(ctx, "image_1", 200).
+		Return(nil, domainErrors.NewNotFoundErrorFromString("Bildvariante konnte nicht gefunden werden."))
+	imageRepository.EXPECT().FindImage(ctx, "image_1").Return(filmkritiken.NewImageFile("", original), nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	result, err := service.LoadImage(ctx, "image_1", 200)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Size != int64(len(original)) {
+		t.Errorf("expected original, got %d bytes", result.Size)
+	}
+	if result.ContentType != "image/png" {
+		t.Errorf("expected content type of legacy image to be detected, got %s", result.ContentType)
+	}
+}
+
+func TestFilmkritikenServiceImpl_LoadImage_WiderThanVariantsReturnsOriginal(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	filmRepository := mocks.NewMockFilmRepository(ctrl)
+	reiheRepository := mocks.NewMockReiheRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+
+	ctx := context.Background()
+	original := []byte("original")
+
+	imageRepository.EXPECT().FindImage(ctx, "image_1").Return(filmkritiken.NewImageFile("image/jpeg", original), nil)
+
+	service := filmkritiken.NewFilmkritikenService(filmkritikenRepository, filmRepository, reiheRepository, imageRepository, imageReferenceRepository, nil, nil)
+
+	// when
+	_, err := service.LoadImage(ctx, "image_1", 1200)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func testPng(t *testing.T, width int, height int) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatalf("could not encode test image: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestImageGarbageCollector_Collect(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	orphanedImageRepository := mocks.NewMockOrphanedImageRepository(ctrl)
+
+	ctx := context.Background()
+	allFilmkritiken := []*filmkritiken.Filmkritiken{
+		{Id: "fk_1", Film: &filmkritiken.Film{Titel: "Alien", Image: &filmkritiken.Image{Id: "referenced"}}},
+		{Id: "fk_2", Film: &filmkritiken.Film{Titel: "Zombiber", Image: &filmkritiken.Image{Id: "missing"}}},
+	}
+
+	filmkritikenRepository.EXPECT().GetFilmkritiken(ctx, nil).Return(allFilmkritiken, int64(2), nil)
+	imageRepository.EXPECT().GetImageIds(ctx).Return([]string{"referenced", "new_orphan", "old_orphan"}, nil)
+	orphanedImageRepository.EXPECT().GetOrphanedImages(ctx).Return(map[string]time.Time{
+		"old_orphan": time.Now().Add(-8 * 24 * time.Hour),
+		"referenced": time.Now().Add(-time.Hour),
+	}, nil)
+	orphanedImageRepository.EXPECT().MarkOrphanedImage(ctx, "new_orphan", gomock.Any()).Return(nil)
+	imageReferenceRepository.EXPECT().ReserveImageDeletion(ctx, "old_orphan").Return(true, nil)
+	imageRepository.EXPECT().DeleteImage(ctx, "old_orphan").Return(nil)
+	imageReferenceRepository.EXPECT().DeleteImageReferences(ctx, "old_orphan").Return(nil)
+	orphanedImageRepository.EXPECT().UnmarkOrphanedImage(ctx, "old_orphan").Return(nil)
+	orphanedImageRepository.EXPECT().UnmarkOrphanedImage(ctx, "referenced").Return(nil)
+
+	gc := filmkritiken.NewImageGarbageCollector(filmkritikenRepository, imageRepository, imageReferenceRepository, orphanedImageRepository, 7*24*time.Hour)
+
+	// when
+	result, err := gc.Collect(ctx, true)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.DeletedImages) != 1 || result.DeletedImages[0] != "old_orphan" {
+		t.Errorf("expected old_orphan to be deleted, got %v", result.DeletedImages)
+	}
+	if len(result.OrphanedImages) != 1 || result.OrphanedImages[0] != "new_orphan" {
+		t.Errorf("expected new_orphan to be kept within grace period, got %v", result.OrphanedImages)
+	}
+	if len(result.MissingImages) != 1 || result.MissingImages[0].FilmkritikenId != "fk_2" {
+		t.Errorf("expected missing image of fk_2 to be reported, got %+v", result.MissingImages)
+	}
+}
+
+func TestImageGarbageCollector_Collect_ReferencedMeanwhile(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	orphanedImageRepository := mocks.NewMockOrphanedImageRepository(ctrl)
+
+	ctx := context.Background()
+
+	filmkritikenRepository.EXPECT().GetFilmkritiken(ctx, nil).Return([]*filmkritiken.Filmkritiken{}, int64(0), nil)
+	imageRepository.EXPECT().GetImageIds(ctx).Return([]string{"old_orphan"}, nil)
+	orphanedImageRepository.EXPECT().GetOrphanedImages(ctx).Return(map[string]time.Time{
+		"old_orphan": time.Now().Add(-8 * 24 * time.Hour),
+	}, nil)
+	// an identical upload references the image after the Filmkritiken were read
+	imageReferenceRepository.EXPECT().ReserveImageDeletion(ctx, "old_orphan").Return(false, nil)
+	orphanedImageRepository.EXPECT().UnmarkOrphanedImage(ctx, "old_orphan").Return(nil)
+
+	gc := filmkritiken.NewImageGarbageCollector(filmkritikenRepository, imageRepository, imageReferenceRepository, orphanedImageRepository, 7*24*time.Hour)
+
+	// when
+	result, err := gc.Collect(ctx, true)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.DeletedImages) != 0 || len(result.OrphanedImages) != 0 {
+		t.Errorf("expected referenced image to be kept, got %+v", result)
+	}
+}
+
+func TestImageGarbageCollector_Collect_ReportOnly(t *testing.T) {
+	// given
+	ctrl := gomock.NewController(t)
+	filmkritikenRepository := mocks.NewMockFilmkritikenRepository(ctrl)
+	imageRepository := mocks.NewMockImageRepository(ctrl)
+	imageReferenceRepository := mocks.NewMockImageReferenceRepository(ctrl)
+	orphanedImageRepository := mocks.NewMockOrphanedImageRepository(ctrl)
+
+	ctx := context.Background()
+
+	filmkritikenRepository.EXPECT().GetFilmkritiken(ctx, nil).Return([]*filmkritiken.Filmkritiken{}, int64(0), nil)
+	imageRepository.EXPECT().GetImageIds(ctx).Return([]string{"old_orphan"}, nil)
+	orphanedImageRepository.EXPECT().GetOrphanedImages(ctx).Return(map[string]time.Time{
+		"old_orphan": time.Now().Add(-8 * 24 * time.Hour),
+	}, nil)
+	// no deletes and no marks
+
+	gc := filmkritiken.NewImageGarbageCollector(filmkritikenRepository, imageRepository, imageReferenceRepository, orphanedImageRepository, 7*24*time.Hour)
+
+	// when
+	result, err := gc.Collect(ctx, false)
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.DeletedImages) != 0 || len(result.OrphanedImages) != 1 {
+		t.Errorf("expected orphan to be reported only, got %+v", result)
+	}
+}
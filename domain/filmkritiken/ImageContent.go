@@ -0,0 +1,48 @@
+package filmkritiken
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+)
+
+// ErrImageAlreadyStored is returned by ImageRepository.SaveImage if an identical image has been stored
+// concurrently under the same content id.
+var ErrImageAlreadyStored = errors.New("image already stored")
+
+type bytesContent struct {
+	*bytes.Reader
+}
+
+func (bytesContent) Close() error {
+	return nil
+}
+
+// NewImageFile wraps an image held in memory so it can be handed to an ImageRepository.
+func NewImageFile(contentType string, imageBites []byte) *ImageFile {
+	return &ImageFile{
+		ContentType: contentType,
+		Size:        int64(len(imageBites)),
+		Content:     NewBytesContent(imageBites),
+	}
+}
+
+// ReadContent reads the whole content of the image into memory and closes it.
+func (f *ImageFile) ReadContent() ([]byte, error) {
+	defer f.Content.Close()
+	return io.ReadAll(f.Content)
+}
+
+// NewBytesContent returns an io.ReadSeekCloser over the given bytes.
+func NewBytesContent(data []byte) io.ReadSeekCloser {
+	return bytesContent{bytes.NewReader(data)}
+}
+
+// ImageContentId returns the id of an image, the hex encoded SHA-256 of its content. Identical uploads
+// therefore share one stored image, and the id can be used as a strong ETag.
+func ImageContentId(imageBites []byte) string {
+	hash := sha256.Sum256(imageBites)
+	return hex.EncodeToString(hash[:])
+}
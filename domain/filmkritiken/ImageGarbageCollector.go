@@ -0,0 +1,174 @@
+package filmkritiken
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type (
+	ImageGarbageCollectorConfig struct {
+		// Interval of the in-process garbage collection, disabled if 0
+		Interval time.Duration `env:"IMAGE_GC_INTERVAL" envDefault:"0"`
+		// GracePeriod an image has to be unreferenced before it is deleted
+		GracePeriod time.Duration `env:"IMAGE_GC_GRACE_PERIOD" envDefault:"168h"`
+	}
+
+	MissingImage struct {
+		FilmkritikenId string
+		Titel          string
+		ImageId        string
+	}
+
+	ImageGarbageCollectionResult struct {
+		// OrphanedImages are not referenced by any Film, but still within the grace period
+		OrphanedImages []string
+		DeletedImages  []string
+		// MissingImages are referenced by a Film, but do not exist
+		MissingImages []*MissingImage
+	}
+
+	ImageGarbageCollector struct {
+		filmkritikenRepository   FilmkritikenRepository
+		imageRepository          ImageRepository
+		imageReferenceRepository ImageReferenceRepository
+		orphanedImageRepository  OrphanedImageRepository
+		gracePeriod              time.Duration
+		now                      func() time.Time
+	}
+)
+
+func NewImageGarbageCollector(
+	filmkritikenRepository FilmkritikenRepository,
+	imageRepository ImageRepository,
+	imageReferenceRepository ImageReferenceRepository,
+	orphanedImageRepository OrphanedImageRepository,
+	gracePeriod time.Duration,
+) *ImageGarbageCollector {
+	return &ImageGarbageCollector{
+		filmkritikenRepository:   filmkritikenRepository,
+		imageRepository:          imageRepository,
+		imageReferenceRepository: imageReferenceRepository,
+		orphanedImageRepository:  orphanedImageRepository,
+		gracePeriod:              gracePeriod,
+		now:                      time.Now,
+	}
+}
+
+// Collect finds images not referenced by any Film and deletes them once they have been unreferenced
+// for the grace period. Without deleteOrphans, nothing is changed and only the report is returned.
+func (gc *ImageGarbageCollector) Collect(ctx context.Context, deleteOrphans bool) (*ImageGarbageCollectionResult, error) {
+	allFilmkritiken, _, err := gc.filmkritikenRepository.GetFilmkritiken(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	imageIds, err := gc.imageRepository.GetImageIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orphanedSince, err := gc.orphanedImageRepository.GetOrphanedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(imageIds))
+	for _, imageId := range imageIds {
+		existing[imageId] = true
+	}
+
+	result := &ImageGarbageCollectionResult{
+		OrphanedImages: make([]string, 0),
+		DeletedImages:  make([]string, 0),
+		MissingImages:  make([]*MissingImage, 0),
+	}
+
+	referenced := make(map[string]bool, len(allFilmkritiken))
+	for _, fk := range allFilmkritiken {
+		if fk.Film == nil || fk.Film.Image == nil || fk.Film.Image.Id == "" {
+			continue
+		}
+		imageId := fk.Film.Image.Id
+		referenced[imageId] = true
+		if !existing[imageId] {
+			result.MissingImages = append(result.MissingImages, &MissingImage{FilmkritikenId: fk.Id, Titel: fk.Film.Titel, ImageId: imageId})
+		}
+	}
+
+	now := gc.now()
+	for _, imageId := range imageIds {
+		if referenced[imageId] {
+			continue
+		}
+
+		since, marked := orphanedSince[imageId]
+		if !marked {
+			since = now
+			if deleteOrphans {
+				if err := gc.orphanedImageRepository.MarkOrphanedImage(ctx, imageId, since); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if !deleteOrphans || now.Sub(since) < gc.gracePeriod {
+			result.OrphanedImages = append(result.OrphanedImages, imageId)
+			continue
+		}
+
+		deleted, err := gc.deleteImage(ctx, imageId)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted {
+			referenced[imageId] = true
+			continue
+		}
+		result.DeletedImages = append(result.DeletedImages, imageId)
+	}
+
+	// images referenced again (e.g. by an identical upload) are no longer orphaned
+	for imageId := range orphanedSince {
+		if deleteOrphans && (referenced[imageId] || !existing[imageId]) {
+			if err := gc.orphanedImageRepository.UnmarkOrphanedImage(ctx, imageId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Strings(result.OrphanedImages)
+	sort.Strings(result.DeletedImages)
+	return result, nil
+}
+
+// deleteImage deletes the image unless it has been referenced since the Filmkritiken were read, an upload
+// of the same image cannot reference it until the deletion is finished. A failed deletion keeps the
+// reservation and is repeated by the next collection.
+func (gc *ImageGarbageCollector) deleteImage(ctx context.Context, imageId string) (bool, error) {
+	reserved, err := gc.imageReferenceRepository.ReserveImageDeletion(ctx, imageId)
+	if err != nil || !reserved {
+		return false, err
+	}
+	if err := gc.imageRepository.DeleteImage(ctx, imageId); err != nil {
+		return false, err
+	}
+	if err := gc.imageReferenceRepository.DeleteImageReferences(ctx, imageId); err != nil {
+		return false, err
+	}
+	return true, gc.orphanedImageRepository.UnmarkOrphanedImage(ctx, imageId)
+}
+
+// RunPeriodically collects garbage every interval until the context is cancelled and hands every
+// result to report.
+func (gc *ImageGarbageCollector) RunPeriodically(ctx context.Context, interval time.Duration, report func(*ImageGarbageCollectionResult, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report(gc.Collect(ctx, true))
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package filmkritiken
+
+import (
+	"bytes"
+	"image"
+
+	"github.com/buckket/go-blurhash"
+	"golang.org/x/image/draw"
+)
+
+const (
+	blurhashXComponents = 4
+	blurhashYComponents = 3
+	// the BlurHash only keeps a few components, so it is computed from a small thumbnail (much faster)
+	blurhashThumbnailWidth = 32
+)
+
+// SetImagePlaceholder stores the dimensions and a BlurHash of the image on the Image, so clients can
+// reserve the space and show a blurred preview while the poster loads.
+func SetImagePlaceholder(img *Image, imageBites *[]byte) error {
+	original, _, err := image.Decode(bytes.NewReader(*imageBites))
+	if err != nil {
+		return err
+	}
+
+	bounds := original.Bounds()
+	width := min(blurhashThumbnailWidth, bounds.Dx())
+	height := max(1, bounds.Dy()*width/bounds.Dx())
+	thumbnail := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.ApproxBiLinear.Scale(thumbnail, thumbnail.Bounds(), original, bounds, draw.Src, nil)
+
+	hash, err := blurhash.Encode(blurhashXComponents, blurhashYComponents, thumbnail)
+	if err != nil {
+		return err
+	}
+
+	img.Width = bounds.Dx()
+	img.Height = bounds.Dy()
+	img.Blurhash = hash
+	return nil
+}
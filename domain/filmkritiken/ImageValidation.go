@@ -0,0 +1,69 @@
+package filmkritiken
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const (
+	// MaxImageSize is the maximum size of an uploaded poster in bytes.
+	MaxImageSize = 8 << 20
+	// maxImagePixels protects against decompression bombs (tiny files with huge dimensions).
+	maxImagePixels = 50_000_000
+)
+
+var allowedImageContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// ValidateImage checks size, type (detected from the magic bytes, not from the client) and header
+// of an uploaded image and returns its content type.
+func ValidateImage(imageBites *[]byte) (string, error) {
+	if imageBites == nil || len(*imageBites) == 0 {
+		return "", errors.NewInvalidInputErrorFromString("Es wurde kein Bild hochgeladen.")
+	}
+	if len(*imageBites) > MaxImageSize {
+		return "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Das Bild ist zu groß (maximal %d MB).", MaxImageSize>>20))
+	}
+
+	contentType := http.DetectContentType(*imageBites)
+	if !allowedImageContentTypes[contentType] {
+		return "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Dateityp %s wird nicht unterstützt. Erlaubt sind JPEG, PNG, GIF und WebP.", contentType))
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(*imageBites))
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return "", errors.NewInvalidInputErrorFromString("Das Bild ist beschädigt und kann nicht gelesen werden.")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return "", errors.NewInvalidInputErrorFromString("Die Auflösung des Bildes ist zu groß.")
+	}
+
+	return contentType, nil
+}
+
+// DetectImageContentType returns the stored content type or sniffs it for images saved without one.
+// The content is rewound afterwards.
+func DetectImageContentType(imageFile *ImageFile) (string, error) {
+	if imageFile.ContentType != "" {
+		return imageFile.ContentType, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(imageFile.Content, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := imageFile.Content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
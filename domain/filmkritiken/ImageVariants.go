@@ -0,0 +1,89 @@
+package filmkritiken
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const imageVariantJpegQuality = 85
+
+// ImageVariantWidths are the widths (in pixels) of the resized variants generated for every poster.
+var ImageVariantWidths = []int{200, 500}
+
+// GenerateImageVariants creates a resized copy of the image for every width in ImageVariantWidths
+// that is smaller than the original. PNGs stay PNGs (transparency), everything else is encoded as JPEG.
+func GenerateImageVariants(imageBites *[]byte) ([]*ImageVariant, error) {
+	original, format, err := image.Decode(bytes.NewReader(*imageBites))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := original.Bounds()
+	variants := make([]*ImageVariant, 0, len(ImageVariantWidths))
+	for _, width := range ImageVariantWidthsFor(bounds.Dx()) {
+		height := bounds.Dy() * width / bounds.Dx()
+		if height < 1 {
+			height = 1
+		}
+		resized := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.CatmullRom.Scale(resized, resized.Bounds(), original, bounds, draw.Src, nil)
+
+		buf := &bytes.Buffer{}
+		contentType := "image/jpeg"
+		if format == "png" {
+			contentType = "image/png"
+			err = png.Encode(buf, resized)
+		} else {
+			err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: imageVariantJpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		variantBites := buf.Bytes()
+		variants = append(variants, &ImageVariant{
+			Width:       width,
+			ContentType: contentType,
+			Bites:       &variantBites,
+		})
+	}
+
+	return variants, nil
+}
+
+// ImageVariantWidthsFor returns the widths of ImageVariantWidths an image of the given width gets a variant of.
+func ImageVariantWidthsFor(originalWidth int) []int {
+	widths := make([]int, 0, len(ImageVariantWidths))
+	for _, width := range ImageVariantWidths {
+		if width < originalWidth {
+			widths = append(widths, width)
+		}
+	}
+	return widths
+}
+
+// closestImageVariantWidth returns the smallest variant width that is at least as wide as requested,
+// or 0 if the original image should be used.
+func closestImageVariantWidth(requestedWidth int) int {
+	if requestedWidth <= 0 {
+		return 0
+	}
+	for _, width := range ImageVariantWidths {
+		if width >= requestedWidth {
+			return width
+		}
+	}
+	return 0
+}
+
+// ImageVariantId returns the id under which the variant of the given width is stored.
+func ImageVariantId(imageId string, width int) string {
+	return fmt.Sprintf("%s_w%d", imageId, width)
+}
@@ -0,0 +1,36 @@
+package filmkritiken
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const (
+	maxKategorien      = 20
+	maxKategorieLength = 40
+)
+
+// NormalizeKategorien trims genres or tags, drops empty entries and duplicates (ignoring case)
+// and keeps the order in which they were given.
+func NormalizeKategorien(kategorien []string) ([]string, error) {
+	normalized := make([]string, 0, len(kategorien))
+	seen := make(map[string]bool, len(kategorien))
+	for _, kategorie := range kategorien {
+		kategorie = strings.Join(strings.Fields(kategorie), " ")
+		if kategorie == "" || seen[strings.ToLower(kategorie)] {
+			continue
+		}
+		if len([]rune(kategorie)) > maxKategorieLength {
+			return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Genres und Tags dürfen höchstens %d Zeichen lang sein.", maxKategorieLength))
+		}
+		seen[strings.ToLower(kategorie)] = true
+		normalized = append(normalized, kategorie)
+	}
+
+	if len(normalized) > maxKategorien {
+		return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Es sind höchstens %d Genres bzw. Tags erlaubt.", maxKategorien))
+	}
+	return normalized, nil
+}
@@ -0,0 +1,161 @@
+package filmkritiken
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const (
+	// KonfliktRegel_Abbrechen refuses the merge as long as there are conflicts
+	KonfliktRegel_Abbrechen KonfliktRegel = "abbrechen"
+	// KonfliktRegel_Ziel keeps the Bewertung of the member the other one is merged into
+	KonfliktRegel_Ziel KonfliktRegel = "ziel"
+	// KonfliktRegel_Quelle keeps the Bewertung of the merged (renamed) member
+	KonfliktRegel_Quelle KonfliktRegel = "quelle"
+)
+
+const Aktion_MitgliedZusammenfuehren = "mitglied.zusammenfuehren"
+
+// NormalizeMitgliedZusammenfuehrung trims both names and defaults the KonfliktRegel to KonfliktRegel_Abbrechen.
+func NormalizeMitgliedZusammenfuehrung(zusammenfuehrung *MitgliedZusammenfuehrung) error {
+	zusammenfuehrung.Von = strings.Join(strings.Fields(zusammenfuehrung.Von), " ")
+	zusammenfuehrung.Nach = strings.Join(strings.Fields(zusammenfuehrung.Nach), " ")
+	if zusammenfuehrung.Von == "" || zusammenfuehrung.Nach == "" {
+		return errors.NewInvalidInputErrorFromString("Beide Mitglieder müssen angegeben werden.")
+	}
+	if zusammenfuehrung.Von == zusammenfuehrung.Nach {
+		return errors.NewInvalidInputErrorFromString("Ein Mitglied kann nicht mit sich selbst zusammengeführt werden.")
+	}
+
+	switch zusammenfuehrung.Regel {
+	case "":
+		zusammenfuehrung.Regel = KonfliktRegel_Abbrechen
+	case KonfliktRegel_Abbrechen, KonfliktRegel_Ziel, KonfliktRegel_Quelle:
+	default:
+		return errors.NewInvalidInputErrorFromString(fmt.Sprintf("Unbekannte Regel für Konflikte: %s", zusammenfuehrung.Regel))
+	}
+	return nil
+}
+
+// MergeMitglied renames the member Von to Nach in the given Filmkritiken (names are compared ignoring case)
+// and returns the changed Filmkritiken. Conflicts are resolved by the KonfliktRegel, with
+// KonfliktRegel_Abbrechen the conflicting Filmkritiken are left untouched.
+func MergeMitglied(allFilmkritiken []*Filmkritiken, zusammenfuehrung *MitgliedZusammenfuehrung) (*ZusammenfuehrungErgebnis, []*Filmkritiken) {
+	ergebnis := &ZusammenfuehrungErgebnis{
+		Von:             zusammenfuehrung.Von,
+		Nach:            zusammenfuehrung.Nach,
+		Regel:           zusammenfuehrung.Regel,
+		FilmkritikenIds: make([]string, 0),
+		Konflikte:       make([]*ZusammenfuehrungKonflikt, 0),
+	}
+
+	// a rename that only changes the case must not treat the old name as the target
+	caseOnly := strings.EqualFold(zusammenfuehrung.Von, zusammenfuehrung.Nach)
+	isNach := func(name string) bool {
+		return name == zusammenfuehrung.Nach || (!caseOnly && strings.EqualFold(name, zusammenfuehrung.Nach))
+	}
+	isVon := func(name string) bool {
+		return !isNach(name) && strings.EqualFold(name, zusammenfuehrung.Von)
+	}
+
+	// all Bewertungen of both members belong to the user of the target afterwards (or the one of the source, if
+	// only that is known by id), otherwise renaming the user at its next login would split them again
+	nachId, vonId := "", ""
+	for _, fk := range allFilmkritiken {
+		for _, bewertung := range fk.Bewertungen {
+			if nachId == "" && isNach(bewertung.Von) {
+				nachId = bewertung.VonId
+			}
+			if vonId == "" && isVon(bewertung.Von) {
+				vonId = bewertung.VonId
+			}
+		}
+	}
+	ergebnis.BenutzerId = nachId
+	if ergebnis.BenutzerId == "" {
+		ergebnis.BenutzerId = vonId
+	}
+	if vonId != ergebnis.BenutzerId {
+		ergebnis.VonBenutzerId = vonId
+	}
+
+	changed := make([]*Filmkritiken, 0)
+	for _, fk := range allFilmkritiken {
+		var bewertungVon, bewertungNach *Bewertung
+		for _, bewertung := range fk.Bewertungen {
+			if isVon(bewertung.Von) {
+				bewertungVon = bewertung
+			} else if isNach(bewertung.Von) {
+				bewertungNach = bewertung
+			}
+		}
+		beitragVon := fk.Details != nil && isVon(fk.Details.BeitragVon)
+		nachOhneId := bewertungNach != nil && bewertungNach.VonId == "" && ergebnis.BenutzerId != ""
+
+		if bewertungVon == nil && !beitragVon && !nachOhneId {
+			continue
+		}
+
+		if bewertungVon != nil && bewertungNach != nil {
+			// copies, the Bewertungen are changed below
+			vorher, nachher := *bewertungVon, *bewertungNach
+			ergebnis.Konflikte = append(ergebnis.Konflikte, &ZusammenfuehrungKonflikt{
+				FilmkritikenId: fk.Id,
+				Titel:          filmTitel(fk),
+				BewertungVon:   &vorher,
+				BewertungNach:  &nachher,
+			})
+			if zusammenfuehrung.Regel == KonfliktRegel_Abbrechen {
+				continue
+			}
+		}
+
+		if bewertungVon != nil {
+			switch {
+			case bewertungNach == nil:
+				bewertungVon.Von = zusammenfuehrung.Nach
+				if ergebnis.BenutzerId != "" {
+					bewertungVon.VonId = ergebnis.BenutzerId
+				}
+			case zusammenfuehrung.Regel == KonfliktRegel_Quelle:
+				bewertungNach.Wertung = bewertungVon.Wertung
+				bewertungNach.Enthaltung = bewertungVon.Enthaltung
+				fk.Bewertungen = removeBewertung(fk.Bewertungen, bewertungVon)
+			default:
+				fk.Bewertungen = removeBewertung(fk.Bewertungen, bewertungVon)
+			}
+			ergebnis.AnzahlBewertungen++
+		}
+		if beitragVon {
+			fk.Details.BeitragVon = zusammenfuehrung.Nach
+			ergebnis.AnzahlBeitraege++
+		}
+		if nachOhneId {
+			bewertungNach.VonId = ergebnis.BenutzerId
+		}
+
+		ergebnis.FilmkritikenIds = append(ergebnis.FilmkritikenIds, fk.Id)
+		changed = append(changed, fk)
+	}
+
+	return ergebnis, changed
+}
+
+func removeBewertung(bewertungen []*Bewertung, remove *Bewertung) []*Bewertung {
+	result := make([]*Bewertung, 0, len(bewertungen))
+	for _, bewertung := range bewertungen {
+		if bewertung != remove {
+			result = append(result, bewertung)
+		}
+	}
+	return result
+}
+
+func filmTitel(fk *Filmkritiken) string {
+	if fk.Film == nil {
+		return ""
+	}
+	return fk.Film.Titel
+}
@@ -0,0 +1,117 @@
+package filmkritiken
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const maxReiheNameLength = 100
+
+// NormalizeReiheName trims the name of a Reihe and checks its length.
+func NormalizeReiheName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errors.NewInvalidInputErrorFromString("Der Name der Reihe muss angegeben werden.")
+	}
+	if len([]rune(name)) > maxReiheNameLength {
+		return "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Der Name der Reihe darf höchstens %d Zeichen lang sein.", maxReiheNameLength))
+	}
+	return name, nil
+}
+
+// SetReiheEintrag assigns the Filmkritiken to the position of the Reihe (or moves it there). Without a
+// position (0 or less), the Filmkritiken is appended.
+func SetReiheEintrag(reihe *Reihe, filmkritikenId string, position int) {
+	eintraege := make([]*ReiheEintrag, 0, len(reihe.Eintraege)+1)
+	maxPosition := 0
+	for _, eintrag := range reihe.Eintraege {
+		if eintrag.FilmkritikenId == filmkritikenId {
+			continue
+		}
+		maxPosition = max(maxPosition, eintrag.Position)
+		eintraege = append(eintraege, eintrag)
+	}
+	if position <= 0 {
+		position = maxPosition + 1
+	}
+
+	eintraege = append(eintraege, &ReiheEintrag{FilmkritikenId: filmkritikenId, Position: position})
+	sort.SliceStable(eintraege, func(i, j int) bool {
+		return eintraege[i].Position < eintraege[j].Position
+	})
+	reihe.Eintraege = eintraege
+}
+
+// RemoveReiheEintrag removes the Filmkritiken from the Reihe and reports whether it was part of it.
+func RemoveReiheEintrag(reihe *Reihe, filmkritikenId string) bool {
+	eintraege := make([]*ReiheEintrag, 0, len(reihe.Eintraege))
+	for _, eintrag := range reihe.Eintraege {
+		if eintrag.FilmkritikenId != filmkritikenId {
+			eintraege = append(eintraege, eintrag)
+		}
+	}
+	removed := len(eintraege) != len(reihe.Eintraege)
+	reihe.Eintraege = eintraege
+	return removed
+}
+
+// CalculateReiheUebersicht returns the entries of the Reihe in order with their average ratings, the
+// average of all ratings of the Reihe and its best and worst entry. Abstentions are not counted.
+func CalculateReiheUebersicht(reihe *Reihe, allFilmkritiken []*Filmkritiken) *ReiheUebersicht {
+	byId := make(map[string]*Filmkritiken, len(allFilmkritiken))
+	for _, fk := range allFilmkritiken {
+		byId[fk.Id] = fk
+	}
+
+	uebersicht := &ReiheUebersicht{
+		Id:    reihe.Id,
+		Name:  reihe.Name,
+		Filme: make([]*ReiheFilm, 0, len(reihe.Eintraege)),
+	}
+	gesamt := &wertungSumme{}
+
+	for _, eintrag := range reihe.Eintraege {
+		fk, found := byId[eintrag.FilmkritikenId]
+		if !found {
+			continue
+		}
+
+		reiheFilm := &ReiheFilm{Position: eintrag.Position, FilmkritikenId: fk.Id}
+		if fk.Film != nil {
+			reiheFilm.Titel = fk.Film.Titel
+		}
+		if fk.Details != nil {
+			reiheFilm.BesprochenAm = fk.Details.BesprochenAm
+		}
+
+		summe := &wertungSumme{}
+		for _, bewertung := range fk.Bewertungen {
+			if !bewertung.Enthaltung {
+				summe.anzahl++
+				summe.summe += bewertung.Wertung
+			}
+		}
+		reiheFilm.AnzahlBewertungen = summe.anzahl
+		reiheFilm.Durchschnitt = summe.durchschnitt()
+		gesamt.anzahl += summe.anzahl
+		gesamt.summe += summe.summe
+
+		if summe.anzahl > 0 {
+			if uebersicht.Beste == nil || reiheFilm.Durchschnitt > uebersicht.Beste.Durchschnitt {
+				uebersicht.Beste = reiheFilm
+			}
+			if uebersicht.Schlechteste == nil || reiheFilm.Durchschnitt < uebersicht.Schlechteste.Durchschnitt {
+				uebersicht.Schlechteste = reiheFilm
+			}
+		}
+
+		uebersicht.Filme = append(uebersicht.Filme, reiheFilm)
+	}
+
+	uebersicht.AnzahlBewertungen = gesamt.anzahl
+	uebersicht.Durchschnitt = gesamt.durchschnitt()
+	return uebersicht
+}
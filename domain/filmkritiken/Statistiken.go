@@ -0,0 +1,112 @@
+package filmkritiken
+
+import (
+	"sort"
+	"time"
+)
+
+type wertungSumme struct {
+	anzahl int
+	summe  int
+}
+
+func (w *wertungSumme) durchschnitt() float64 {
+	if w.anzahl == 0 {
+		return 0
+	}
+	return float64(w.summe) / float64(w.anzahl)
+}
+
+// mitglied identifies the member of a Bewertung by the user id, Bewertungen of members without login are
+// identified by the name only
+func mitglied(bewertung *Bewertung) string {
+	if bewertung.VonId != "" {
+		return bewertung.VonId
+	}
+	return "name:" + bewertung.Von
+}
+
+// CalculateMitgliederStatistiken returns the number of ratings and the average rating of every member,
+// overall and per genre. Abstentions (Enthaltung) are counted separately and not part of the averages.
+// Members are grouped by user id, named after their latest Bewertung.
+func CalculateMitgliederStatistiken(allFilmkritiken []*Filmkritiken) []*MitgliedStatistik {
+	letzteBewertung := make(map[string]*Bewertung)
+	letzteBewertungAm := make(map[string]*time.Time)
+	gesamt := make(map[string]*wertungSumme)
+	enthaltungen := make(map[string]int)
+	proGenre := make(map[string]map[string]*wertungSumme)
+
+	for _, fk := range allFilmkritiken {
+		var genres []string
+		if fk.Film != nil {
+			genres = fk.Film.Genres
+		}
+
+		for _, bewertung := range fk.Bewertungen {
+			key := mitglied(bewertung)
+			if letzteBewertung[key] == nil || neuer(besprochenAm(fk), letzteBewertungAm[key]) {
+				letzteBewertung[key] = bewertung
+				letzteBewertungAm[key] = besprochenAm(fk)
+			}
+
+			if gesamt[key] == nil {
+				gesamt[key] = &wertungSumme{}
+			}
+			if bewertung.Enthaltung {
+				enthaltungen[key]++
+				continue
+			}
+
+			gesamt[key].anzahl++
+			gesamt[key].summe += bewertung.Wertung
+
+			if proGenre[key] == nil {
+				proGenre[key] = make(map[string]*wertungSumme)
+			}
+			for _, genre := range genres {
+				if proGenre[key][genre] == nil {
+					proGenre[key][genre] = &wertungSumme{}
+				}
+				proGenre[key][genre].anzahl++
+				proGenre[key][genre].summe += bewertung.Wertung
+			}
+		}
+	}
+
+	statistiken := make([]*MitgliedStatistik, 0, len(gesamt))
+	for key, summe := range gesamt {
+		genreStatistiken := make([]*GenreStatistik, 0, len(proGenre[key]))
+		for genre, genreSumme := range proGenre[key] {
+			genreStatistiken = append(genreStatistiken, &GenreStatistik{
+				Genre:             genre,
+				AnzahlBewertungen: genreSumme.anzahl,
+				Durchschnitt:      genreSumme.durchschnitt(),
+			})
+		}
+		sort.Slice(genreStatistiken, func(i, j int) bool {
+			return genreStatistiken[i].Genre < genreStatistiken[j].Genre
+		})
+
+		statistiken = append(statistiken, &MitgliedStatistik{
+			VonId:              letzteBewertung[key].VonId,
+			Name:               letzteBewertung[key].Von,
+			AnzahlBewertungen:  summe.anzahl,
+			AnzahlEnthaltungen: enthaltungen[key],
+			Durchschnitt:       summe.durchschnitt(),
+			Genres:             genreStatistiken,
+		})
+	}
+	sort.Slice(statistiken, func(i, j int) bool {
+		if statistiken[i].Name != statistiken[j].Name {
+			return statistiken[i].Name < statistiken[j].Name
+		}
+		return statistiken[i].VonId < statistiken[j].VonId
+	})
+
+	return statistiken
+}
+
+// neuer reports whether a screening took place after another one, unscheduled screenings are the oldest
+func neuer(a *time.Time, b *time.Time) bool {
+	return a != nil && (b == nil || a.After(*b))
+}
@@ -1,11 +1,13 @@
 package filmkritiken
 
 import (
+	"io"
 	"time"
 )
 
 const (
 	Context_Username ContextKey = "username"
+	Context_UserId   ContextKey = "userId"
 	Context_TraceId  ContextKey = "traceId"
 )
 
@@ -20,28 +22,86 @@ type (
 	}
 
 	Film struct {
-		//Id               UUID   `json:"id" bson:"_id"`
-		Titel            string `json:"titel"`
-		Altersfreigabe   int    `json:"altersfreigabe"`
-		Erscheinungsjahr int    `json:"erscheinungsjahr"`
-		Regie            string `json:"regie"`
-		Laenge           int    `json:"laenge"`
-		Originaltitel    string `json:"originaltitel"`
-		Originalsprache  string `json:"originalsprache"`
-		Produktionsland  string `json:"produktionsland"`
-		Image            *Image `json:"image"`
+		// Id of the Film in its own collection, Filmkritiken keep a copy of the Film they screened
+		Id               string        `json:"id" bson:"_id,omitempty"`
+		Titel            string        `json:"titel"`
+		Altersfreigabe   int           `json:"altersfreigabe"`
+		Erscheinungsjahr int           `json:"erscheinungsjahr"`
+		Regie            string        `json:"regie"`
+		Laenge           int           `json:"laenge"`
+		Originaltitel    string        `json:"originaltitel"`
+		Originalsprache  string        `json:"originalsprache"`
+		Produktionsland  string        `json:"produktionsland"`
+		Image            *Image        `json:"image"`
+		Genres           []string      `json:"genres"`
+		Tags             []string      `json:"tags"`
+		Besetzung        []*Darsteller `json:"besetzung"`
+	}
+
+	Darsteller struct {
+		Name  string `json:"name"`
+		Rolle string `json:"rolle"`
+		// Reihenfolge in the credits, leading actors first
+		Reihenfolge int `json:"reihenfolge"`
 	}
 
 	Bewertung struct {
+		// VonId is the stable user id, Von the display name of the member
+		VonId      string `json:"vonid"`
+		Von        string `json:"von"`
+		Wertung    int    `json:"wertung"`
+		Enthaltung bool   `json:"enthaltung"`
+		// ErfasstVon is the moderator who entered the Bewertung at the meeting, empty if the member rated himself
+		ErfasstVon string `json:"erfasstvon,omitempty"`
+	}
+
+	// BewertungEingabe is the Bewertung of a member entered by the moderator, VonId is empty for members
+	// without login
+	BewertungEingabe struct {
+		VonId      string `json:"vonid"`
 		Von        string `json:"von"`
 		Wertung    int    `json:"wertung"`
 		Enthaltung bool   `json:"enthaltung"`
 	}
 
+	// BewertungenErfassung are the Details of the AuditEintrag of Bewertungen entered by the moderator
+	BewertungenErfassung struct {
+		FilmkritikenId string       `json:"filmkritikenid"`
+		Bewertungen    []*Bewertung `json:"bewertungen"`
+	}
+
 	Image struct {
 		Source    string `json:"source"`
 		Copyright string `json:"copyright"`
 		Id        string `json:"id"`
+		// Width and Height of the original image in pixels
+		Width    int    `json:"width"`
+		Height   int    `json:"height"`
+		Blurhash string `json:"blurhash"`
+	}
+
+	// FilmCandidate is a film found by a FilmMetadataProvider, pre-filled for creating a Filmkritik
+	FilmCandidate struct {
+		ExterneId string `json:"externeid"`
+		Film      *Film  `json:"film"`
+		// PosterRef can be sent instead of an uploaded image to use the provider's poster
+		PosterRef string `json:"posterref"`
+		PosterUrl string `json:"posterurl"`
+	}
+
+	ImageFile struct {
+		// Id identifies the stored file (original or variant), e.g. for ETags
+		Id          string
+		ContentType string
+		ModTime     time.Time
+		Size        int64
+		Content     io.ReadSeekCloser
+	}
+
+	ImageVariant struct {
+		Width       int
+		ContentType string
+		Bites       *[]byte
 	}
 
 	FilmkritikenDetails struct {
@@ -57,11 +117,158 @@ type (
 		Titel      string
 		Jahr       int
 		BeitragVon string
+		Genre      string
+		Tag        string
+		Darsteller string
+		// Reihe only returns the Filmkritiken assigned to the Reihe with this id
+		Reihe      string
 		Sortierung string
 	}
 
 	FilterOptions struct {
 		Jahre       []int    `json:"jahre"`
 		Beitragende []string `json:"beitragende"`
+		Genres      []string `json:"genres"`
+		Tags        []string `json:"tags"`
+	}
+
+	MitgliedStatistik struct {
+		VonId              string            `json:"vonid"`
+		Name               string            `json:"name"`
+		AnzahlBewertungen  int               `json:"anzahlbewertungen"`
+		AnzahlEnthaltungen int               `json:"anzahlenthaltungen"`
+		Durchschnitt       float64           `json:"durchschnitt"`
+		Genres             []*GenreStatistik `json:"genres"`
+	}
+
+	// FilmUebersicht shows every screening (Filmkritiken) of a Film to compare the ratings over time
+	FilmUebersicht struct {
+		Film          *Film              `json:"film"`
+		Vorfuehrungen []*Vorfuehrung     `json:"vorfuehrungen"`
+		Mitglieder    []*MitgliedVerlauf `json:"mitglieder"`
+	}
+
+	Vorfuehrung struct {
+		FilmkritikenId    string     `json:"filmkritikenid"`
+		BeitragVon        string     `json:"beitragvon"`
+		BesprochenAm      *time.Time `json:"besprochenam"`
+		AnzahlBewertungen int        `json:"anzahlbewertungen"`
+		Durchschnitt      float64    `json:"durchschnitt"`
+	}
+
+	MitgliedVerlauf struct {
+		VonId     string            `json:"vonid"`
+		Name      string            `json:"name"`
+		Wertungen []*VerlaufWertung `json:"wertungen"`
+		// Veraenderung between the first and the latest rating, 0 if rated at most once
+		Veraenderung int `json:"veraenderung"`
+	}
+
+	VerlaufWertung struct {
+		FilmkritikenId string     `json:"filmkritikenid"`
+		BesprochenAm   *time.Time `json:"besprochenam"`
+		Wertung        int        `json:"wertung"`
+		Enthaltung     bool       `json:"enthaltung"`
+	}
+
+	// Reihe groups Filmkritiken of a franchise or series in the order of its entries
+	Reihe struct {
+		Id        string          `json:"id" bson:"_id"`
+		Name      string          `json:"name"`
+		Eintraege []*ReiheEintrag `json:"eintraege"`
+	}
+
+	ReiheEintrag struct {
+		FilmkritikenId string `json:"filmkritikenid"`
+		Position       int    `json:"position"`
+	}
+
+	ReiheUebersicht struct {
+		Id                string       `json:"id"`
+		Name              string       `json:"name"`
+		Filme             []*ReiheFilm `json:"filme"`
+		AnzahlBewertungen int          `json:"anzahlbewertungen"`
+		Durchschnitt      float64      `json:"durchschnitt"`
+		// Beste and Schlechteste entry by average rating, nil if nothing was rated yet
+		Beste        *ReiheFilm `json:"beste"`
+		Schlechteste *ReiheFilm `json:"schlechteste"`
+	}
+
+	ReiheFilm struct {
+		Position          int        `json:"position"`
+		FilmkritikenId    string     `json:"filmkritikenid"`
+		Titel             string     `json:"titel"`
+		BesprochenAm      *time.Time `json:"besprochenam"`
+		AnzahlBewertungen int        `json:"anzahlbewertungen"`
+		Durchschnitt      float64    `json:"durchschnitt"`
+	}
+
+	// Person lists all reviewed films a person was involved in as director or actor
+	Person struct {
+		Name  string        `json:"name"`
+		Filme []*PersonFilm `json:"filme"`
+	}
+
+	PersonFilm struct {
+		FilmkritikenId   string     `json:"filmkritikenid"`
+		Titel            string     `json:"titel"`
+		Erscheinungsjahr int        `json:"erscheinungsjahr"`
+		BesprochenAm     *time.Time `json:"besprochenam"`
+		Regie            bool       `json:"regie"`
+		// Rolle played by the person, empty if the person was not part of the cast
+		Rolle             string  `json:"rolle"`
+		Darsteller        bool    `json:"darsteller"`
+		AnzahlBewertungen int     `json:"anzahlbewertungen"`
+		Durchschnitt      float64 `json:"durchschnitt"`
+	}
+
+	// KonfliktRegel decides which Bewertung is kept if both merged members rated the same Filmkritiken
+	KonfliktRegel string
+
+	// MitgliedZusammenfuehrung renames the member Von to Nach, if Nach already exists both are merged
+	MitgliedZusammenfuehrung struct {
+		Von   string        `json:"von"`
+		Nach  string        `json:"nach"`
+		Regel KonfliktRegel `json:"regel"`
+	}
+
+	ZusammenfuehrungErgebnis struct {
+		Von   string        `json:"von"`
+		Nach  string        `json:"nach"`
+		Regel KonfliktRegel `json:"regel"`
+		// FilmkritikenIds of all changed Filmkritiken
+		FilmkritikenIds   []string                    `json:"filmkritikenids"`
+		AnzahlBewertungen int                         `json:"anzahlbewertungen"`
+		AnzahlBeitraege   int                         `json:"anzahlbeitraege"`
+		Konflikte         []*ZusammenfuehrungKonflikt `json:"konflikte"`
+		// BenutzerId is the user all Bewertungen of both members belong to afterwards, empty if neither has one
+		BenutzerId string `json:"benutzerid,omitempty"`
+		// VonBenutzerId is the user of Von, if it is another one than BenutzerId
+		VonBenutzerId string `json:"vonbenutzerid,omitempty"`
+		// Ausgefuehrt is false for a preview
+		Ausgefuehrt bool `json:"ausgefuehrt"`
+	}
+
+	// ZusammenfuehrungKonflikt is a Filmkritiken rated by both members
+	ZusammenfuehrungKonflikt struct {
+		FilmkritikenId string     `json:"filmkritikenid"`
+		Titel          string     `json:"titel"`
+		BewertungVon   *Bewertung `json:"bewertungvon"`
+		BewertungNach  *Bewertung `json:"bewertungnach"`
+	}
+
+	// AuditEintrag records who changed data outside of the regular workflows
+	AuditEintrag struct {
+		Id        string    `json:"id" bson:"_id"`
+		Zeitpunkt time.Time `json:"zeitpunkt"`
+		Benutzer  string    `json:"benutzer"`
+		Aktion    string    `json:"aktion"`
+		Details   any       `json:"details"`
+	}
+
+	GenreStatistik struct {
+		Genre             string  `json:"genre"`
+		AnzahlBewertungen int     `json:"anzahlbewertungen"`
+		Durchschnitt      float64 `json:"durchschnitt"`
 	}
 )
@@ -0,0 +1,89 @@
+package filmkritiken
+
+import (
+	"sort"
+	"time"
+)
+
+// CompareVorfuehrungen returns all screenings of the film, oldest first (unscheduled last), with the
+// average of each screening and how the rating of every member changed from screening to screening.
+// Members are grouped by user id, named after their latest Bewertung.
+func CompareVorfuehrungen(film *Film, screenings []*Filmkritiken) *FilmUebersicht {
+	sorted := make([]*Filmkritiken, len(screenings))
+	copy(sorted, screenings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := besprochenAm(sorted[i]), besprochenAm(sorted[j])
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+
+	uebersicht := &FilmUebersicht{
+		Film:          film,
+		Vorfuehrungen: make([]*Vorfuehrung, 0, len(sorted)),
+		Mitglieder:    make([]*MitgliedVerlauf, 0),
+	}
+	verlaeufe := make(map[string]*MitgliedVerlauf)
+
+	for _, fk := range sorted {
+		vorfuehrung := &Vorfuehrung{FilmkritikenId: fk.Id}
+		if fk.Details != nil {
+			vorfuehrung.BeitragVon = fk.Details.BeitragVon
+			vorfuehrung.BesprochenAm = fk.Details.BesprochenAm
+		}
+
+		summe := &wertungSumme{}
+		for _, bewertung := range fk.Bewertungen {
+			if !bewertung.Enthaltung {
+				summe.anzahl++
+				summe.summe += bewertung.Wertung
+			}
+
+			verlauf := verlaeufe[mitglied(bewertung)]
+			if verlauf == nil {
+				verlauf = &MitgliedVerlauf{VonId: bewertung.VonId, Wertungen: make([]*VerlaufWertung, 0, 1)}
+				verlaeufe[mitglied(bewertung)] = verlauf
+				uebersicht.Mitglieder = append(uebersicht.Mitglieder, verlauf)
+			}
+			verlauf.Name = bewertung.Von
+			verlauf.Wertungen = append(verlauf.Wertungen, &VerlaufWertung{
+				FilmkritikenId: fk.Id,
+				BesprochenAm:   vorfuehrung.BesprochenAm,
+				Wertung:        bewertung.Wertung,
+				Enthaltung:     bewertung.Enthaltung,
+			})
+		}
+		vorfuehrung.AnzahlBewertungen = summe.anzahl
+		vorfuehrung.Durchschnitt = summe.durchschnitt()
+
+		uebersicht.Vorfuehrungen = append(uebersicht.Vorfuehrungen, vorfuehrung)
+	}
+
+	for _, verlauf := range uebersicht.Mitglieder {
+		wertungen := make([]int, 0, len(verlauf.Wertungen))
+		for _, wertung := range verlauf.Wertungen {
+			if !wertung.Enthaltung {
+				wertungen = append(wertungen, wertung.Wertung)
+			}
+		}
+		if len(wertungen) > 1 {
+			verlauf.Veraenderung = wertungen[len(wertungen)-1] - wertungen[0]
+		}
+	}
+	sort.Slice(uebersicht.Mitglieder, func(i, j int) bool {
+		if uebersicht.Mitglieder[i].Name != uebersicht.Mitglieder[j].Name {
+			return uebersicht.Mitglieder[i].Name < uebersicht.Mitglieder[j].Name
+		}
+		return uebersicht.Mitglieder[i].VonId < uebersicht.Mitglieder[j].VonId
+	})
+
+	return uebersicht
+}
+
+func besprochenAm(fk *Filmkritiken) *time.Time {
+	if fk.Details == nil {
+		return nil
+	}
+	return fk.Details.BesprochenAm
+}
@@ -0,0 +1,175 @@
+package session
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const maxRoleNameLength = 50
+
+// KnownPermissions lists every permission checked by an endpoint, local roles may only grant these.
+var KnownPermissions = []string{
+	"film.add",
+	"bewertung.add",
+	"bewertung.openclose",
+	"bewertung.moderieren",
+	"mitglieder.verwalten",
+	"rollen.verwalten",
+}
+
+//go:generate mockgen -source=PermissionService.go -destination=../../mocks/PermissionService.go -package mocks
+type PermissionService interface {
+	// ResolvePermissions returns the current permissions of the user, based on the roles of the identity
+	// provider (idpRoles) and the locally assigned roles
+	ResolvePermissions(ctx context.Context, userID string, idpRoles []string) ([]string, error)
+	GetRoles(ctx context.Context) ([]*Role, error)
+	SaveRole(ctx context.Context, name string, permissions []string) (*Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	GetUsers(ctx context.Context) ([]*User, error)
+	SetUserRoles(ctx context.Context, userID string, roles []string) (*User, error)
+}
+
+type permissionServiceImpl struct {
+	userRepository UserRepository
+	roleRepository RoleRepository
+}
+
+func NewPermissionService(userRepository UserRepository, roleRepository RoleRepository) PermissionService {
+	return &permissionServiceImpl{
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+	}
+}
+
+// ResolvePermissions maps roles to the permissions of their local definition. Roles of the identity provider
+// without local definition are permissions themselves (as the app roles in Entra are named like permissions).
+func ResolvePermissions(idpRoles []string, localRoles []string, definitions []*Role) []string {
+	permissionsByRole := make(map[string][]string, len(definitions))
+	for _, role := range definitions {
+		permissionsByRole[role.Name] = role.Permissions
+	}
+
+	permissions := make([]string, 0)
+	for _, role := range idpRoles {
+		if rolePermissions, exists := permissionsByRole[role]; exists {
+			permissions = append(permissions, rolePermissions...)
+		} else {
+			permissions = append(permissions, role)
+		}
+	}
+	for _, role := range localRoles {
+		permissions = append(permissions, permissionsByRole[role]...)
+	}
+
+	sort.Strings(permissions)
+	return slices.Compact(permissions)
+}
+
+func (s *permissionServiceImpl) ResolvePermissions(ctx context.Context, userID string, idpRoles []string) ([]string, error) {
+	var localRoles []string
+	user, err := s.userRepository.FindUser(ctx, userID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return nil, err
+		}
+	} else {
+		localRoles = user.Roles
+	}
+
+	definitions, err := s.roleRepository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolvePermissions(idpRoles, localRoles, definitions), nil
+}
+
+func (s *permissionServiceImpl) GetRoles(ctx context.Context) ([]*Role, error) {
+	return s.roleRepository.GetRoles(ctx)
+}
+
+func (s *permissionServiceImpl) SaveRole(ctx context.Context, name string, permissions []string) (*Role, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxRoleNameLength {
+		return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Der Name der Rolle muss zwischen 1 und %d Zeichen lang sein.", maxRoleNameLength))
+	}
+
+	rolePermissions := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !slices.Contains(KnownPermissions, permission) {
+			return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Unbekannte Berechtigung: %s", permission))
+		}
+		if !slices.Contains(rolePermissions, permission) {
+			rolePermissions = append(rolePermissions, permission)
+		}
+	}
+
+	role := &Role{Name: name, Permissions: rolePermissions}
+	if err := s.roleRepository.SaveRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole removes the role and its assignments to users.
+func (s *permissionServiceImpl) DeleteRole(ctx context.Context, name string) error {
+	users, err := s.userRepository.GetUsers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if !slices.Contains(user.Roles, name) {
+			continue
+		}
+		user.Roles = slices.DeleteFunc(user.Roles, func(role string) bool { return role == name })
+		if err := s.userRepository.SaveUser(ctx, user); err != nil {
+			return err
+		}
+	}
+
+	return s.roleRepository.DeleteRole(ctx, name)
+}
+
+func (s *permissionServiceImpl) GetUsers(ctx context.Context) ([]*User, error) {
+	users, err := s.userRepository.GetUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(users, func(i, j int) bool {
+		return strings.ToLower(users[i].Name) < strings.ToLower(users[j].Name)
+	})
+	return users, nil
+}
+
+func (s *permissionServiceImpl) SetUserRoles(ctx context.Context, userID string, roles []string) (*User, error) {
+	user, err := s.userRepository.FindUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	definitions, err := s.roleRepository.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	userRoles := make([]string, 0, len(roles))
+	for _, role := range roles {
+		known := slices.ContainsFunc(definitions, func(definition *Role) bool { return definition.Name == role })
+		if !known {
+			return nil, errors.NewInvalidInputErrorFromString(fmt.Sprintf("Unbekannte Rolle: %s", role))
+		}
+		if !slices.Contains(userRoles, role) {
+			userRoles = append(userRoles, role)
+		}
+	}
+
+	user.Roles = userRoles
+	if err := s.userRepository.SaveUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,58 @@
+package session_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestResolvePermissions(t *testing.T) {
+	definitions := []*session.Role{
+		{Name: "moderator", Permissions: []string{"bewertung.openclose", "film.add"}},
+		{Name: "filmtreff", Permissions: []string{"bewertung.add"}},
+	}
+
+	t.Run("idp roles without definition are permissions", func(t *testing.T) {
+		permissions := session.ResolvePermissions([]string{"film.add", "filmtreff"}, nil, definitions)
+		if !slices.Equal(permissions, []string{"bewertung.add", "film.add"}) {
+			t.Errorf("unexpected permissions %v", permissions)
+		}
+	})
+
+	t.Run("local roles are merged, unknown local roles grant nothing", func(t *testing.T) {
+		permissions := session.ResolvePermissions([]string{"bewertung.add"}, []string{"moderator", "deleted"}, definitions)
+		if !slices.Equal(permissions, []string{"bewertung.add", "bewertung.openclose", "film.add"}) {
+			t.Errorf("unexpected permissions %v", permissions)
+		}
+	})
+}
+
+func TestPermissionService_SetUserRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	userRepo := mocks.NewMockUserRepository(ctrl)
+	roleRepo := mocks.NewMockRoleRepository(ctrl)
+	service := session.NewPermissionService(userRepo, roleRepo)
+	ctx := context.Background()
+
+	userRepo.EXPECT().FindUser(ctx, "oid-stefan").Return(&session.User{ID: "oid-stefan"}, nil).Times(2)
+	roleRepo.EXPECT().GetRoles(ctx).Return([]*session.Role{{Name: "moderator"}}, nil).Times(2)
+	userRepo.EXPECT().SaveUser(ctx, gomock.Any()).Return(nil)
+
+	user, err := service.SetUserRoles(ctx, "oid-stefan", []string{"moderator", "moderator"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(user.Roles, []string{"moderator"}) {
+		t.Errorf("unexpected roles %v", user.Roles)
+	}
+
+	_, err = service.SetUserRoles(ctx, "oid-stefan", []string{"admin"})
+	if _, ok := err.(*errors.InvalidInputError); !ok {
+		t.Errorf("expected InvalidInputError for unknown role, got %v", err)
+	}
+}
@@ -11,4 +11,35 @@ type SessionRepository interface {
 	FindSession(ctx context.Context, sessionID string) (*Session, error)
 	DeleteSession(ctx context.Context, sessionID string) error
 	RefreshSession(ctx context.Context, sessionID string, duration time.Duration) error
+	// UpdateRefreshedSession stores identity and refresh token of the session, if its refresh token is still
+	// previousRefreshToken. It reports false if the session was refreshed by someone else or was deleted.
+	UpdateRefreshedSession(ctx context.Context, session *Session, previousRefreshToken string) (bool, error)
+	UpdateSessionLastSeen(ctx context.Context, sessionID string, lastSeenAt time.Time, ip string) error
+	// GetSessions returns the sessions of a user, with the hash of the session id as ID
+	GetSessions(ctx context.Context, userID string) ([]*Session, error)
+	// DeleteSessionByHash deletes a session of the user by the hash of its id
+	DeleteSessionByHash(ctx context.Context, userID string, hashedID string) error
+	DeleteSessions(ctx context.Context, userID string) (int64, error)
+}
+
+type UserRepository interface {
+	FindUser(ctx context.Context, userID string) (*User, error)
+	GetUsers(ctx context.Context) ([]*User, error)
+	SaveUser(ctx context.Context, user *User) error
+}
+
+type RoleRepository interface {
+	GetRoles(ctx context.Context) ([]*Role, error)
+	SaveRole(ctx context.Context, role *Role) error
+	DeleteRole(ctx context.Context, name string) error
+}
+
+type TokenRepository interface {
+	SaveToken(ctx context.Context, token *Token) error
+	// FindTokenByHash returns the Token with the hash of its secret
+	FindTokenByHash(ctx context.Context, hash string) (*Token, error)
+	GetTokens(ctx context.Context, userID string) ([]*Token, error)
+	DeleteToken(ctx context.Context, userID string, tokenID string) error
+	DeleteTokens(ctx context.Context, userID string) (int64, error)
+	UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt time.Time) error
 }
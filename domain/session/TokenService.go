@@ -0,0 +1,146 @@
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/google/uuid"
+)
+
+const (
+	// TokenPrefix makes tokens recognizable, e.g. for secret scanners
+	TokenPrefix          = "fkt_"
+	tokenSecretBytes     = 32
+	maxTokenNameLength   = 50
+	maxTokensPerUser     = 20
+	defaultTokenDays     = 90
+	maxTokenDays         = 365
+	tokenLastUsedMinimum = time.Minute
+)
+
+//go:generate mockgen -source=TokenService.go -destination=../../mocks/TokenService.go -package mocks
+type TokenService interface {
+	// CreateToken creates a Token for the user of the session and returns it with its secret, which is not
+	// stored and can't be shown again. The scopes must be part of the permissions of the session.
+	CreateToken(ctx context.Context, sess *Session, permissions []string, name string, scopes []string, validDays int) (*Token, string, error)
+	GetTokens(ctx context.Context, userID string) ([]*Token, error)
+	RevokeToken(ctx context.Context, userID string, tokenID string) error
+	// RevokeTokens deletes all tokens of the user, when all of its sessions end
+	RevokeTokens(ctx context.Context, userID string) (int64, error)
+	// Authenticate returns the valid Token of the secret and its User, whose IdpRoles are those of the last
+	// login or refresh
+	Authenticate(ctx context.Context, secret string) (*Token, *User, error)
+}
+
+type tokenServiceImpl struct {
+	tokenRepository TokenRepository
+	userRepository  UserRepository
+}
+
+func NewTokenService(tokenRepository TokenRepository, userRepository UserRepository) TokenService {
+	return &tokenServiceImpl{
+		tokenRepository: tokenRepository,
+		userRepository:  userRepository,
+	}
+}
+
+func (s *tokenServiceImpl) CreateToken(ctx context.Context, sess *Session, permissions []string, name string, scopes []string, validDays int) (*Token, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > maxTokenNameLength {
+		return nil, "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Der Name des Tokens muss zwischen 1 und %d Zeichen lang sein.", maxTokenNameLength))
+	}
+	if validDays == 0 {
+		validDays = defaultTokenDays
+	}
+	if validDays < 1 || validDays > maxTokenDays {
+		return nil, "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Ein Token ist höchstens %d Tage gültig.", maxTokenDays))
+	}
+	if len(scopes) == 0 {
+		return nil, "", errors.NewInvalidInputErrorFromString("Mindestens eine Berechtigung muss angegeben werden.")
+	}
+	tokenScopes := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(permissions, scope) {
+			return nil, "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Die Berechtigung %s fehlt.", scope))
+		}
+		if !slices.Contains(tokenScopes, scope) {
+			tokenScopes = append(tokenScopes, scope)
+		}
+	}
+
+	existing, err := s.tokenRepository.GetTokens(ctx, sess.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(existing) >= maxTokensPerUser {
+		return nil, "", errors.NewInvalidInputErrorFromString(fmt.Sprintf("Es sind höchstens %d Tokens möglich.", maxTokensPerUser))
+	}
+
+	secretBytes := make([]byte, tokenSecretBytes)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return nil, "", err
+	}
+	secret := TokenPrefix + hex.EncodeToString(secretBytes)
+
+	now := time.Now()
+	token := &Token{
+		ID:        uuid.NewString(),
+		Hash:      HashSessionID(secret),
+		UserID:    sess.UserID,
+		Name:      name,
+		Scopes:    tokenScopes,
+		CreatedAt: now,
+		ExpiresAt: now.AddDate(0, 0, validDays),
+	}
+	if err := s.tokenRepository.SaveToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+	return token, secret, nil
+}
+
+func (s *tokenServiceImpl) GetTokens(ctx context.Context, userID string) ([]*Token, error) {
+	return s.tokenRepository.GetTokens(ctx, userID)
+}
+
+func (s *tokenServiceImpl) RevokeToken(ctx context.Context, userID string, tokenID string) error {
+	return s.tokenRepository.DeleteToken(ctx, userID, tokenID)
+}
+
+func (s *tokenServiceImpl) RevokeTokens(ctx context.Context, userID string) (int64, error) {
+	return s.tokenRepository.DeleteTokens(ctx, userID)
+}
+
+func (s *tokenServiceImpl) Authenticate(ctx context.Context, secret string) (*Token, *User, error) {
+	if !strings.HasPrefix(secret, TokenPrefix) {
+		return nil, nil, errors.NewNotFoundErrorFromString("Token nicht gefunden.")
+	}
+
+	token, err := s.tokenRepository.FindTokenByHash(ctx, HashSessionID(secret))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if token.ExpiresAt.Before(now) {
+		return nil, nil, errors.NewNotFoundErrorFromString("Token ist abgelaufen.")
+	}
+
+	user, err := s.userRepository.FindUser(ctx, token.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// scripts may call often, the time of the last use does not need to be exact
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > tokenLastUsedMinimum {
+		if err := s.tokenRepository.UpdateTokenLastUsed(ctx, token.ID, now); err != nil {
+			return nil, nil, err
+		}
+		token.LastUsedAt = &now
+	}
+	return token, user, nil
+}
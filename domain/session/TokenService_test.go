@@ -0,0 +1,84 @@
+package session_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/golang/mock/gomock"
+)
+
+func TestTokenService_CreateToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tokenRepo := mocks.NewMockTokenRepository(ctrl)
+	userRepo := mocks.NewMockUserRepository(ctrl)
+	service := session.NewTokenService(tokenRepo, userRepo)
+	ctx := context.Background()
+	sess := &session.Session{UserID: "oid-stefan", Roles: []string{"filmtreff"}}
+
+	t.Run("stores only the hash of the secret", func(t *testing.T) {
+		var saved *session.Token
+		tokenRepo.EXPECT().GetTokens(ctx, "oid-stefan").Return(nil, nil)
+		tokenRepo.EXPECT().SaveToken(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, token *session.Token) error {
+			saved = token
+			return nil
+		})
+
+		token, secret, err := service.CreateToken(ctx, sess, []string{"film.add", "bewertung.add"}, " Bot ", []string{"bewertung.add"}, 0)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.HasPrefix(secret, session.TokenPrefix) || saved.Hash != session.HashSessionID(secret) || saved.Hash == secret {
+			t.Errorf("expected the hash of the secret to be stored, got %q for %q", saved.Hash, secret)
+		}
+		if token.Name != "Bot" || len(token.Scopes) != 1 || token.Scopes[0] != "bewertung.add" {
+			t.Errorf("unexpected token %+v", token)
+		}
+		if days := token.ExpiresAt.Sub(token.CreatedAt).Hours() / 24; days < 89 || days > 91 {
+			t.Errorf("expected 90 days validity, got %v", days)
+		}
+	})
+
+	t.Run("scopes must be permissions of the user", func(t *testing.T) {
+		_, _, err := service.CreateToken(ctx, sess, []string{"bewertung.add"}, "Bot", []string{"film.add"}, 30)
+		if _, ok := err.(*errors.InvalidInputError); !ok {
+			t.Errorf("expected InvalidInputError, got %v", err)
+		}
+	})
+}
+
+func TestTokenService_Authenticate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	tokenRepo := mocks.NewMockTokenRepository(ctrl)
+	userRepo := mocks.NewMockUserRepository(ctrl)
+	service := session.NewTokenService(tokenRepo, userRepo)
+	ctx := context.Background()
+	secret := session.TokenPrefix + "abc"
+
+	t.Run("valid token updates last used", func(t *testing.T) {
+		tokenRepo.EXPECT().FindTokenByHash(ctx, session.HashSessionID(secret)).Return(&session.Token{ID: "t1", UserID: "oid-stefan", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		userRepo.EXPECT().FindUser(ctx, "oid-stefan").Return(&session.User{ID: "oid-stefan", Name: "Stefan"}, nil)
+		tokenRepo.EXPECT().UpdateTokenLastUsed(ctx, "t1", gomock.Any()).Return(nil)
+
+		token, user, err := service.Authenticate(ctx, secret)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if user.Name != "Stefan" || token.LastUsedAt == nil {
+			t.Errorf("unexpected result %+v %+v", token, user)
+		}
+	})
+
+	t.Run("expired token is rejected", func(t *testing.T) {
+		tokenRepo.EXPECT().FindTokenByHash(ctx, gomock.Any()).Return(&session.Token{ID: "t1", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+
+		_, _, err := service.Authenticate(ctx, secret)
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+	})
+}
@@ -3,15 +3,66 @@ package session
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"slices"
 	"time"
 )
 
+// Session of a browser. Its ID is the secret of the session cookie, only its hash is stored, so sessions
+// loaded with GetSessions have the hash as ID.
 type Session struct {
-	ID          string    `json:"id" bson:"_id"`
-	Name        string    `json:"name" bson:"name"`
-	Permissions []string  `json:"permissions" bson:"permissions"`
-	ExpiresAt   time.Time `json:"expiresAt" bson:"expiresAt"`
+	ID string `json:"id" bson:"_id"`
+	// UserID is the stable id of the User (oid or sub claim), Name only its display name at login
+	UserID string `json:"userId" bson:"userId"`
+	// Provider is the name of the login provider, e.g. entra
+	Provider    string   `json:"provider" bson:"provider"`
+	Name        string   `json:"name" bson:"name"`
+	Permissions []string `json:"permissions" bson:"permissions"`
+	// Roles of the identity provider at login, the Permissions are resolved from them and the local roles
+	Roles     []string  `json:"roles" bson:"roles"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	// UserAgent and IP of the login, so members can tell their sessions apart
+	UserAgent  string    `json:"userAgent" bson:"userAgent"`
+	IP         string    `json:"ip" bson:"ip"`
+	LastSeenAt time.Time `json:"lastSeenAt" bson:"lastSeenAt"`
+	// RefreshToken of the identity provider, encrypted, to update Roles and Name since RefreshedAt
+	RefreshToken string    `json:"-" bson:"refreshToken"`
+	RefreshedAt  time.Time `json:"-" bson:"refreshedAt"`
+}
+
+// User is a member, identified by the object id (oid) or subject (sub) of the identity provider, as the
+// display name can change.
+type User struct {
+	ID   string `json:"id" bson:"_id"`
+	Name string `json:"name" bson:"name"`
+	// Names contains every display name the User had, to assign ratings stored before user ids existed
+	Names []string `json:"names" bson:"names"`
+	// Roles assigned locally, in addition to the roles of the identity provider
+	Roles []string `json:"roles" bson:"roles"`
+	// IdpRoles are the roles of the identity provider at the last login or refresh, personal tokens are
+	// authorized with them
+	IdpRoles    []string  `json:"idpRoles" bson:"idpRoles"`
 	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	LastLoginAt time.Time `json:"lastLoginAt" bson:"lastLoginAt"`
+}
+
+// Role grants Permissions (like film.add) to all users with the role.
+type Role struct {
+	Name        string   `json:"name" bson:"_id"`
+	Permissions []string `json:"permissions" bson:"permissions"`
+}
+
+// Token is a personal access token for scripts, only its hash is stored. Its Scopes restrict the current
+// permissions of the user.
+type Token struct {
+	ID         string     `json:"id" bson:"_id"`
+	Hash       string     `json:"-" bson:"hash"`
+	UserID     string     `json:"userId" bson:"userId"`
+	Name       string     `json:"name" bson:"name"`
+	Scopes     []string   `json:"scopes" bson:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt" bson:"createdAt"`
+	ExpiresAt  time.Time  `json:"expiresAt" bson:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt" bson:"lastUsedAt"`
 }
 
 func HashSessionID(sessionID string) string {
@@ -21,3 +72,23 @@ func HashSessionID(sessionID string) string {
 	hash := sha256.Sum256([]byte(sessionID))
 	return hex.EncodeToString(hash[:])
 }
+
+// Login records a login with the current display name and reports whether the name changed.
+func (u *User) Login(name string, now time.Time) bool {
+	renamed := u.Rename(name)
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = now
+	}
+	u.LastLoginAt = now
+	return renamed
+}
+
+// Rename sets the current display name and reports whether it changed.
+func (u *User) Rename(name string) bool {
+	renamed := u.Name != "" && u.Name != name
+	u.Name = name
+	if !slices.Contains(u.Names, name) {
+		u.Names = append(u.Names, name)
+	}
+	return renamed
+}
@@ -4,6 +4,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"testing"
+	"time"
 
 	"github.com/DerBlum/filmkritiken-backend/domain/session"
 )
@@ -26,3 +27,27 @@ func TestHashSessionID(t *testing.T) {
 		}
 	})
 }
+
+func TestUser_Login(t *testing.T) {
+	now := time.Date(2024, 10, 18, 20, 0, 0, 0, time.UTC)
+
+	t.Run("first login is not a rename", func(t *testing.T) {
+		user := &session.User{ID: "oid-1"}
+		if renamed := user.Login("Alice", now); renamed {
+			t.Error("expected first login not to be a rename")
+		}
+		if user.Name != "Alice" || len(user.Names) != 1 || !user.CreatedAt.Equal(now) || !user.LastLoginAt.Equal(now) {
+			t.Errorf("unexpected user %+v", user)
+		}
+	})
+
+	t.Run("changed display name is remembered", func(t *testing.T) {
+		user := &session.User{ID: "oid-1", Name: "Alice", Names: []string{"Alice"}, CreatedAt: now}
+		if renamed := user.Login("Alice Smith", now.Add(time.Hour)); !renamed {
+			t.Error("expected login with another name to be a rename")
+		}
+		if user.Name != "Alice Smith" || len(user.Names) != 2 || !user.CreatedAt.Equal(now) {
+			t.Errorf("unexpected user %+v", user)
+		}
+	})
+}
@@ -1,15 +1,19 @@
 package inbound
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
 	"github.com/DerBlum/filmkritiken-backend/domain/session"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/oauth2"
@@ -20,6 +24,8 @@ const (
 	StateCookieName      = "oauth_state"
 	VerifierCookieName   = "oauth_verifier"
 	RedirectCookieName   = "oauth_redirect"
+	NonceCookieName      = "oauth_nonce"
+	ProviderCookieName   = "oauth_provider"
 	SecondsPerDay        = 86400
 	OAuthStateTTLSeconds = 600
 	StateTokenBytes      = 16
@@ -32,41 +38,102 @@ type AuthConfig struct {
 	EntraRedirectURI    string `env:"ENTRA_REDIRECT_URI"`
 	FrontendURL         string `env:"FRONTEND_URL" envDefault:"http://localhost:5173"`
 	SessionDurationDays int    `env:"SESSION_DURATION_DAYS" envDefault:"7"`
+	// SessionEncryptionKey (base64, 32 bytes) encrypts the refresh tokens, without it sessions are not refreshed
+	SessionEncryptionKey string `env:"SESSION_ENCRYPTION_KEY,unset"`
+	// SessionRefreshInterval is the minimum time between two refreshes of roles and name at the identity provider
+	SessionRefreshInterval time.Duration `env:"SESSION_REFRESH_INTERVAL" envDefault:"15m"`
+	// DevLogin enables a login without identity provider for local development, never on HTTPS
+	DevLogin bool `env:"DEV_LOGIN" envDefault:"false"`
+	// OidcProviders lists additional OpenID Connect providers, configured by OIDC_<NAME>_* variables
+	OidcProviders []string `env:"OIDC_PROVIDERS"`
+	// Providers is filled by LoadOidcProviderConfigs
+	Providers map[string]*OidcProviderConfig
+}
+
+type CreateTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ValidDays defaults to 90 days
+	ValidDays int `json:"validDays"`
 }
 
 type BffAuthHandler struct {
-	config      *AuthConfig
-	sessionRepo session.SessionRepository
-	oauthConfig *oauth2.Config
+	config              *AuthConfig
+	sessionRepo         session.SessionRepository
+	userRepo            session.UserRepository
+	permissionService   session.PermissionService
+	tokenService        session.TokenService
+	filmkritikenService filmkritiken.FilmkritikenService
+	providers           map[string]*oidcProvider
+	// defaultProvider is used if the login does not select a provider
+	defaultProvider string
+	devLogin        bool
+	// refreshTokenCipher is nil if no SessionEncryptionKey is configured
+	refreshTokenCipher *tokenCipher
+	refreshLocks       *sessionLocks
 }
 
-func NewBffAuthHandler(config *AuthConfig, sessionRepo session.SessionRepository) *BffAuthHandler {
-	clientSecret := config.EntraClientSecret
-	if len(clientSecret) > 0 && (clientSecret[0] == '<' || clientSecret == "unset") {
-		clientSecret = ""
+func NewBffAuthHandler(config *AuthConfig, sessionRepo session.SessionRepository, userRepo session.UserRepository, permissionService session.PermissionService, tokenService session.TokenService, filmkritikenService filmkritiken.FilmkritikenService) *BffAuthHandler {
+	providers := make(map[string]*oidcProvider)
+	defaultProvider := ""
+	if config.EntraTenantID != "" {
+		providers[EntraProviderName] = newOidcProvider(EntraProviderName, entraProviderConfig(config), nil)
+		defaultProvider = EntraProviderName
+	}
+	for _, name := range config.OidcProviders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if providerConfig, ok := config.Providers[name]; ok {
+			providers[name] = newOidcProvider(name, providerConfig, nil)
+			if defaultProvider == "" {
+				defaultProvider = name
+			}
+		}
 	}
 
-	oauthConfig := &oauth2.Config{
-		ClientID:     config.EntraClientID,
-		ClientSecret: clientSecret,
-		RedirectURL:  config.EntraRedirectURI,
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  "https://login.microsoftonline.com/" + config.EntraTenantID + "/oauth2/v2.0/authorize",
-			TokenURL: "https://login.microsoftonline.com/" + config.EntraTenantID + "/oauth2/v2.0/token",
-		},
-		Scopes: []string{"openid", "profile", "offline_access"},
+	devLogin := config.DevLogin
+	if devLogin && strings.HasPrefix(strings.ToLower(config.FrontendURL), "https://") {
+		log.Errorf("DEV_LOGIN is ignored for the HTTPS frontend %s", config.FrontendURL)
+		devLogin = false
+	}
+	if devLogin {
+		log.Warn("DEV_LOGIN is enabled, anybody can log in with any permission")
+		if defaultProvider == "" {
+			defaultProvider = DevProviderName
+		}
+	}
+
+	var refreshTokenCipher *tokenCipher
+	if config.SessionEncryptionKey != "" {
+		var err error
+		refreshTokenCipher, err = newTokenCipher(config.SessionEncryptionKey)
+		if err != nil {
+			log.Errorf("SESSION_ENCRYPTION_KEY is invalid, sessions are not refreshed: %v", err)
+		}
+	} else {
+		log.Warn("SESSION_ENCRYPTION_KEY is not set, roles of the identity provider are only updated at login")
 	}
 
 	return &BffAuthHandler{
-		config:      config,
-		sessionRepo: sessionRepo,
-		oauthConfig: oauthConfig,
+		config:              config,
+		sessionRepo:         sessionRepo,
+		userRepo:            userRepo,
+		permissionService:   permissionService,
+		tokenService:        tokenService,
+		filmkritikenService: filmkritikenService,
+		providers:           providers,
+		defaultProvider:     defaultProvider,
+		devLogin:            devLogin,
+		refreshTokenCipher:  refreshTokenCipher,
+		refreshLocks:        newSessionLocks(),
 	}
 }
 
+func isHTTPSRequest(c *gin.Context) bool {
+	return c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+}
+
 func (h *BffAuthHandler) setCookie(c *gin.Context, name, value string, maxAge int) {
-	isHTTPS := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
-	if isHTTPS {
+	if isHTTPSRequest(c) {
 		c.SetSameSite(http.SameSiteNoneMode)
 		c.SetCookie(name, value, maxAge, "/", "", true, true)
 	} else {
@@ -75,15 +142,68 @@ func (h *BffAuthHandler) setCookie(c *gin.Context, name, value string, maxAge in
 	}
 }
 
+func (h *BffAuthHandler) handleProviders(c *gin.Context) {
+	providers := make([]gin.H, 0, len(h.providers))
+	for name, provider := range h.providers {
+		providers = append(providers, gin.H{
+			"name":        name,
+			"displayName": provider.displayName(),
+			"default":     name == h.defaultProvider,
+		})
+	}
+	if h.devLoginAllowed(c) {
+		providers = append(providers, gin.H{
+			"name":        DevProviderName,
+			"displayName": "Entwicklung",
+			"default":     DevProviderName == h.defaultProvider,
+		})
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i]["name"].(string) < providers[j]["name"].(string)
+	})
+	c.JSON(http.StatusOK, providers)
+}
+
 func (h *BffAuthHandler) handleLogin(c *gin.Context) {
+	providerName := c.Query("provider")
+	if providerName == "" {
+		providerName = h.defaultProvider
+	}
+	if providerName == DevProviderName && h.devLoginAllowed(c) {
+		redirectPath := c.Query("redirect")
+		if redirectPath == "" {
+			redirectPath = c.Query("returnUrl")
+		}
+		c.Redirect(http.StatusFound, "/auth/dev-login?redirect="+url.QueryEscape(redirectPath))
+		return
+	}
+	provider, ok := h.providers[providerName]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown login provider"})
+		return
+	}
+
+	oauthConfig, _, err := provider.discover(c.Request.Context())
+	if err != nil {
+		log.Errorf("OpenID discovery of %s failed: %v", providerName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider not available"})
+		return
+	}
+
 	b := make([]byte, StateTokenBytes)
 	_, _ = rand.Read(b)
 	state := hex.EncodeToString(b)
 
 	verifier := oauth2.GenerateVerifier()
 
+	nonceBytes := make([]byte, StateTokenBytes)
+	_, _ = rand.Read(nonceBytes)
+	nonce := hex.EncodeToString(nonceBytes)
+
 	h.setCookie(c, StateCookieName, state, OAuthStateTTLSeconds)
 	h.setCookie(c, VerifierCookieName, verifier, OAuthStateTTLSeconds)
+	h.setCookie(c, NonceCookieName, nonce, OAuthStateTTLSeconds)
+	h.setCookie(c, ProviderCookieName, providerName, OAuthStateTTLSeconds)
 
 	redirectPath := c.Query("redirect")
 	if redirectPath == "" {
@@ -93,7 +213,7 @@ func (h *BffAuthHandler) handleLogin(c *gin.Context) {
 		h.setCookie(c, RedirectCookieName, redirectPath, OAuthStateTTLSeconds)
 	}
 
-	authURL := h.oauthConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+	authURL := oauthConfig.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier), oauth2.SetAuthURLParam("nonce", nonce))
 	c.Redirect(http.StatusFound, authURL)
 }
 
@@ -101,7 +221,7 @@ func (h *BffAuthHandler) handleCallback(c *gin.Context) {
 	errorQuery := c.Query("error")
 	if errorQuery != "" {
 		errorDesc := c.Query("error_description")
-		log.Warnf("EntraID authorization error: %s - %s", errorQuery, errorDesc)
+		log.Warnf("OpenID authorization error: %s - %s", errorQuery, errorDesc)
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":             errorQuery,
 			"error_description": errorDesc,
@@ -120,24 +240,41 @@ func (h *BffAuthHandler) handleCallback(c *gin.Context) {
 	}
 
 	verifierCookie, _ := c.Cookie(VerifierCookieName)
+	nonceCookie, _ := c.Cookie(NonceCookieName)
+	providerCookie, _ := c.Cookie(ProviderCookieName)
 
 	h.setCookie(c, StateCookieName, "", -1)
 	h.setCookie(c, VerifierCookieName, "", -1)
+	h.setCookie(c, NonceCookieName, "", -1)
+	h.setCookie(c, ProviderCookieName, "", -1)
 
 	if codeQuery == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing authorization code"})
 		return
 	}
 
+	provider, ok := h.providers[providerCookie]
+	if !ok {
+		log.Warnf("callback for unknown login provider %q", providerCookie)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown login provider"})
+		return
+	}
+	oauthConfig, verifier, err := provider.discover(c.Request.Context())
+	if err != nil {
+		log.Errorf("OpenID discovery of %s failed: %v", provider.name, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Login provider not available"})
+		return
+	}
+
 	var opts []oauth2.AuthCodeOption
 	if verifierCookie != "" {
 		opts = append(opts, oauth2.VerifierOption(verifierCookie))
 	}
 
-	token, err := h.oauthConfig.Exchange(c.Request.Context(), codeQuery, opts...)
-	if err != nil && h.oauthConfig.ClientSecret != "" {
+	token, err := oauthConfig.Exchange(c.Request.Context(), codeQuery, opts...)
+	if err != nil && oauthConfig.ClientSecret != "" {
 		log.Warnf("Primary OAuth exchange failed (%v), retrying without client_secret for Public Client App Registration...", err)
-		fallbackConfig := *h.oauthConfig
+		fallbackConfig := *oauthConfig
 		fallbackConfig.ClientSecret = ""
 		token, err = fallbackConfig.Exchange(c.Request.Context(), codeQuery, opts...)
 	}
@@ -152,42 +289,70 @@ func (h *BffAuthHandler) handleCallback(c *gin.Context) {
 	}
 
 	idTokenRaw, _ := token.Extra("id_token").(string)
-	name := "Filmtreff-Mitglied"
-	var roles []string
-
-	if idTokenRaw != "" {
-		parser := jwt.NewParser()
-		parsedToken, _, err := parser.ParseUnverified(idTokenRaw, jwt.MapClaims{})
-		if err == nil {
-			if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok {
-				if n, ok := claims["name"].(string); ok && n != "" {
-					name = n
-				} else if u, ok := claims["preferred_username"].(string); ok && u != "" {
-					name = u
-				}
-
-				if rolesRaw, ok := claims["roles"].([]interface{}); ok {
-					for _, r := range rolesRaw {
-						if rStr, ok := r.(string); ok {
-							roles = append(roles, rStr)
-						}
-					}
-				}
-			}
-		}
+	if idTokenRaw == "" {
+		log.Error("OAuth token response contains no ID token")
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Missing ID token"})
+		return
 	}
 
-	if roles == nil {
-		roles = make([]string, 0)
+	claims, err := verifier.Verify(c.Request.Context(), idTokenRaw, nonceCookie)
+	if err != nil {
+		log.Warnf("ID token verification failed: %v", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid ID token"})
+		return
+	}
+
+	userID, name, roles := provider.userFromClaims(claims)
+	if userID == "" {
+		log.Errorf("ID token of %s from %s contains no user id claim", name, provider.name)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to identify user"})
+		return
+	}
+
+	redirectCookie, _ := c.Cookie(RedirectCookieName)
+	h.setCookie(c, RedirectCookieName, "", -1)
+
+	h.startSession(c, provider.name, userID, name, roles, token.RefreshToken, redirectCookie)
+}
+
+// startSession logs the user in with the roles of the login provider and redirects to the given path of the frontend.
+// The refresh token of the provider is kept to update roles and name later, if there is one.
+func (h *BffAuthHandler) startSession(c *gin.Context, providerName string, userID string, name string, roles []string, refreshToken string, redirectPath string) {
+	if err := h.saveUserIdentity(c.Request.Context(), userID, name, roles, true); err != nil {
+		log.Errorf("Failed to save user %s to DB: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save user"})
+		return
+	}
+
+	permissions, err := h.permissionService.ResolvePermissions(c.Request.Context(), userID, roles)
+	if err != nil {
+		log.Errorf("Failed to resolve permissions of %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve permissions"})
+		return
 	}
 
 	sessionDuration := time.Duration(h.config.SessionDurationDays) * 24 * time.Hour
 	newSession := &session.Session{
 		ID:          uuid.NewString(),
+		UserID:      userID,
+		Provider:    providerName,
 		Name:        name,
-		Permissions: roles,
+		Permissions: permissions,
+		Roles:       roles,
 		ExpiresAt:   time.Now().Add(sessionDuration),
 		CreatedAt:   time.Now(),
+		UserAgent:   userAgent(c),
+		IP:          c.ClientIP(),
+		LastSeenAt:  time.Now(),
+	}
+	if refreshToken != "" && h.refreshTokenCipher != nil {
+		encrypted, err := h.refreshTokenCipher.Encrypt(refreshToken, userID)
+		if err != nil {
+			log.Errorf("Failed to encrypt refresh token of %s: %v", userID, err)
+		} else {
+			newSession.RefreshToken = encrypted
+			newSession.RefreshedAt = newSession.CreatedAt
+		}
 	}
 
 	if err := h.sessionRepo.SaveSession(c.Request.Context(), newSession); err != nil {
@@ -198,19 +363,55 @@ func (h *BffAuthHandler) handleCallback(c *gin.Context) {
 
 	maxAge := h.config.SessionDurationDays * SecondsPerDay
 	h.setCookie(c, SessionCookieName, newSession.ID, maxAge)
-
-	redirectCookie, _ := c.Cookie(RedirectCookieName)
-	h.setCookie(c, RedirectCookieName, "", -1)
+	// a new session never reuses the CSRF token of a previous one
+	h.setCookie(c, CsrfCookieName, generateCsrfToken(), maxAge)
 
 	frontendURL := strings.TrimRight(h.config.FrontendURL, "/")
 	targetPath := "/"
-	if redirectCookie != "" && strings.HasPrefix(redirectCookie, "/") && !strings.HasPrefix(redirectCookie, "//") {
-		targetPath = redirectCookie
+	if redirectPath != "" && strings.HasPrefix(redirectPath, "/") && !strings.HasPrefix(redirectPath, "//") {
+		targetPath = redirectPath
 	}
 
 	c.Redirect(http.StatusFound, frontendURL+targetPath)
 }
 
+// saveUserIdentity stores the user with its current display name and roles of the identity provider, at a
+// login or a refresh of the session. If the name changed, the name is updated on all Bewertungen of the user,
+// so the history of a member stays in one piece.
+func (h *BffAuthHandler) saveUserIdentity(ctx context.Context, userID string, name string, idpRoles []string, login bool) error {
+	user, err := h.userRepo.FindUser(ctx, userID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			return err
+		}
+		user = &session.User{ID: userID}
+	}
+
+	previousName := user.Name
+	renamed := false
+	if login {
+		renamed = user.Login(name, time.Now())
+	} else if name != "" {
+		renamed = user.Rename(name)
+	}
+	if idpRoles == nil {
+		idpRoles = make([]string, 0)
+	}
+	// personal tokens are authorized with these, so removed roles apply to them as well
+	user.IdpRoles = idpRoles
+	if err := h.userRepo.SaveUser(ctx, user); err != nil {
+		return err
+	}
+
+	if renamed {
+		log.Infof("user %s renamed from %s to %s", userID, previousName, user.Name)
+		if err := h.filmkritikenService.RenameBenutzer(ctx, userID, user.Name); err != nil {
+			log.Errorf("could not rename bewertungen of user %s: %v", userID, err)
+		}
+	}
+	return nil
+}
+
 func (h *BffAuthHandler) handleMe(c *gin.Context) {
 	sessionID, err := c.Cookie(SessionCookieName)
 	if err != nil || sessionID == "" {
@@ -225,23 +426,164 @@ func (h *BffAuthHandler) handleMe(c *gin.Context) {
 		return
 	}
 
+	if !h.refreshIdentity(c.Request.Context(), sess) {
+		_ = h.sessionRepo.DeleteSession(c.Request.Context(), sessionID)
+		h.setCookie(c, SessionCookieName, "", -1)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked by login provider"})
+		return
+	}
+
 	sessionDuration := time.Duration(h.config.SessionDurationDays) * 24 * time.Hour
 	_ = h.sessionRepo.RefreshSession(c.Request.Context(), sessionID, sessionDuration)
+	touchSession(c, h.sessionRepo, sess)
 
 	maxAge := h.config.SessionDurationDays * SecondsPerDay
 	h.setCookie(c, SessionCookieName, sessionID, maxAge)
 
-	permissions := sess.Permissions
+	// local roles may have changed since the login
+	permissions, err := h.permissionService.ResolvePermissions(c.Request.Context(), sess.UserID, sessionIdpRoles(sess))
+	if err != nil {
+		log.Warnf("could not resolve permissions of %s, using those of the login: %v", sess.UserID, err)
+		permissions = sess.Permissions
+	}
 	if permissions == nil {
 		permissions = make([]string, 0)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
+		"id":          sess.UserID,
 		"name":        sess.Name,
 		"permissions": permissions,
+		"csrfToken":   h.csrfToken(c),
 	})
 }
 
+// refreshIdentity updates roles and name of the session with its refresh token, at most once per
+// SessionRefreshInterval. It returns false if the session ended: the provider refuses the refresh token, e.g.
+// because the user was disabled (the personal tokens of the user are revoked then), or the session was
+// deleted in the meantime. Errors of the provider keep the session as it is.
+func (h *BffAuthHandler) refreshIdentity(ctx context.Context, sess *session.Session) bool {
+	if h.refreshTokenCipher == nil || sess.RefreshToken == "" || time.Since(sess.RefreshedAt) < h.config.SessionRefreshInterval {
+		return true
+	}
+	provider, ok := h.providers[sess.Provider]
+	if !ok {
+		return true
+	}
+
+	// requests of one session (e.g. two tabs) refresh one after another, as a rotated refresh token can be
+	// used only once. The session is reloaded, the request before may have refreshed it already.
+	unlock := h.refreshLocks.lock(sess.ID)
+	defer unlock()
+	if !h.reloadSession(ctx, sess) {
+		return false
+	}
+	if time.Since(sess.RefreshedAt) < h.config.SessionRefreshInterval {
+		return true
+	}
+	previousRefreshToken := sess.RefreshToken
+
+	refreshToken, err := h.refreshTokenCipher.Decrypt(sess.RefreshToken, sess.UserID)
+	if err != nil {
+		// e.g. after a change of the key, the session continues with the roles of the login
+		log.Warnf("could not decrypt refresh token of %s: %v", sess.UserID, err)
+		return true
+	}
+	oauthConfig, verifier, err := provider.discover(ctx)
+	if err != nil {
+		log.Warnf("OpenID discovery of %s for refresh failed: %v", provider.name, err)
+		return true
+	}
+
+	token, err := oauthConfig.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		if retrieveErr, ok := err.(*oauth2.RetrieveError); ok && retrieveErr.ErrorCode == "invalid_grant" {
+			return h.refreshRefused(ctx, sess, previousRefreshToken, provider.name, err)
+		}
+		log.Warnf("refresh of session of %s failed: %v", sess.UserID, err)
+		return true
+	}
+
+	if idTokenRaw, _ := token.Extra("id_token").(string); idTokenRaw != "" {
+		claims, err := verifier.VerifyRefreshed(ctx, idTokenRaw)
+		if err != nil {
+			log.Warnf("refreshed ID token of %s is invalid: %v", sess.UserID, err)
+			return true
+		}
+		userID, name, roles := provider.userFromClaims(claims)
+		if userID != sess.UserID {
+			log.Errorf("refreshed ID token is for %s instead of %s, ending session", userID, sess.UserID)
+			return false
+		}
+		if roles == nil {
+			roles = make([]string, 0)
+		}
+		if err := h.saveUserIdentity(ctx, userID, name, roles, false); err != nil {
+			log.Warnf("could not update user %s: %v", userID, err)
+		} else if name != "" {
+			sess.Name = name
+		}
+		sess.Roles = roles
+	}
+
+	if token.RefreshToken != "" && token.RefreshToken != refreshToken {
+		// providers may rotate the refresh token on every use
+		if encrypted, err := h.refreshTokenCipher.Encrypt(token.RefreshToken, sess.UserID); err == nil {
+			sess.RefreshToken = encrypted
+		}
+	}
+	sess.RefreshedAt = time.Now()
+
+	permissions, err := h.permissionService.ResolvePermissions(ctx, sess.UserID, sess.Roles)
+	if err != nil {
+		log.Warnf("could not resolve permissions of %s after refresh: %v", sess.UserID, err)
+	} else {
+		sess.Permissions = permissions
+	}
+	stored, err := h.sessionRepo.UpdateRefreshedSession(ctx, sess, previousRefreshToken)
+	if err != nil {
+		log.Warnf("could not save refreshed session of %s: %v", sess.UserID, err)
+		return true
+	}
+	if !stored {
+		// refreshed by another instance of the backend, or deleted (logout, revoke) during the refresh
+		return h.reloadSession(ctx, sess)
+	}
+	return true
+}
+
+// refreshRefused handles a refresh token refused by the provider. Another instance of the backend may have
+// used the rotated refresh token first, only a refusal of the stored refresh token ends the session.
+func (h *BffAuthHandler) refreshRefused(ctx context.Context, sess *session.Session, previousRefreshToken string, providerName string, err error) bool {
+	if !h.reloadSession(ctx, sess) {
+		return false
+	}
+	if sess.RefreshToken != previousRefreshToken {
+		return true
+	}
+
+	log.Warnf("refresh token of %s was refused by %s, ending session: %v", sess.UserID, providerName, err)
+	// e.g. a disabled user must not keep access with its personal tokens
+	if _, err := h.tokenService.RevokeTokens(ctx, sess.UserID); err != nil {
+		log.Errorf("could not revoke tokens of %s: %v", sess.UserID, err)
+	}
+	return false
+}
+
+// reloadSession replaces sess with its stored state and returns false if the session no longer exists.
+func (h *BffAuthHandler) reloadSession(ctx context.Context, sess *session.Session) bool {
+	current, err := h.sessionRepo.FindSession(ctx, sess.ID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return false
+		}
+		log.Warnf("could not reload session of %s: %v", sess.UserID, err)
+		return true
+	}
+	*sess = *current
+	return true
+}
+
 func (h *BffAuthHandler) handleLogout(c *gin.Context) {
 	sessionID, _ := c.Cookie(SessionCookieName)
 	if sessionID != "" {
@@ -249,5 +591,101 @@ func (h *BffAuthHandler) handleLogout(c *gin.Context) {
 	}
 
 	h.setCookie(c, SessionCookieName, "", -1)
+	h.setCookie(c, CsrfCookieName, "", -1)
+	c.Status(http.StatusNoContent)
+}
+
+// browserSession returns the session of the session cookie. Tokens are managed with a browser session only,
+// so a leaked token can't be used to create new ones.
+func (h *BffAuthHandler) browserSession(c *gin.Context) (*session.Session, bool) {
+	sessionID, err := c.Cookie(SessionCookieName)
+	if err != nil || sessionID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthenticated"})
+		return nil, false
+	}
+
+	sess, err := h.sessionRepo.FindSession(c.Request.Context(), sessionID)
+	if err != nil || sess == nil || sess.UserID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session expired or invalid"})
+		return nil, false
+	}
+	return sess, true
+}
+
+func (h *BffAuthHandler) handleGetTokens(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.GetTokens(c.Request.Context(), sess.UserID)
+	if err != nil {
+		log.Errorf("Failed to load tokens of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tokens"})
+		return
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *BffAuthHandler) handleCreateToken(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	req := &CreateTokenRequest{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		return
+	}
+
+	permissions, err := h.permissionService.ResolvePermissions(c.Request.Context(), sess.UserID, sessionIdpRoles(sess))
+	if err != nil {
+		log.Errorf("Failed to resolve permissions of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve permissions"})
+		return
+	}
+
+	token, secret, err := h.tokenService.CreateToken(c.Request.Context(), sess, permissions, req.Name, req.Scopes, req.ValidDays)
+	if err != nil {
+		if _, ok := err.(*errors.InvalidInputError); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to create token for %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create token"})
+		return
+	}
+
+	log.Infof("user %s created token %s (%s) with scopes %v", sess.UserID, token.ID, token.Name, token.Scopes)
+	// the secret is only returned once
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     secret,
+		"id":        token.ID,
+		"name":      token.Name,
+		"scopes":    token.Scopes,
+		"expiresAt": token.ExpiresAt,
+	})
+}
+
+func (h *BffAuthHandler) handleRevokeToken(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	tokenID := c.Param("tokenId")
+	err := h.tokenService.RevokeToken(c.Request.Context(), sess.UserID, tokenID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to revoke token %s: %v", tokenID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke token"})
+		return
+	}
+
+	log.Infof("user %s revoked token %s", sess.UserID, tokenID)
 	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,88 @@
+package inbound
+
+import (
+	"html/template"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// DevProviderName is the login provider enabled by DEV_LOGIN
+const DevProviderName = "dev"
+
+var devLoginPage = template.Must(template.New("devLogin").Parse(`<!DOCTYPE html>
+<html lang="de">
+<head><meta charset="utf-8"><title>Entwicklungs-Login</title></head>
+<body style="font-family: sans-serif; max-width: 30em; margin: 3em auto">
+<h1>Entwicklungs-Login</h1>
+<p>Nur für die lokale Entwicklung, ohne Identity Provider.</p>
+<form method="post" action="/auth/dev-login">
+	<p><label>Name <input name="name" value="Dev-Mitglied" required maxlength="100"></label></p>
+	<fieldset>
+		<legend>Berechtigungen</legend>
+		{{range .Permissions}}<p><label><input type="checkbox" name="permission" value="{{.}}" checked> {{.}}</label></p>
+		{{end}}
+	</fieldset>
+	<input type="hidden" name="redirect" value="{{.Redirect}}">
+	<p><button type="submit">Anmelden</button></p>
+</form>
+</body>
+</html>
+`))
+
+// devLoginAllowed refuses the dev login on HTTPS, which only deployed instances use.
+func (h *BffAuthHandler) devLoginAllowed(c *gin.Context) bool {
+	if !h.devLogin {
+		return false
+	}
+	if isHTTPSRequest(c) || strings.HasPrefix(strings.ToLower(c.GetHeader("Origin")), "https://") {
+		log.Warn("dev login refused for HTTPS request")
+		return false
+	}
+	return true
+}
+
+func (h *BffAuthHandler) handleDevLoginPage(c *gin.Context) {
+	if !h.devLoginAllowed(c) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	err := devLoginPage.Execute(c.Writer, gin.H{
+		"Permissions": session.KnownPermissions,
+		"Redirect":    c.Query("redirect"),
+	})
+	if err != nil {
+		log.Errorf("could not render dev login page: %v", err)
+	}
+}
+
+func (h *BffAuthHandler) handleDevLogin(c *gin.Context) {
+	if !h.devLoginAllowed(c) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	name := strings.Join(strings.Fields(c.PostForm("name")), " ")
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name is required"})
+		return
+	}
+	// the permissions are passed like roles of an identity provider, so local roles apply as well
+	roles := make([]string, 0)
+	for _, permission := range c.PostFormArray("permission") {
+		if slices.Contains(session.KnownPermissions, permission) && !slices.Contains(roles, permission) {
+			roles = append(roles, permission)
+		}
+	}
+
+	userID := DevProviderName + ":" + strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+	log.Warnf("dev login of %s (%s) with %v", name, userID, roles)
+	h.startSession(c, DevProviderName, userID, name, roles, "", c.PostForm("redirect"))
+}
@@ -0,0 +1,116 @@
+package inbound
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+)
+
+func newDevLoginTestRouter(handler *BffAuthHandler) *gin.Engine {
+	r := gin.New()
+	r.GET("/auth/dev-login", handler.handleDevLoginPage)
+	r.POST("/auth/dev-login", handler.handleDevLogin)
+	return r
+}
+
+func postDevLogin(r *gin.Engine, form url.Values, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/auth/dev-login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestBffAuthHandler_DevLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("disabled dev login is not found", func(t *testing.T) {
+		handler := NewBffAuthHandler(&AuthConfig{FrontendURL: "http://frontend"}, nil, nil, nil, nil, nil)
+		r := newDevLoginTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/dev-login", nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+		w = postDevLogin(r, url.Values{"name": {"Stefan"}}, nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("dev login is ignored for HTTPS frontend", func(t *testing.T) {
+		handler := NewBffAuthHandler(&AuthConfig{FrontendURL: "https://filmkritiken.example", DevLogin: true}, nil, nil, nil, nil, nil)
+		r := newDevLoginTestRouter(handler)
+
+		w := postDevLogin(r, url.Values{"name": {"Stefan"}}, nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("dev login is refused for HTTPS requests", func(t *testing.T) {
+		handler := NewBffAuthHandler(&AuthConfig{FrontendURL: "http://frontend", DevLogin: true}, nil, nil, nil, nil, nil)
+		r := newDevLoginTestRouter(handler)
+
+		w := postDevLogin(r, url.Values{"name": {"Stefan"}}, map[string]string{"X-Forwarded-Proto": "https"})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for forwarded HTTPS, got %d", w.Code)
+		}
+		w = postDevLogin(r, url.Values{"name": {"Stefan"}}, map[string]string{"Origin": "https://frontend"})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404 for HTTPS origin, got %d", w.Code)
+		}
+	})
+
+	t.Run("dev login creates a session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		sessionRepo := mocks.NewMockSessionRepository(ctrl)
+		userRepo := mocks.NewMockUserRepository(ctrl)
+		permissionService := mocks.NewMockPermissionService(ctrl)
+		config := &AuthConfig{FrontendURL: "http://frontend", SessionDurationDays: 7, DevLogin: true}
+		handler := NewBffAuthHandler(config, sessionRepo, userRepo, permissionService, nil, nil)
+		r := newDevLoginTestRouter(handler)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/dev-login", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "film.add") {
+			t.Fatalf("expected login page with permissions, got %d", w.Code)
+		}
+
+		roles := []string{"bewertung.add"}
+		userRepo.EXPECT().FindUser(gomock.Any(), "dev:stefan-blum").Return(nil, errors.NewNotFoundErrorFromString("Benutzer nicht gefunden."))
+		userRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+		permissionService.EXPECT().ResolvePermissions(gomock.Any(), "dev:stefan-blum", roles).Return(roles, nil)
+		var saved *session.Session
+		sessionRepo.EXPECT().SaveSession(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, s *session.Session) error {
+			saved = s
+			return nil
+		})
+
+		w = postDevLogin(r, url.Values{
+			"name":       {" Stefan  Blum "},
+			"permission": {"bewertung.add", "admin"},
+			"redirect":   {"/filmkritiken"},
+		}, nil)
+
+		if w.Code != http.StatusFound || w.Header().Get("Location") != "http://frontend/filmkritiken" {
+			t.Fatalf("expected redirect to the frontend, got %d %s", w.Code, w.Header().Get("Location"))
+		}
+		if saved == nil || saved.Provider != DevProviderName || saved.Name != "Stefan Blum" || !slices.Equal(saved.Permissions, roles) {
+			t.Errorf("unexpected session %+v", saved)
+		}
+	})
+}
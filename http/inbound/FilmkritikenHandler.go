@@ -23,6 +23,8 @@ type (
 		Von            string             `json:"von"`
 		BesprochenAm   *time.Time         `json:"besprochenam"`
 		BewertungOffen bool               `json:"bewertungoffen"`
+		// PosterRef of a FilmCandidate from the film search, used if no image is uploaded
+		PosterRef string `json:"posterref"`
 	}
 
 	SetBewertungRequest struct {
@@ -40,9 +42,33 @@ type (
 		BesprochenAm time.Time `json:"besprochenam"`
 	}
 
+	CreateReiheRequest struct {
+		Name string `json:"name"`
+	}
+
+	SetReiheEintragRequest struct {
+		// Position in the Reihe, appended at the end if 0
+		Position int `json:"position"`
+	}
+
+	MergeMitgliedRequest struct {
+		Von  string `json:"von"`
+		Nach string `json:"nach"`
+		// Regel for Filmkritiken rated by both members: abbrechen (default), ziel or quelle
+		Regel string `json:"regel"`
+	}
+
+	SetKategorienRequest struct {
+		Genres []string `json:"genres"`
+		Tags   []string `json:"tags"`
+	}
+
 	BenutzerBewertung struct {
 		Wertung  int    `json:"wertung"`
 		Benutzer string `json:"benutzer"`
+		// BenutzerId is empty for members without login
+		BenutzerId string `json:"benutzerId"`
+		Enthaltung bool   `json:"enthaltung"`
 	}
 
 	FilmkritikenPageResponse struct {
@@ -79,6 +105,10 @@ func (h *filmkritikenHandler) handleGetFilmkritiken(ginCtx *gin.Context) {
 	}
 	jahr, _ := parseIntFromQueryParam(queryParams, "jahr")
 	beitragvon := queryParams.Get("beitragvon")
+	genre := queryParams.Get("genre")
+	tag := queryParams.Get("tag")
+	darsteller := queryParams.Get("darsteller")
+	reihe := queryParams.Get("reihe")
 	sortierung := queryParams.Get("sortierung")
 
 	filter := &filmkritiken.FilmkritikenFilter{
@@ -88,6 +118,10 @@ func (h *filmkritikenHandler) handleGetFilmkritiken(ginCtx *gin.Context) {
 		Titel:      suche,
 		Jahr:       jahr,
 		BeitragVon: beitragvon,
+		Genre:      genre,
+		Tag:        tag,
+		Darsteller: darsteller,
+		Reihe:      reihe,
 		Sortierung: sortierung,
 	}
 	result, totalCount, err := h.filmkritikenService.GetFilmkritiken(ginCtx.Request.Context(), filter)
@@ -176,26 +210,44 @@ func (h *filmkritikenHandler) handleCreateFilm(ginCtx *gin.Context) {
 		return
 	}
 
-	// read image
+	// read image (or download the poster of a film search result)
+	var imageBites []byte
 	fileHeader, err = ginCtx.FormFile("image")
-	if err != nil {
-		log.Errorf("could not get uploaded image: %v", err)
-		ginCtx.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-	file, err := fileHeader.Open()
-	if err != nil {
-		log.Errorf("could not open uploaded image: %v", err)
-		ginCtx.AbortWithStatus(http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
+	if err != nil && req.Film != nil && req.Film.Id != "" {
+		// another screening of a stored film keeps its poster
+	} else if err != nil && req.PosterRef != "" {
+		poster, downloadErr := h.filmkritikenService.DownloadPoster(ginCtx.Request.Context(), req.PosterRef)
+		if downloadErr != nil {
+			writePosterDownloadError(ginCtx, downloadErr)
+			return
+		}
+		imageBites = *poster
+	} else {
+		if err != nil {
+			log.Errorf("could not get uploaded image: %v", err)
+			ginCtx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		if fileHeader.Size > filmkritiken.MaxImageSize {
+			log.Warnf("uploaded image too large: %d bytes", fileHeader.Size)
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(fmt.Sprintf("Das Bild ist zu groß (maximal %d MB).", filmkritiken.MaxImageSize>>20))
+			return
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			log.Errorf("could not open uploaded image: %v", err)
+			ginCtx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
 
-	imageBites, err := io.ReadAll(file)
-	if err != nil {
-		log.Errorf("could not read uploaded image: %v", err)
-		ginCtx.AbortWithStatus(http.StatusBadRequest)
-		return
+		imageBites, err = io.ReadAll(file)
+		if err != nil {
+			log.Errorf("could not read uploaded image: %v", err)
+			ginCtx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
 	}
 
 	// create film
@@ -206,6 +258,18 @@ func (h *filmkritikenHandler) handleCreateFilm(ginCtx *gin.Context) {
 	}
 	result, err := h.filmkritikenService.CreateFilm(ginCtx.Request.Context(), req.Film, filmkritikenDetails, &imageBites)
 	if err != nil {
+		if _, ok := err.(*domainErrors.InvalidInputError); ok {
+			log.Warnf("rejected film: %v", err)
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		if _, ok := err.(*domainErrors.UnavailableError); ok {
+			log.Warnf("could not create film: %v", err)
+			ginCtx.Writer.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
 		log.Errorf("could not create film: %v", err)
 		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
 		_, _ = ginCtx.Writer.WriteString(err.Error())
@@ -215,6 +279,45 @@ func (h *filmkritikenHandler) handleCreateFilm(ginCtx *gin.Context) {
 	ginCtx.JSON(http.StatusCreated, result)
 }
 
+func writePosterDownloadError(ginCtx *gin.Context, err error) {
+	switch err.(type) {
+	case *domainErrors.InvalidInputError, *domainErrors.NotFoundError:
+		log.Warnf("could not download poster: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	case *domainErrors.UnavailableError:
+		ginCtx.Writer.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+	log.Errorf("could not download poster: %v", err)
+	ginCtx.Writer.WriteHeader(http.StatusBadGateway)
+	_, _ = ginCtx.Writer.WriteString("Das Filmplakat konnte nicht geladen werden.")
+}
+
+func (h *filmkritikenHandler) handleSearchFilms(ginCtx *gin.Context) {
+	candidates, err := h.filmkritikenService.SearchFilms(ginCtx.Request.Context(), ginCtx.Query("q"))
+	if err != nil {
+		switch err.(type) {
+		case *domainErrors.InvalidInputError:
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		case *domainErrors.UnavailableError:
+			ginCtx.Writer.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not search films: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusBadGateway)
+		_, _ = ginCtx.Writer.WriteString("Die Filmsuche ist fehlgeschlagen.")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, candidates)
+}
+
 func (h *filmkritikenHandler) handleOpenCloseBewertungen(ginCtx *gin.Context) {
 
 	filmkritikenId := ginCtx.Param("filmkritikenId")
@@ -282,15 +385,17 @@ func (h *filmkritikenHandler) handleSetBewertung(ginCtx *gin.Context) {
 	requestContext := ginCtx.Request.Context()
 
 	username := requestContext.Value(filmkritiken.Context_Username).(string)
+	userId := requestContext.Value(filmkritiken.Context_UserId).(string)
 
-	if usernameFromUrl != username {
+	// the URL may contain the user id or (as before user ids existed) the display name
+	if usernameFromUrl != userId && usernameFromUrl != username {
 		log.Warnf("users in URL (%s) and token (%s) do not match", usernameFromUrl, username)
 		ginCtx.Writer.WriteHeader(http.StatusBadRequest)
 		_, _ = ginCtx.Writer.WriteString("Benutzer muss mit eingeloggtem Benutzer übereinstimmen")
 		return
 	}
 
-	err = h.filmkritikenService.SetKritik(requestContext, req.FilmkritikenId, username, req.Wertung, req.Enthaltung)
+	err = h.filmkritikenService.SetKritik(requestContext, req.FilmkritikenId, userId, username, req.Wertung, req.Enthaltung)
 
 	if err != nil {
 		if _, ok := err.(*domainErrors.InvalidInputError); ok {
@@ -313,6 +418,58 @@ func (h *filmkritikenHandler) handleSetBewertung(ginCtx *gin.Context) {
 	ginCtx.Writer.WriteHeader(http.StatusNoContent)
 }
 
+// handleSetBewertungen stores the Bewertungen of all members the moderator collected at the meeting.
+func (h *filmkritikenHandler) handleSetBewertungen(ginCtx *gin.Context) {
+	req := &SetBewertungBulkRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Error("could not map json to SetBewertungBulkRequest")
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	filmkritikenId := ginCtx.Param("filmkritikenId")
+	if filmkritikenId == "" || (req.FilmkritikenId != "" && req.FilmkritikenId != filmkritikenId) {
+		ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+		_, _ = ginCtx.Writer.WriteString("Film muss angegeben werden")
+		return
+	}
+
+	eingaben := make([]*filmkritiken.BewertungEingabe, 0, len(req.Bewertungen))
+	for _, bewertung := range req.Bewertungen {
+		if bewertung == nil {
+			continue
+		}
+		eingaben = append(eingaben, &filmkritiken.BewertungEingabe{
+			VonId:      bewertung.BenutzerId,
+			Von:        bewertung.Benutzer,
+			Wertung:    bewertung.Wertung,
+			Enthaltung: bewertung.Enthaltung,
+		})
+	}
+
+	err = h.filmkritikenService.SetKritiken(ginCtx.Request.Context(), filmkritikenId, eingaben)
+	if err != nil {
+		if _, ok := err.(*domainErrors.InvalidInputError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		if _, ok := err.(*domainErrors.NotFoundError); ok {
+			log.Warnf("could not find filmkritiken (%s): %v", filmkritikenId, err)
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not set bewertungen: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
 func (h *filmkritikenHandler) loadImage(ginCtx *gin.Context) {
 	imageId := ginCtx.Param("imageId")
 	if imageId == "" {
@@ -321,7 +478,10 @@ func (h *filmkritikenHandler) loadImage(ginCtx *gin.Context) {
 		return
 	}
 
-	imageBites, err := h.filmkritikenService.LoadImage(ginCtx.Request.Context(), imageId)
+	// optional width (px) to get the closest resized variant instead of the original
+	width, _ := parseIntFromQueryParam(ginCtx.Request.URL.Query(), "w")
+
+	imageFile, err := h.filmkritikenService.LoadImage(ginCtx.Request.Context(), imageId, width)
 	if err != nil {
 		if _, ok := err.(*domainErrors.NotFoundError); ok {
 			log.Warnf("could not find image (%s): %v", imageId, err)
@@ -335,10 +495,16 @@ func (h *filmkritikenHandler) loadImage(ginCtx *gin.Context) {
 		return
 	}
 
+	defer imageFile.Content.Close()
+
 	ginCtx.Writer.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%v, immutable", imageCacheDuration))
-	ginCtx.Writer.WriteHeader(http.StatusOK)
-	_, _ = ginCtx.Writer.Write(*imageBites)
+	ginCtx.Writer.Header().Set("Content-Type", imageFile.ContentType)
+	ginCtx.Writer.Header().Set("X-Content-Type-Options", "nosniff")
+	// new images are stored under the SHA-256 of their content, so the id is a strong ETag
+	ginCtx.Writer.Header().Set("ETag", `"`+imageFile.Id+`"`)
 
+	// streams the image and handles Range, If-None-Match and If-Modified-Since requests
+	http.ServeContent(ginCtx.Writer, ginCtx.Request, "", imageFile.ModTime, imageFile.Content)
 }
 
 func (h *filmkritikenHandler) handleSetBesprochenAm(ginCtx *gin.Context) {
@@ -374,6 +540,240 @@ func (h *filmkritikenHandler) handleSetBesprochenAm(ginCtx *gin.Context) {
 	ginCtx.Writer.WriteHeader(http.StatusNoContent)
 }
 
+func (h *filmkritikenHandler) handleSetKategorien(ginCtx *gin.Context) {
+	filmkritikenId := ginCtx.Param("filmkritikenId")
+	if filmkritikenId == "" {
+		ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+		_, _ = ginCtx.Writer.WriteString("Film muss angegeben werden")
+		return
+	}
+
+	req := &SetKategorienRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Errorf("could not map json to SetKategorienRequest: %v", err)
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	err = h.filmkritikenService.UpdateKategorien(ginCtx.Request.Context(), filmkritikenId, req.Genres, req.Tags)
+	if err != nil {
+		if _, ok := err.(*domainErrors.InvalidInputError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		if _, ok := err.(*domainErrors.NotFoundError); ok {
+			log.Warnf("could not find filmkritiken (%s): %v", filmkritikenId, err)
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not update genres and tags: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h *filmkritikenHandler) handleGetMitgliederStatistiken(ginCtx *gin.Context) {
+	statistiken, err := h.filmkritikenService.GetMitgliederStatistiken(ginCtx.Request.Context())
+	if err != nil {
+		log.Errorf("Could not get Statistiken: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString("Could not get Statistiken")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, statistiken)
+}
+
+func (h *filmkritikenHandler) handleGetFilm(ginCtx *gin.Context) {
+	filmId := ginCtx.Param("filmId")
+
+	uebersicht, err := h.filmkritikenService.GetFilm(ginCtx.Request.Context(), filmId)
+	if err != nil {
+		if _, ok := err.(*domainErrors.NotFoundError); ok {
+			log.Warnf("could not find film (%s): %v", filmId, err)
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not get film (%s): %v", filmId, err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString("Could not get Film from DB")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, uebersicht)
+}
+
+func (h *filmkritikenHandler) handleGetPerson(ginCtx *gin.Context) {
+	name := ginCtx.Param("name")
+
+	person, err := h.filmkritikenService.GetPerson(ginCtx.Request.Context(), name)
+	if err != nil {
+		if _, ok := err.(*domainErrors.InvalidInputError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		if _, ok := err.(*domainErrors.NotFoundError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not get person (%s): %v", name, err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString("Could not get Person")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, person)
+}
+
+func (h *filmkritikenHandler) handleCreateReihe(ginCtx *gin.Context) {
+	req := &CreateReiheRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Errorf("could not map json to CreateReiheRequest: %v", err)
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	reihe, err := h.filmkritikenService.CreateReihe(ginCtx.Request.Context(), req.Name)
+	if err != nil {
+		if _, ok := err.(*domainErrors.InvalidInputError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not create reihe: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+
+	ginCtx.JSON(http.StatusCreated, reihe)
+}
+
+func (h *filmkritikenHandler) handleGetReihen(ginCtx *gin.Context) {
+	reihen, err := h.filmkritikenService.GetReihen(ginCtx.Request.Context())
+	if err != nil {
+		log.Errorf("Could not get Reihen: %v", err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString("Could not get Reihen")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, reihen)
+}
+
+func (h *filmkritikenHandler) handleGetReihe(ginCtx *gin.Context) {
+	reiheId := ginCtx.Param("reiheId")
+
+	uebersicht, err := h.filmkritikenService.GetReihe(ginCtx.Request.Context(), reiheId)
+	if err != nil {
+		if _, ok := err.(*domainErrors.NotFoundError); ok {
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ginCtx.Writer.WriteString(err.Error())
+			return
+		}
+		log.Errorf("could not get reihe (%s): %v", reiheId, err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		_, _ = ginCtx.Writer.WriteString("Could not get Reihe")
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, uebersicht)
+}
+
+func (h *filmkritikenHandler) handleSetReiheEintrag(ginCtx *gin.Context) {
+	reiheId := ginCtx.Param("reiheId")
+	filmkritikenId := ginCtx.Param("filmkritikenId")
+
+	req := &SetReiheEintragRequest{}
+	if ginCtx.Request.ContentLength != 0 {
+		err := ginCtx.ShouldBindJSON(req)
+		if err != nil {
+			log.Errorf("could not map json to SetReiheEintragRequest: %v", err)
+			ginCtx.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := h.filmkritikenService.SetReiheEintrag(ginCtx.Request.Context(), reiheId, filmkritikenId, req.Position)
+	if err != nil {
+		writeReiheEintragError(ginCtx, reiheId, filmkritikenId, err)
+		return
+	}
+
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h *filmkritikenHandler) handleRemoveReiheEintrag(ginCtx *gin.Context) {
+	reiheId := ginCtx.Param("reiheId")
+	filmkritikenId := ginCtx.Param("filmkritikenId")
+
+	err := h.filmkritikenService.RemoveReiheEintrag(ginCtx.Request.Context(), reiheId, filmkritikenId)
+	if err != nil {
+		writeReiheEintragError(ginCtx, reiheId, filmkritikenId, err)
+		return
+	}
+
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h *filmkritikenHandler) handleMergeMitglied(ginCtx *gin.Context) {
+	req := &MergeMitgliedRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Errorf("could not map json to MergeMitgliedRequest: %v", err)
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+	vorschau := ginCtx.Query("vorschau") == "true"
+
+	zusammenfuehrung := &filmkritiken.MitgliedZusammenfuehrung{
+		Von:   req.Von,
+		Nach:  req.Nach,
+		Regel: filmkritiken.KonfliktRegel(req.Regel),
+	}
+	ergebnis, err := h.filmkritikenService.MergeMitglied(ginCtx.Request.Context(), zusammenfuehrung, vorschau)
+	if err != nil {
+		switch err.(type) {
+		case *domainErrors.InvalidInputError:
+			ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+		case *domainErrors.NotFoundError:
+			ginCtx.Writer.WriteHeader(http.StatusNotFound)
+		default:
+			log.Errorf("could not merge %s into %s: %v", req.Von, req.Nach, err)
+			ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+
+	if ergebnis.Ausgefuehrt {
+		log.Infof("merged %s into %s: %d Bewertungen, %d Beiträge", ergebnis.Von, ergebnis.Nach, ergebnis.AnzahlBewertungen, ergebnis.AnzahlBeitraege)
+	}
+	ginCtx.JSON(http.StatusOK, ergebnis)
+}
+
+func writeReiheEintragError(ginCtx *gin.Context, reiheId string, filmkritikenId string, err error) {
+	if _, ok := err.(*domainErrors.NotFoundError); ok {
+		log.Warnf("could not find reihe (%s) or filmkritiken (%s): %v", reiheId, filmkritikenId, err)
+		ginCtx.Writer.WriteHeader(http.StatusNotFound)
+		_, _ = ginCtx.Writer.WriteString(err.Error())
+		return
+	}
+	log.Errorf("could not update reihe (%s): %v", reiheId, err)
+	ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+	_, _ = ginCtx.Writer.WriteString(err.Error())
+}
+
 func parseIntFromQueryParam(queryParams url.Values, paramName string) (int, error) {
 	values := queryParams[paramName]
 	if len(values) == 1 {
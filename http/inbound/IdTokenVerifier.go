@@ -0,0 +1,184 @@
+package inbound
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	jwksCacheDuration = 24 * time.Hour
+	// unknown key ids (after a key rotation) reload the keys, but not more often than this
+	jwksMinRefreshInterval = time.Minute
+	jwksTimeout            = 10 * time.Second
+	idTokenLeeway          = time.Minute
+)
+
+type (
+	jwksResponse struct {
+		Keys []*jwksKey `json:"keys"`
+	}
+
+	jwksKey struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	}
+
+	// jwksKeySet caches the signing keys of the identity provider
+	jwksKeySet struct {
+		url        string
+		httpClient *http.Client
+		mutex      sync.Mutex
+		keys       map[string]*rsa.PublicKey
+		fetchedAt  time.Time
+	}
+
+	idTokenVerifier struct {
+		issuer   string
+		audience string
+		keySet   *jwksKeySet
+	}
+)
+
+func newIdTokenVerifier(issuer string, audience string, jwksURL string, httpClient *http.Client) *idTokenVerifier {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: jwksTimeout}
+	}
+	return &idTokenVerifier{
+		issuer:   issuer,
+		audience: audience,
+		keySet:   &jwksKeySet{url: jwksURL, httpClient: httpClient},
+	}
+}
+
+// Verify checks signature, issuer, audience, expiry and nonce of the ID token and returns its claims.
+func (v *idTokenVerifier) Verify(ctx context.Context, rawIdToken string, nonce string) (jwt.MapClaims, error) {
+	claims, err := v.parse(ctx, rawIdToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); nonce == "" || tokenNonce != nonce {
+		return nil, fmt.Errorf("nonce of ID token does not match")
+	}
+	return claims, nil
+}
+
+// VerifyRefreshed checks an ID token of a refresh token response, which has no nonce of a login.
+func (v *idTokenVerifier) VerifyRefreshed(ctx context.Context, rawIdToken string) (jwt.MapClaims, error) {
+	return v.parse(ctx, rawIdToken)
+}
+
+func (v *idTokenVerifier) parse(ctx context.Context, rawIdToken string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(
+		rawIdToken,
+		claims,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			return v.keySet.key(ctx, kid)
+		},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (k *jwksKeySet) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	key, known := k.keys[kid]
+	sinceFetch := time.Since(k.fetchedAt)
+	if known && sinceFetch < jwksCacheDuration {
+		return key, nil
+	}
+	if !known && k.keys != nil && sinceFetch < jwksMinRefreshInterval {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		if known {
+			log.Warnf("could not refresh JWKS, using cached key %s: %v", kid, err)
+			return key, nil
+		}
+		return nil, err
+	}
+	k.keys = keys
+	k.fetchedAt = time.Now()
+
+	if key, known = k.keys[kid]; !known {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (k *jwksKeySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := k.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS request failed with status %d", resp.StatusCode)
+	}
+
+	jwks := &jwksResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			log.Warnf("skipping invalid JWKS key %s: %v", jwk.Kid, err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func (jwk *jwksKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 {
+		return nil, fmt.Errorf("invalid modulus or exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
@@ -0,0 +1,163 @@
+package inbound
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "https://login.example.com/tenant/v2.0"
+	testAudience = "client-id"
+	testNonce    = "nonce-123"
+)
+
+type testJwks struct {
+	keys     map[string]*rsa.PrivateKey
+	requests atomic.Int32
+}
+
+func (j *testJwks) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	j.requests.Add(1)
+	response := &jwksResponse{}
+	for kid, key := range j.keys {
+		response.Keys = append(response.Keys, &jwksKey{
+			Kid: kid,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func generateTestKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %v", err)
+	}
+	return key
+}
+
+func signTestIdToken(t *testing.T, key *rsa.PrivateKey, kid string, modify func(claims jwt.MapClaims)) string {
+	claims := jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   "sub-stefan",
+		"oid":   "oid-stefan",
+		"name":  "Stefan Blum",
+		"nonce": testNonce,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	}
+	if modify != nil {
+		modify(claims)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("could not sign token: %v", err)
+	}
+	return signed
+}
+
+func TestIdTokenVerifier_Verify(t *testing.T) {
+	key := generateTestKey(t)
+	jwks := &testJwks{keys: map[string]*rsa.PrivateKey{"key-1": key}}
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	verifier := newIdTokenVerifier(testIssuer, testAudience, server.URL, server.Client())
+	ctx := context.Background()
+
+	t.Run("valid token returns claims", func(t *testing.T) {
+		claims, err := verifier.Verify(ctx, signTestIdToken(t, key, "key-1", nil), testNonce)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if claims["oid"] != "oid-stefan" {
+			t.Errorf("unexpected claims %v", claims)
+		}
+	})
+
+	invalid := map[string]struct {
+		token string
+		nonce string
+	}{
+		"wrong nonce":       {signTestIdToken(t, key, "key-1", nil), "other-nonce"},
+		"missing nonce":     {signTestIdToken(t, key, "key-1", nil), ""},
+		"wrong audience":    {signTestIdToken(t, key, "key-1", func(c jwt.MapClaims) { c["aud"] = "other-client" }), testNonce},
+		"wrong issuer":      {signTestIdToken(t, key, "key-1", func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }), testNonce},
+		"expired":           {signTestIdToken(t, key, "key-1", func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }), testNonce},
+		"missing expiry":    {signTestIdToken(t, key, "key-1", func(c jwt.MapClaims) { delete(c, "exp") }), testNonce},
+		"foreign signature": {signTestIdToken(t, generateTestKey(t), "key-1", nil), testNonce},
+		"unsigned":          {unsignedTestIdToken(t), testNonce},
+	}
+	for name, tc := range invalid {
+		t.Run(name+" is rejected", func(t *testing.T) {
+			if _, err := verifier.Verify(ctx, tc.token, tc.nonce); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if requests := jwks.requests.Load(); requests != 1 {
+		t.Errorf("expected the keys to be loaded once, got %d requests", requests)
+	}
+}
+
+func TestIdTokenVerifier_KeyRotation(t *testing.T) {
+	oldKey, newKey := generateTestKey(t), generateTestKey(t)
+	jwks := &testJwks{keys: map[string]*rsa.PrivateKey{"old": oldKey}}
+	server := httptest.NewServer(jwks)
+	defer server.Close()
+
+	verifier := newIdTokenVerifier(testIssuer, testAudience, server.URL, server.Client())
+	ctx := context.Background()
+
+	if _, err := verifier.Verify(ctx, signTestIdToken(t, oldKey, "old", nil), testNonce); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	jwks.keys = map[string]*rsa.PrivateKey{"new": newKey}
+	newToken := signTestIdToken(t, newKey, "new", nil)
+
+	// right after loading the keys, unknown key ids don't trigger a new request
+	if _, err := verifier.Verify(ctx, newToken, testNonce); err == nil {
+		t.Error("expected an error within the minimum refresh interval")
+	}
+
+	verifier.keySet.fetchedAt = time.Now().Add(-jwksMinRefreshInterval)
+	if _, err := verifier.Verify(ctx, newToken, testNonce); err != nil {
+		t.Fatalf("expected the rotated key to be loaded, got %v", err)
+	}
+	if requests := jwks.requests.Load(); requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func unsignedTestIdToken(t *testing.T) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"nonce": testNonce,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	signed, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("could not create token: %v", err)
+	}
+	return signed
+}
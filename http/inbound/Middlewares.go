@@ -1,8 +1,13 @@
 package inbound
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
@@ -32,43 +37,124 @@ func NewEmptyHandler() func(ginCtx *gin.Context) {
 	}
 }
 
-func NewAuthHandler(sessionRepo session.SessionRepository, allowedRoles []string) func(ginCtx *gin.Context) {
+// NewAuthHandler checks the session (or the personal access token, if tokenService is set) and its permissions.
+// Without permissionService, the permissions of the session at login are used, otherwise they are resolved on
+// every request so role changes apply immediately.
+func NewAuthHandler(sessionRepo session.SessionRepository, permissionService session.PermissionService, tokenService session.TokenService, allowedRoles []string) func(ginCtx *gin.Context) {
 	return func(ginCtx *gin.Context) {
-		authHandler(ginCtx, sessionRepo, allowedRoles)
+		authHandler(ginCtx, sessionRepo, permissionService, tokenService, allowedRoles)
 	}
 }
 
-func authHandler(ginCtx *gin.Context, sessionRepo session.SessionRepository, allowedRoles []string) {
-	if sessionRepo == nil {
-		log.Warn("sessionRepo not configured in authHandler")
-		ginCtx.AbortWithStatus(http.StatusUnauthorized)
-		return
+func authHandler(ginCtx *gin.Context, sessionRepo session.SessionRepository, permissionService session.PermissionService, tokenService session.TokenService, allowedRoles []string) {
+	var sess *session.Session
+	// scopes of a personal access token, nil for browser sessions
+	var scopes []string
+
+	if secret, ok := bearerToken(ginCtx); ok {
+		if tokenService == nil {
+			log.Warn("tokenService not configured in authHandler")
+			ginCtx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		token, user, err := tokenService.Authenticate(ginCtx.Request.Context(), secret)
+		if err != nil {
+			log.Warnf("token invalid or expired: %v", err)
+			ginCtx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		// the roles of the identity provider are those of the last login or refresh of the user, never a copy
+		// kept with the token
+		idpRoles := user.IdpRoles
+		if idpRoles == nil {
+			idpRoles = make([]string, 0)
+		}
+		sess = &session.Session{
+			ID:          token.ID,
+			UserID:      user.ID,
+			Name:        user.Name,
+			Permissions: token.Scopes,
+			Roles:       idpRoles,
+			ExpiresAt:   token.ExpiresAt,
+		}
+		scopes = token.Scopes
+	} else {
+		if sessionRepo == nil {
+			log.Warn("sessionRepo not configured in authHandler")
+			ginCtx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		sessionID, err := ginCtx.Cookie(SessionCookieName)
+		if err != nil || sessionID == "" {
+			log.Warn("received request without valid session cookie")
+			ginCtx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		sess, err = sessionRepo.FindSession(ginCtx.Request.Context(), sessionID)
+		if err != nil || sess == nil || sess.ExpiresAt.Before(time.Now()) {
+			log.Warnf("session invalid or expired: %v", err)
+			ginCtx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		touchSession(ginCtx, sessionRepo, sess)
 	}
 
-	sessionID, err := ginCtx.Cookie(SessionCookieName)
-	if err != nil || sessionID == "" {
-		log.Warn("received request without valid session cookie")
+	if sess.UserID == "" {
+		// sessions created before users were identified by id have to log in again
+		log.Warnf("session of %s has no user id", sess.Name)
 		ginCtx.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
 
-	sess, err := sessionRepo.FindSession(ginCtx.Request.Context(), sessionID)
-	if err != nil || sess == nil || sess.ExpiresAt.Before(time.Now()) {
-		log.Warnf("session invalid or expired: %v", err)
-		ginCtx.AbortWithStatus(http.StatusUnauthorized)
-		return
+	permissions := sess.Permissions
+	if permissionService != nil {
+		var err error
+		permissions, err = permissionService.ResolvePermissions(ginCtx.Request.Context(), sess.UserID, sessionIdpRoles(sess))
+		if err != nil {
+			log.Errorf("could not resolve permissions of %s: %v", sess.UserID, err)
+			ginCtx.AbortWithStatus(http.StatusInternalServerError)
+			return
+		}
+	}
+	if scopes != nil {
+		// a token never grants more than the user currently has
+		permissions = slices.DeleteFunc(slices.Clone(permissions), func(permission string) bool {
+			return !slices.Contains(scopes, permission)
+		})
 	}
 
-	if !hasSessionRole(allowedRoles, sess.Permissions) {
+	if !hasSessionRole(allowedRoles, permissions) {
 		log.Warnf("session user %s lacks required roles %v", sess.Name, allowedRoles)
 		ginCtx.AbortWithStatus(http.StatusForbidden)
 		return
 	}
 
 	newCtx := context.WithValue(ginCtx.Request.Context(), filmkritiken.Context_Username, sess.Name)
+	newCtx = context.WithValue(newCtx, filmkritiken.Context_UserId, sess.UserID)
 	ginCtx.Request = ginCtx.Request.WithContext(newCtx)
 }
 
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(ginCtx *gin.Context) (string, bool) {
+	scheme, token, found := strings.Cut(ginCtx.GetHeader("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(token), true
+}
+
+// sessionIdpRoles returns the roles of the identity provider, sessions from before local roles existed stored
+// them as permissions.
+func sessionIdpRoles(sess *session.Session) []string {
+	if sess.Roles == nil {
+		return sess.Permissions
+	}
+	return sess.Roles
+}
+
 func hasSessionRole(allowedRoles []string, userPermissions []string) bool {
 	if len(allowedRoles) == 0 {
 		return true
@@ -82,3 +168,108 @@ func hasSessionRole(allowedRoles []string, userPermissions []string) bool {
 	}
 	return false
 }
+
+// ConditionalGetMiddleware buffers successful GET/HEAD responses, adds a strong ETag based on the
+// content hash (unless the handler already set one) and answers If-None-Match / If-Modified-Since
+// with 304 Not Modified.
+func ConditionalGetMiddleware(ginCtx *gin.Context) {
+	method := ginCtx.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		ginCtx.Next()
+		return
+	}
+
+	originalWriter := ginCtx.Writer
+	bufferedWriter := &bufferedResponseWriter{
+		ResponseWriter: originalWriter,
+		body:           &bytes.Buffer{},
+		status:         http.StatusOK,
+	}
+	ginCtx.Writer = bufferedWriter
+	ginCtx.Next()
+	ginCtx.Writer = originalWriter
+
+	if bufferedWriter.status == http.StatusOK {
+		header := originalWriter.Header()
+		etag := header.Get("ETag")
+		if etag == "" {
+			hash := sha256.Sum256(bufferedWriter.body.Bytes())
+			etag = `"` + hex.EncodeToString(hash[:16]) + `"`
+			header.Set("ETag", etag)
+		}
+
+		if isNotModified(ginCtx.Request, etag, header.Get("Last-Modified")) {
+			header.Del("Content-Type")
+			header.Del("Content-Length")
+			originalWriter.WriteHeader(http.StatusNotModified)
+			originalWriter.WriteHeaderNow()
+			return
+		}
+	}
+
+	originalWriter.WriteHeader(bufferedWriter.status)
+	_, _ = originalWriter.Write(bufferedWriter.body.Bytes())
+}
+
+func isNotModified(request *http.Request, etag string, lastModified string) bool {
+	// If-None-Match takes precedence over If-Modified-Since (RFC 9110, 13.1.3)
+	if ifNoneMatch := request.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+
+	ifModifiedSince := request.Header.Get("If-Modified-Since")
+	if ifModifiedSince == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// bufferedResponseWriter keeps status and body in memory so ConditionalGetMiddleware can decide
+// afterwards whether to send them at all. Headers are written directly to the wrapped writer.
+type bufferedResponseWriter struct {
+	gin.ResponseWriter
+	body   *bytes.Buffer
+	status int
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedResponseWriter) WriteHeaderNow() {
+	// status is written by ConditionalGetMiddleware
+}
+
+func (w *bufferedResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
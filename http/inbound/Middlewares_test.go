@@ -21,9 +21,11 @@ func TestAuthHandler_BffSession(t *testing.T) {
 
 	validSession := &session.Session{
 		ID:          validSessID,
+		UserID:      "oid-stefan",
 		Name:        "Stefan Blum",
 		Permissions: []string{"film.add", "bewertung.add"},
 		ExpiresAt:   time.Now().Add(time.Hour),
+		LastSeenAt:  time.Now(),
 	}
 
 	expiredSession := &session.Session{
@@ -41,7 +43,7 @@ func TestAuthHandler_BffSession(t *testing.T) {
 
 		w := httptest.NewRecorder()
 		_, r := gin.CreateTestContext(w)
-		r.GET("/test", NewAuthHandler(mockRepo, allowedRoles), func(ctx *gin.Context) {
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, allowedRoles), func(ctx *gin.Context) {
 			ctx.Status(http.StatusOK)
 		})
 
@@ -59,7 +61,7 @@ func TestAuthHandler_BffSession(t *testing.T) {
 
 		w := httptest.NewRecorder()
 		_, r := gin.CreateTestContext(w)
-		r.GET("/test", NewAuthHandler(mockRepo, allowedRoles), func(ctx *gin.Context) {
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, allowedRoles), func(ctx *gin.Context) {
 			ctx.Status(http.StatusOK)
 		})
 
@@ -79,9 +81,10 @@ func TestAuthHandler_BffSession(t *testing.T) {
 		w := httptest.NewRecorder()
 		_, r := gin.CreateTestContext(w)
 
-		var capturedName string
-		r.GET("/test", NewAuthHandler(mockRepo, allowedRoles), func(ctx *gin.Context) {
+		var capturedName, capturedUserId string
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, allowedRoles), func(ctx *gin.Context) {
 			capturedName, _ = ctx.Request.Context().Value(filmkritiken.Context_Username).(string)
+			capturedUserId, _ = ctx.Request.Context().Value(filmkritiken.Context_UserId).(string)
 			ctx.Status(http.StatusOK)
 		})
 
@@ -95,6 +98,154 @@ func TestAuthHandler_BffSession(t *testing.T) {
 		if capturedName != "Stefan Blum" {
 			t.Errorf("expected username 'Stefan Blum', got %q", capturedName)
 		}
+		if capturedUserId != "oid-stefan" {
+			t.Errorf("expected user id 'oid-stefan', got %q", capturedUserId)
+		}
+	})
+
+	t.Run("last seen of session is updated", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockSessionRepository(ctrl)
+		idleSession := *validSession
+		idleSession.LastSeenAt = time.Now().Add(-time.Hour)
+		mockRepo.EXPECT().FindSession(gomock.Any(), validSessID).Return(&idleSession, nil)
+		mockRepo.EXPECT().UpdateSessionLastSeen(gomock.Any(), validSessID, gomock.Any(), "192.0.2.1").Return(nil)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, allowedRoles), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: validSessID})
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("permissions are resolved on every request", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockSessionRepository(ctrl)
+		mockPermissionService := mocks.NewMockPermissionService(ctrl)
+		mockRepo.EXPECT().FindSession(gomock.Any(), validSessID).Return(validSession, nil)
+		// the session has no roles of the identity provider yet, its permissions are used instead
+		mockPermissionService.EXPECT().ResolvePermissions(gomock.Any(), "oid-stefan", validSession.Permissions).Return([]string{"admin.only"}, nil)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/test", NewAuthHandler(mockRepo, mockPermissionService, nil, []string{"admin.only"}), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: validSessID})
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("bearer token is restricted to its scopes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockPermissionService := mocks.NewMockPermissionService(ctrl)
+		mockTokenService := mocks.NewMockTokenService(ctrl)
+		token := &session.Token{ID: "t1", UserID: "oid-stefan", Scopes: []string{"bewertung.add"}, ExpiresAt: time.Now().Add(time.Hour)}
+		user := &session.User{ID: "oid-stefan", Name: "Stefan Blum", IdpRoles: []string{"film.add", "bewertung.add"}}
+		mockTokenService.EXPECT().Authenticate(gomock.Any(), "fkt_secret").Return(token, user, nil).Times(2)
+		mockPermissionService.EXPECT().ResolvePermissions(gomock.Any(), "oid-stefan", user.IdpRoles).Return([]string{"bewertung.add", "film.add"}, nil).Times(2)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		var capturedUserId string
+		r.GET("/bewertung", NewAuthHandler(nil, mockPermissionService, mockTokenService, []string{"bewertung.add"}), func(ctx *gin.Context) {
+			capturedUserId, _ = ctx.Request.Context().Value(filmkritiken.Context_UserId).(string)
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/film", NewAuthHandler(nil, mockPermissionService, mockTokenService, []string{"film.add"}), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/bewertung", nil)
+		req.Header.Set("Authorization", "Bearer fkt_secret")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || capturedUserId != "oid-stefan" {
+			t.Errorf("expected 200 for oid-stefan, got %d for %q", w.Code, capturedUserId)
+		}
+
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/film", nil)
+		req.Header.Set("Authorization", "Bearer fkt_secret")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 outside of the scopes, got %d", w.Code)
+		}
+	})
+
+	t.Run("removed role of the identity provider applies to existing token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockTokenService := mocks.NewMockTokenService(ctrl)
+		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		mockRoleRepo := mocks.NewMockRoleRepository(ctrl)
+		permissionService := session.NewPermissionService(mockUserRepo, mockRoleRepo)
+
+		token := &session.Token{ID: "t1", UserID: "oid-stefan", Scopes: []string{"film.add"}, ExpiresAt: time.Now().Add(time.Hour)}
+		user := &session.User{ID: "oid-stefan", Name: "Stefan Blum", IdpRoles: []string{"film.add"}}
+		mockTokenService.EXPECT().Authenticate(gomock.Any(), "fkt_secret").DoAndReturn(func(_ any, _ string) (*session.Token, *session.User, error) {
+			current := *user
+			return token, &current, nil
+		}).Times(2)
+		mockUserRepo.EXPECT().FindUser(gomock.Any(), "oid-stefan").DoAndReturn(func(_ any, _ string) (*session.User, error) {
+			current := *user
+			return &current, nil
+		}).Times(2)
+		mockRoleRepo.EXPECT().GetRoles(gomock.Any()).Return(nil, nil).Times(2)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/film", NewAuthHandler(nil, permissionService, mockTokenService, []string{"film.add"}), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/film", nil)
+		req.Header.Set("Authorization", "Bearer fkt_secret")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200 while the user has the role, got %d", w.Code)
+		}
+
+		// the role was removed in the identity provider and stored with the next login or refresh
+		user.IdpRoles = []string{}
+		w = httptest.NewRecorder()
+		req = httptest.NewRequest(http.MethodGet, "/film", nil)
+		req.Header.Set("Authorization", "Bearer fkt_secret")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("expected 403 after the role was removed, got %d", w.Code)
+		}
+	})
+
+	t.Run("session without user id returns 401", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockRepo := mocks.NewMockSessionRepository(ctrl)
+		legacySession := *validSession
+		legacySession.UserID = ""
+		mockRepo.EXPECT().FindSession(gomock.Any(), validSessID).Return(&legacySession, nil)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, allowedRoles), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: validSessID})
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", w.Code)
+		}
 	})
 
 	t.Run("valid session lacking required role returns 403", func(t *testing.T) {
@@ -104,7 +255,7 @@ func TestAuthHandler_BffSession(t *testing.T) {
 
 		w := httptest.NewRecorder()
 		_, r := gin.CreateTestContext(w)
-		r.GET("/test", NewAuthHandler(mockRepo, []string{"admin.only"}), func(ctx *gin.Context) {
+		r.GET("/test", NewAuthHandler(mockRepo, nil, nil, []string{"admin.only"}), func(ctx *gin.Context) {
 			ctx.Status(http.StatusOK)
 		})
 
@@ -116,3 +267,211 @@ func TestAuthHandler_BffSession(t *testing.T) {
 		}
 	})
 }
+
+func TestConditionalGetMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func() *gin.Engine {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/json", ConditionalGetMiddleware, func(ctx *gin.Context) {
+			ctx.JSON(http.StatusOK, gin.H{"titel": "Alien"})
+		})
+		r.GET("/image", ConditionalGetMiddleware, func(ctx *gin.Context) {
+			ctx.Writer.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+			ctx.Writer.WriteHeader(http.StatusOK)
+			_, _ = ctx.Writer.Write([]byte("image"))
+		})
+		r.GET("/notfound", ConditionalGetMiddleware, func(ctx *gin.Context) {
+			ctx.Writer.WriteHeader(http.StatusNotFound)
+			_, _ = ctx.Writer.WriteString("Filmkritiken konnten nicht gefunden werden.")
+		})
+		return r
+	}
+
+	t.Run("sets etag and returns body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		newRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/json", nil))
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w.Header().Get("ETag") == "" {
+			t.Error("expected ETag header")
+		}
+		if w.Body.String() != `{"titel":"Alien"}` {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("matching If-None-Match returns 304 without body", func(t *testing.T) {
+		r := newRouter()
+		first := httptest.NewRecorder()
+		r.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/json", nil))
+		etag := first.Header().Get("ETag")
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/json", nil)
+		req.Header.Set("If-None-Match", `"other", W/`+etag)
+		r.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("expected 304, got %d", w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("expected empty body, got %q", w.Body.String())
+		}
+		if w.Header().Get("ETag") != etag {
+			t.Errorf("expected ETag %s, got %s", etag, w.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("stale If-None-Match returns 200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/json", nil)
+		req.Header.Set("If-None-Match", `"stale"`)
+		newRouter().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("If-Modified-Since after Last-Modified returns 304", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/image", nil)
+		req.Header.Set("If-Modified-Since", "Tue, 03 Jan 2006 00:00:00 GMT")
+		newRouter().ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotModified {
+			t.Errorf("expected 304, got %d", w.Code)
+		}
+	})
+
+	t.Run("If-Modified-Since before Last-Modified returns 200", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/image", nil)
+		req.Header.Set("If-Modified-Since", "Sun, 01 Jan 2006 00:00:00 GMT")
+		newRouter().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w.Body.String() != "image" {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+	})
+
+	t.Run("error responses are passed through without etag", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/notfound", nil)
+		req.Header.Set("If-None-Match", "*")
+		newRouter().ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", w.Code)
+		}
+		if w.Header().Get("ETag") != "" {
+			t.Error("expected no ETag header")
+		}
+		if w.Body.String() != "Filmkritiken konnten nicht gefunden werden." {
+			t.Errorf("unexpected body %q", w.Body.String())
+		}
+	})
+}
+
+func TestCsrfMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	allowedOrigins := []string{"https://filmkritiken.example"}
+	csrfToken := generateCsrfToken()
+
+	serve := func(method string, configure func(req *http.Request)) int {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.Handle(method, "/test", NewCsrfMiddleware(allowedOrigins), func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+
+		req := httptest.NewRequest(method, "/test", nil)
+		configure(req)
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	withSession := func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: "session-123"})
+		req.AddCookie(&http.Cookie{Name: CsrfCookieName, Value: csrfToken})
+	}
+
+	t.Run("GET is not checked", func(t *testing.T) {
+		code := serve(http.MethodGet, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set("Origin", "https://evil.example")
+		})
+		if code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+	})
+
+	t.Run("valid token and origin returns 200", func(t *testing.T) {
+		code := serve(http.MethodPost, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set("Origin", "https://filmkritiken.example")
+			req.Header.Set(CsrfHeaderName, csrfToken)
+		})
+		if code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+	})
+
+	t.Run("missing token returns 403", func(t *testing.T) {
+		code := serve(http.MethodPut, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set("Origin", "https://filmkritiken.example")
+		})
+		if code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("token not matching the cookie returns 403", func(t *testing.T) {
+		code := serve(http.MethodPatch, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set(CsrfHeaderName, generateCsrfToken())
+		})
+		if code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("foreign origin returns 403", func(t *testing.T) {
+		code := serve(http.MethodDelete, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set("Origin", "https://evil.example")
+			req.Header.Set(CsrfHeaderName, csrfToken)
+		})
+		if code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("foreign referer returns 403", func(t *testing.T) {
+		code := serve(http.MethodPost, func(req *http.Request) {
+			withSession(req)
+			req.Header.Set("Referer", "https://evil.example/filmkritiken")
+			req.Header.Set(CsrfHeaderName, csrfToken)
+		})
+		if code != http.StatusForbidden {
+			t.Errorf("expected 403, got %d", code)
+		}
+	})
+
+	t.Run("bearer token is not checked", func(t *testing.T) {
+		code := serve(http.MethodPost, func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer fkt_secret")
+		})
+		if code != http.StatusOK {
+			t.Errorf("expected 200, got %d", code)
+		}
+	})
+}
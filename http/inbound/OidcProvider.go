@@ -0,0 +1,203 @@
+package inbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/caarlos0/env/v11"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+// EntraProviderName is the provider configured by the ENTRA_* variables
+const EntraProviderName = "entra"
+
+// OidcProviderConfig configures an OpenID Connect provider, read from OIDC_<NAME>_* variables. Claims are
+// lists of claim names, the first present one is used. Nested claims are separated by dots.
+type OidcProviderConfig struct {
+	DisplayName  string   `env:"DISPLAY_NAME"`
+	Issuer       string   `env:"ISSUER"`
+	ClientID     string   `env:"CLIENT_ID"`
+	ClientSecret string   `env:"CLIENT_SECRET,unset"`
+	RedirectURI  string   `env:"REDIRECT_URI"`
+	Scopes       []string `env:"SCOPES" envDefault:"openid,profile"`
+	UserIdClaims []string `env:"USER_ID_CLAIMS" envDefault:"sub"`
+	NameClaims   []string `env:"NAME_CLAIMS" envDefault:"name,preferred_username"`
+	// RolesClaim is e.g. realm_access.roles for Keycloak
+	RolesClaim string `env:"ROLES_CLAIM" envDefault:"roles"`
+	// UserIdPrefix keeps the ids of different providers apart, defaults to "<name>:"
+	UserIdPrefix string `env:"USER_ID_PREFIX"`
+}
+
+type (
+	oidcDiscoveryDocument struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		JwksURI               string `json:"jwks_uri"`
+	}
+
+	// oidcProvider loads its endpoints on first use, so the backend starts even if a provider is down
+	oidcProvider struct {
+		name        string
+		config      *OidcProviderConfig
+		httpClient  *http.Client
+		mutex       sync.Mutex
+		oauthConfig *oauth2.Config
+		verifier    *idTokenVerifier
+	}
+)
+
+// LoadOidcProviderConfigs reads the configuration of every provider listed in OIDC_PROVIDERS.
+func LoadOidcProviderConfigs(authConfig *AuthConfig) error {
+	authConfig.Providers = make(map[string]*OidcProviderConfig, len(authConfig.OidcProviders))
+	for _, name := range authConfig.OidcProviders {
+		name = strings.ToLower(strings.TrimSpace(name))
+		providerConfig := &OidcProviderConfig{}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		if err := env.ParseWithOptions(providerConfig, env.Options{Prefix: prefix}); err != nil {
+			return err
+		}
+		if providerConfig.Issuer == "" || providerConfig.ClientID == "" {
+			return fmt.Errorf("%sISSUER and %sCLIENT_ID are required", prefix, prefix)
+		}
+		if providerConfig.UserIdPrefix == "" {
+			providerConfig.UserIdPrefix = name + ":"
+		}
+		authConfig.Providers[name] = providerConfig
+	}
+	return nil
+}
+
+// entraProviderConfig keeps the ENTRA_* variables working. User ids are not prefixed, as they were stored
+// before other providers existed.
+func entraProviderConfig(authConfig *AuthConfig) *OidcProviderConfig {
+	return &OidcProviderConfig{
+		DisplayName: "Microsoft",
+		// tokens of a single tenant app are issued by the tenant, "common" or "organizations" are not supported
+		Issuer:       "https://login.microsoftonline.com/" + authConfig.EntraTenantID + "/v2.0",
+		ClientID:     authConfig.EntraClientID,
+		ClientSecret: authConfig.EntraClientSecret,
+		RedirectURI:  authConfig.EntraRedirectURI,
+		Scopes:       []string{"openid", "profile", "offline_access"},
+		// oid is the same for a user in all apps of the tenant
+		UserIdClaims: []string{"oid", "sub"},
+		NameClaims:   []string{"name", "preferred_username"},
+		RolesClaim:   "roles",
+	}
+}
+
+func newOidcProvider(name string, config *OidcProviderConfig, httpClient *http.Client) *oidcProvider {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: jwksTimeout}
+	}
+	return &oidcProvider{name: name, config: config, httpClient: httpClient}
+}
+
+func (p *oidcProvider) displayName() string {
+	if p.config.DisplayName != "" {
+		return p.config.DisplayName
+	}
+	return p.name
+}
+
+// discover returns the OAuth config and the ID token verifier from the discovery document of the issuer.
+func (p *oidcProvider) discover(ctx context.Context) (*oauth2.Config, *idTokenVerifier, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.oauthConfig != nil {
+		return p.oauthConfig, p.verifier, nil
+	}
+
+	discoveryURL := strings.TrimRight(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("discovery of %s failed with status %d", p.name, resp.StatusCode)
+	}
+
+	document := &oidcDiscoveryDocument{}
+	if err := json.NewDecoder(resp.Body).Decode(document); err != nil {
+		return nil, nil, err
+	}
+	if document.Issuer != p.config.Issuer {
+		return nil, nil, fmt.Errorf("discovery of %s returned issuer %s", p.name, document.Issuer)
+	}
+	if document.AuthorizationEndpoint == "" || document.TokenEndpoint == "" || document.JwksURI == "" {
+		return nil, nil, fmt.Errorf("discovery document of %s is incomplete", p.name)
+	}
+
+	clientSecret := p.config.ClientSecret
+	if len(clientSecret) > 0 && (clientSecret[0] == '<' || clientSecret == "unset") {
+		clientSecret = ""
+	}
+	p.oauthConfig = &oauth2.Config{
+		ClientID:     p.config.ClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  p.config.RedirectURI,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  document.AuthorizationEndpoint,
+			TokenURL: document.TokenEndpoint,
+		},
+		Scopes: p.config.Scopes,
+	}
+	p.verifier = newIdTokenVerifier(document.Issuer, p.config.ClientID, document.JwksURI, p.httpClient)
+	return p.oauthConfig, p.verifier, nil
+}
+
+// userFromClaims maps the claims of a verified ID token to the user id, display name and roles.
+func (p *oidcProvider) userFromClaims(claims jwt.MapClaims) (string, string, []string) {
+	userID := ""
+	if id := firstStringClaim(claims, p.config.UserIdClaims); id != "" {
+		userID = p.config.UserIdPrefix + id
+	}
+
+	name := firstStringClaim(claims, p.config.NameClaims)
+	if name == "" {
+		name = "Filmtreff-Mitglied"
+	}
+
+	roles := make([]string, 0)
+	if rolesRaw, ok := claimValue(claims, p.config.RolesClaim).([]interface{}); ok {
+		for _, r := range rolesRaw {
+			if rStr, ok := r.(string); ok {
+				roles = append(roles, rStr)
+			}
+		}
+	}
+	return userID, name, roles
+}
+
+func firstStringClaim(claims jwt.MapClaims, paths []string) string {
+	for _, path := range paths {
+		if value, ok := claimValue(claims, strings.TrimSpace(path)).(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// claimValue resolves dotted paths like realm_access.roles
+func claimValue(claims jwt.MapClaims, path string) any {
+	var value any = map[string]any(claims)
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
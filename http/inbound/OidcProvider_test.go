@@ -0,0 +1,431 @@
+package inbound
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+)
+
+// mockIdp serves discovery, JWKS and a token endpoint returning an ID token with the nonce of the login. Refresh
+// tokens are rotated like by Keycloak: every refresh returns a new one and a used one is refused.
+type mockIdp struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	nonce  string
+	claims jwt.MapClaims
+	// refreshError is returned for the refresh token grant, if set
+	refreshError string
+	// onRefresh is called on every refresh token grant, if set
+	onRefresh func()
+
+	mutex             sync.Mutex
+	refreshCalls      int
+	usedRefreshTokens map[string]bool
+}
+
+func newMockIdp(t *testing.T) *mockIdp {
+	idp := &mockIdp{key: generateTestKey(t), usedRefreshTokens: make(map[string]bool)}
+	jwks := &testJwks{keys: map[string]*rsa.PrivateKey{"kc-1": idp.key}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(&oidcDiscoveryDocument{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/auth",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksURI:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.Handle("/jwks", jwks)
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		refreshToken := "rt-login"
+		if r.FormValue("grant_type") == "refresh_token" {
+			var refreshError string
+			refreshToken, refreshError = idp.refresh(r.FormValue("refresh_token"))
+			if refreshError != "" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(map[string]any{"error": refreshError})
+				return
+			}
+		}
+		claims := jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   "filmkritiken",
+			"nonce": idp.nonce,
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "kc-1"
+		idToken, _ := token.SignedString(idp.key)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "at", "token_type": "Bearer", "id_token": idToken, "refresh_token": refreshToken})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// refresh returns the rotated refresh token or the error for the refresh token grant
+func (idp *mockIdp) refresh(refreshToken string) (string, string) {
+	if idp.onRefresh != nil {
+		idp.onRefresh()
+	}
+	idp.mutex.Lock()
+	defer idp.mutex.Unlock()
+	idp.refreshCalls++
+	if idp.refreshError != "" {
+		return "", idp.refreshError
+	}
+	if idp.usedRefreshTokens[refreshToken] {
+		return "", "invalid_grant"
+	}
+	idp.usedRefreshTokens[refreshToken] = true
+	return fmt.Sprintf("rt-%d", idp.refreshCalls), ""
+}
+
+func TestOidcProvider_UserFromClaims(t *testing.T) {
+	provider := newOidcProvider("keycloak", &OidcProviderConfig{
+		UserIdClaims: []string{"sub"},
+		NameClaims:   []string{"name", "preferred_username"},
+		RolesClaim:   "realm_access.roles",
+		UserIdPrefix: "keycloak:",
+	}, nil)
+
+	userID, name, roles := provider.userFromClaims(jwt.MapClaims{
+		"sub":                "123",
+		"preferred_username": "stefan",
+		"realm_access":       map[string]any{"roles": []any{"film.add", "offline_access"}},
+	})
+
+	if userID != "keycloak:123" || name != "stefan" || !slices.Equal(roles, []string{"film.add", "offline_access"}) {
+		t.Errorf("unexpected user %q %q %v", userID, name, roles)
+	}
+}
+
+func TestBffAuthHandler_LoginWithOidcProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	idp := newMockIdp(t)
+	idp.claims = jwt.MapClaims{"sub": "123", "name": "Stefan Blum", "roles": []string{"bewertung.add"}}
+
+	ctrl := gomock.NewController(t)
+	sessionRepo := mocks.NewMockSessionRepository(ctrl)
+	userRepo := mocks.NewMockUserRepository(ctrl)
+	permissionService := mocks.NewMockPermissionService(ctrl)
+
+	config := &AuthConfig{
+		FrontendURL:         "http://frontend",
+		SessionDurationDays: 7,
+		OidcProviders:       []string{"mock"},
+		Providers: map[string]*OidcProviderConfig{"mock": {
+			Issuer:       idp.server.URL,
+			ClientID:     "filmkritiken",
+			RedirectURI:  "http://backend/auth/callback",
+			Scopes:       []string{"openid"},
+			UserIdClaims: []string{"sub"},
+			NameClaims:   []string{"name"},
+			RolesClaim:   "roles",
+			UserIdPrefix: "mock:",
+		}},
+	}
+	handler := NewBffAuthHandler(config, sessionRepo, userRepo, permissionService, nil, nil)
+	r := gin.New()
+	r.GET("/auth/login", handler.handleLogin)
+	r.GET("/auth/callback", handler.handleCallback)
+
+	t.Run("unknown provider is rejected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login?provider=entra", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("login and callback create a session", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/login?provider=mock", nil))
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected redirect, got %d", w.Code)
+		}
+		location, _ := url.Parse(w.Header().Get("Location"))
+		if location.Host != idp.server.Listener.Addr().String() || location.Path != "/auth" {
+			t.Fatalf("expected redirect to the provider, got %s", location)
+		}
+		idp.nonce = location.Query().Get("nonce")
+
+		userRepo.EXPECT().FindUser(gomock.Any(), "mock:123").Return(nil, errors.NewNotFoundErrorFromString("Benutzer nicht gefunden."))
+		userRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+		permissionService.EXPECT().ResolvePermissions(gomock.Any(), "mock:123", []string{"bewertung.add"}).Return([]string{"bewertung.add"}, nil)
+		var saved *session.Session
+		sessionRepo.EXPECT().SaveSession(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, s *session.Session) error {
+			saved = s
+			return nil
+		})
+
+		callback := httptest.NewRequest(http.MethodGet, "/auth/callback?code=abc&state="+location.Query().Get("state"), nil)
+		for _, cookie := range w.Result().Cookies() {
+			callback.AddCookie(cookie)
+		}
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, callback)
+
+		if w.Code != http.StatusFound {
+			t.Fatalf("expected redirect to the frontend, got %d: %s", w.Code, w.Body.String())
+		}
+		if saved == nil || saved.UserID != "mock:123" || saved.Provider != "mock" || saved.Name != "Stefan Blum" {
+			t.Errorf("unexpected session %+v", saved)
+		}
+	})
+}
+
+// memorySessions backs the session repository mock of the refresh tests, so concurrent refreshes see each
+// other's changes
+type memorySessions struct {
+	mutex    sync.Mutex
+	sessions map[string]session.Session
+}
+
+func (m *memorySessions) store(sess *session.Session) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.sessions[sess.ID] = *sess
+}
+
+func (m *memorySessions) delete(sessionID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	delete(m.sessions, sessionID)
+}
+
+func (m *memorySessions) find(_ context.Context, sessionID string) (*session.Session, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	sess, ok := m.sessions[sessionID]
+	if !ok {
+		return nil, errors.NewNotFoundErrorFromString("Session nicht gefunden.")
+	}
+	return &sess, nil
+}
+
+func (m *memorySessions) updateRefreshed(_ context.Context, sess *session.Session, previousRefreshToken string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	stored, ok := m.sessions[sess.ID]
+	if !ok || stored.RefreshToken != previousRefreshToken {
+		return false, nil
+	}
+	m.sessions[sess.ID] = *sess
+	return true, nil
+}
+
+type refreshTest struct {
+	idp          *mockIdp
+	handler      *BffAuthHandler
+	sessions     *memorySessions
+	tokenService *mocks.MockTokenService
+	savedUsers   chan *session.User
+}
+
+func newRefreshTest(t *testing.T) *refreshTest {
+	idp := newMockIdp(t)
+	idp.claims = jwt.MapClaims{"sub": "123", "name": "Stefan Blum", "roles": []string{"film.add"}}
+
+	ctrl := gomock.NewController(t)
+	sessionRepo := mocks.NewMockSessionRepository(ctrl)
+	userRepo := mocks.NewMockUserRepository(ctrl)
+	permissionService := mocks.NewMockPermissionService(ctrl)
+	tokenService := mocks.NewMockTokenService(ctrl)
+
+	sessions := &memorySessions{sessions: make(map[string]session.Session)}
+	sessionRepo.EXPECT().FindSession(gomock.Any(), gomock.Any()).DoAndReturn(sessions.find).AnyTimes()
+	sessionRepo.EXPECT().UpdateRefreshedSession(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(sessions.updateRefreshed).AnyTimes()
+	savedUsers := make(chan *session.User, 10)
+	userRepo.EXPECT().FindUser(gomock.Any(), "mock:123").Return(&session.User{ID: "mock:123", Name: "Stefan Blum", IdpRoles: []string{"bewertung.add"}}, nil).AnyTimes()
+	userRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, user *session.User) error {
+		savedUsers <- user
+		return nil
+	}).AnyTimes()
+	permissionService.EXPECT().ResolvePermissions(gomock.Any(), "mock:123", []string{"film.add"}).Return([]string{"film.add"}, nil).AnyTimes()
+
+	config := &AuthConfig{
+		SessionEncryptionKey:   base64.StdEncoding.EncodeToString(make([]byte, 32)),
+		SessionRefreshInterval: time.Minute,
+		OidcProviders:          []string{"mock"},
+		Providers: map[string]*OidcProviderConfig{"mock": {
+			Issuer:       idp.server.URL,
+			ClientID:     "filmkritiken",
+			UserIdClaims: []string{"sub"},
+			NameClaims:   []string{"name"},
+			RolesClaim:   "roles",
+			UserIdPrefix: "mock:",
+		}},
+	}
+	handler := NewBffAuthHandler(config, sessionRepo, userRepo, permissionService, tokenService, nil)
+	return &refreshTest{idp: idp, handler: handler, sessions: sessions, tokenService: tokenService, savedUsers: savedUsers}
+}
+
+// newSession stores a session due for a refresh and returns a copy of it, like loaded by a request
+func (rt *refreshTest) newSession(t *testing.T) *session.Session {
+	encrypted, err := rt.handler.refreshTokenCipher.Encrypt("rt-login", "mock:123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sess := &session.Session{ID: "session-1", UserID: "mock:123", Provider: "mock", Name: "Stefan Blum", Roles: []string{"bewertung.add"}, RefreshToken: encrypted}
+	rt.sessions.store(sess)
+	stored := *sess
+	return &stored
+}
+
+func (rt *refreshTest) refreshToken(t *testing.T, sess *session.Session) string {
+	refreshToken, err := rt.handler.refreshTokenCipher.Decrypt(sess.RefreshToken, "mock:123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return refreshToken
+}
+
+func TestBffAuthHandler_RefreshIdentity(t *testing.T) {
+	t.Run("roles of the provider are updated", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		sess := rt.newSession(t)
+
+		// when
+		valid := rt.handler.refreshIdentity(context.Background(), sess)
+
+		// then
+		if !valid {
+			t.Fatal("expected session to stay valid")
+		}
+		if !slices.Equal(sess.Roles, []string{"film.add"}) || !slices.Equal(sess.Permissions, []string{"film.add"}) || sess.RefreshedAt.IsZero() {
+			t.Errorf("unexpected session %+v", sess)
+		}
+		stored, _ := rt.sessions.find(context.Background(), "session-1")
+		if refreshToken := rt.refreshToken(t, stored); refreshToken != "rt-1" {
+			t.Errorf("expected rotated refresh token to be stored, got %q", refreshToken)
+		}
+		// personal tokens are authorized with the roles stored on the user
+		if savedUser := <-rt.savedUsers; !slices.Equal(savedUser.IdpRoles, []string{"film.add"}) {
+			t.Errorf("expected roles of the provider on the user, got %+v", savedUser)
+		}
+	})
+
+	t.Run("refused refresh token ends the session and revokes the tokens", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		rt.idp.refreshError = "invalid_grant"
+		rt.tokenService.EXPECT().RevokeTokens(gomock.Any(), "mock:123").Return(int64(2), nil)
+
+		// when
+		valid := rt.handler.refreshIdentity(context.Background(), rt.newSession(t))
+
+		// then
+		if valid {
+			t.Error("expected session to end")
+		}
+	})
+
+	t.Run("unavailable provider keeps the session", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		rt.idp.refreshError = "temporarily_unavailable"
+
+		// when
+		valid := rt.handler.refreshIdentity(context.Background(), rt.newSession(t))
+
+		// then
+		if !valid {
+			t.Error("expected session to stay valid")
+		}
+	})
+
+	t.Run("concurrent requests of a session refresh once", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		first := rt.newSession(t)
+		second := *first
+
+		// when
+		var wg sync.WaitGroup
+		valid := make([]bool, 2)
+		for i, sess := range []*session.Session{first, &second} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				valid[i] = rt.handler.refreshIdentity(context.Background(), sess)
+			}()
+		}
+		wg.Wait()
+
+		// then (a second use of the rotated refresh token would be refused and revoke the tokens)
+		if !valid[0] || !valid[1] {
+			t.Errorf("expected both requests to keep the session, got %v", valid)
+		}
+		if rt.idp.refreshCalls != 1 {
+			t.Errorf("expected one refresh at the provider, got %d", rt.idp.refreshCalls)
+		}
+		if rt.refreshToken(t, first) != "rt-1" || rt.refreshToken(t, &second) != "rt-1" {
+			t.Errorf("expected both requests to see the rotated refresh token")
+		}
+	})
+
+	t.Run("refresh token used by another instance keeps the session", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		sess := rt.newSession(t)
+		refreshedElsewhere := *sess
+		refreshedElsewhere.RefreshToken, _ = rt.handler.refreshTokenCipher.Encrypt("rt-other", "mock:123")
+		refreshedElsewhere.RefreshedAt = time.Now()
+		rt.idp.onRefresh = func() { rt.sessions.store(&refreshedElsewhere) }
+		rt.idp.refreshError = "invalid_grant"
+
+		// when
+		valid := rt.handler.refreshIdentity(context.Background(), sess)
+
+		// then
+		if !valid {
+			t.Fatal("expected session to stay valid")
+		}
+		if refreshToken := rt.refreshToken(t, sess); refreshToken != "rt-other" {
+			t.Errorf("expected refresh token of the other instance, got %q", refreshToken)
+		}
+	})
+
+	t.Run("session deleted during the refresh is not restored", func(t *testing.T) {
+		// given
+		rt := newRefreshTest(t)
+		sess := rt.newSession(t)
+		rt.idp.onRefresh = func() { rt.sessions.delete("session-1") }
+
+		// when
+		valid := rt.handler.refreshIdentity(context.Background(), sess)
+
+		// then
+		if valid {
+			t.Error("expected session to end")
+		}
+		if _, err := rt.sessions.find(context.Background(), "session-1"); err == nil {
+			t.Error("expected session to stay deleted")
+		}
+	})
+}
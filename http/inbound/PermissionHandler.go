@@ -0,0 +1,137 @@
+package inbound
+
+import (
+	"net/http"
+
+	domainErrors "github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type (
+	SaveRoleRequest struct {
+		Permissions []string `json:"permissions"`
+	}
+
+	SetUserRolesRequest struct {
+		Roles []string `json:"roles"`
+	}
+
+	permissionHandler struct {
+		permissionService session.PermissionService
+		sessionRepo       session.SessionRepository
+		tokenService      session.TokenService
+	}
+)
+
+func NewPermissionHandler(permissionService session.PermissionService, sessionRepo session.SessionRepository, tokenService session.TokenService) *permissionHandler {
+	return &permissionHandler{
+		permissionService: permissionService,
+		sessionRepo:       sessionRepo,
+		tokenService:      tokenService,
+	}
+}
+
+func (h *permissionHandler) handleGetRoles(ginCtx *gin.Context) {
+	roles, err := h.permissionService.GetRoles(ginCtx.Request.Context())
+	if err != nil {
+		writePermissionError(ginCtx, "could not load roles", err)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, roles)
+}
+
+func (h *permissionHandler) handleSaveRole(ginCtx *gin.Context) {
+	req := &SaveRoleRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Errorf("could not map json to SaveRoleRequest: %v", err)
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.permissionService.SaveRole(ginCtx.Request.Context(), ginCtx.Param("role"), req.Permissions)
+	if err != nil {
+		writePermissionError(ginCtx, "could not save role", err)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, role)
+}
+
+func (h *permissionHandler) handleDeleteRole(ginCtx *gin.Context) {
+	err := h.permissionService.DeleteRole(ginCtx.Request.Context(), ginCtx.Param("role"))
+	if err != nil {
+		writePermissionError(ginCtx, "could not delete role", err)
+		return
+	}
+
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func (h *permissionHandler) handleGetUsers(ginCtx *gin.Context) {
+	users, err := h.permissionService.GetUsers(ginCtx.Request.Context())
+	if err != nil {
+		writePermissionError(ginCtx, "could not load users", err)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, users)
+}
+
+func (h *permissionHandler) handleSetUserRoles(ginCtx *gin.Context) {
+	req := &SetUserRolesRequest{}
+	err := ginCtx.ShouldBindJSON(req)
+	if err != nil {
+		log.Errorf("could not map json to SetUserRolesRequest: %v", err)
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.permissionService.SetUserRoles(ginCtx.Request.Context(), ginCtx.Param("userId"), req.Roles)
+	if err != nil {
+		writePermissionError(ginCtx, "could not set roles of user", err)
+		return
+	}
+
+	ginCtx.JSON(http.StatusOK, user)
+}
+
+// handleRevokeUserSessions logs a user out of all browsers and revokes its personal tokens, e.g. after a lost
+// device.
+func (h *permissionHandler) handleRevokeUserSessions(ginCtx *gin.Context) {
+	userID := ginCtx.Param("userId")
+	if userID == "" {
+		ginCtx.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	count, err := h.sessionRepo.DeleteSessions(ginCtx.Request.Context(), userID)
+	if err != nil {
+		writePermissionError(ginCtx, "could not revoke sessions of user", err)
+		return
+	}
+	tokenCount, err := h.tokenService.RevokeTokens(ginCtx.Request.Context(), userID)
+	if err != nil {
+		writePermissionError(ginCtx, "could not revoke tokens of user", err)
+		return
+	}
+
+	log.Infof("revoked %d sessions and %d tokens of user %s", count, tokenCount, userID)
+	ginCtx.Writer.WriteHeader(http.StatusNoContent)
+}
+
+func writePermissionError(ginCtx *gin.Context, message string, err error) {
+	switch err.(type) {
+	case *domainErrors.InvalidInputError:
+		ginCtx.Writer.WriteHeader(http.StatusBadRequest)
+	case *domainErrors.NotFoundError:
+		ginCtx.Writer.WriteHeader(http.StatusNotFound)
+	default:
+		log.Errorf("%s: %v", message, err)
+		ginCtx.Writer.WriteHeader(http.StatusInternalServerError)
+	}
+	_, _ = ginCtx.Writer.WriteString(err.Error())
+}
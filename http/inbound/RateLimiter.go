@@ -0,0 +1,160 @@
+package inbound
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateLimitCleanupInterval = time.Minute
+	// rateLimitIdleTimeout after which the bucket of a client is dropped, it is full again by then
+	rateLimitIdleTimeout = 10 * time.Minute
+)
+
+// RateLimitConfig sets a token bucket per client for each kind of route, 0 requests per minute disables it.
+type RateLimitConfig struct {
+	ReadPerMinute   int `env:"RATE_LIMIT_READ_PER_MINUTE" envDefault:"600"`
+	ReadBurst       int `env:"RATE_LIMIT_READ_BURST" envDefault:"120"`
+	WritePerMinute  int `env:"RATE_LIMIT_WRITE_PER_MINUTE" envDefault:"60"`
+	WriteBurst      int `env:"RATE_LIMIT_WRITE_BURST" envDefault:"20"`
+	AuthPerMinute   int `env:"RATE_LIMIT_AUTH_PER_MINUTE" envDefault:"20"`
+	AuthBurst       int `env:"RATE_LIMIT_AUTH_BURST" envDefault:"10"`
+	UploadPerMinute int `env:"RATE_LIMIT_UPLOAD_PER_MINUTE" envDefault:"10"`
+	UploadBurst     int `env:"RATE_LIMIT_UPLOAD_BURST" envDefault:"3"`
+	// FailedAuthPerMinute limits the requests of an IP answered with 401 or 403, e.g. guessed tokens
+	FailedAuthPerMinute int `env:"RATE_LIMIT_FAILED_AUTH_PER_MINUTE" envDefault:"10"`
+	FailedAuthBurst     int `env:"RATE_LIMIT_FAILED_AUTH_BURST" envDefault:"20"`
+}
+
+type rateLimiter struct {
+	budget  string
+	limit   rate.Limit
+	burst   int
+	mu      sync.Mutex
+	clients map[string]*rateLimitClient
+	// lastCleanup of idle clients, so the map does not grow with every IP ever seen
+	lastCleanup time.Time
+}
+
+type rateLimitClient struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimitMiddleware limits the requests of each client to perMinute with bursts of burst requests.
+// Clients are identified by the user of the request (if an auth handler ran before) or the client IP,
+// X-Forwarded-For is only used from the trusted proxies of gin. Rejected requests get 429 with Retry-After.
+func NewRateLimitMiddleware(budget string, perMinute int, burst int) func(ginCtx *gin.Context) {
+	limiter := newRateLimiter(budget, perMinute, burst)
+	if limiter == nil {
+		return NewEmptyHandler()
+	}
+	return limiter.handle
+}
+
+// NewFailedAuthLimitMiddleware limits the requests of each client IP answered with 401 or 403 to perMinute with
+// bursts of burst requests. It runs before the authentication, as failed requests have no user to be limited
+// by. Only failures use up the budget, but once it is used up every request of the IP gets 429.
+func NewFailedAuthLimitMiddleware(perMinute int, burst int) func(ginCtx *gin.Context) {
+	limiter := newRateLimiter("failed-auth", perMinute, burst)
+	if limiter == nil {
+		return NewEmptyHandler()
+	}
+	return limiter.handleFailedAuth
+}
+
+func newRateLimiter(budget string, perMinute int, burst int) *rateLimiter {
+	if perMinute <= 0 {
+		log.Warnf("rate limit %s disabled", budget)
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		budget:  budget,
+		limit:   rate.Limit(float64(perMinute) / 60),
+		burst:   burst,
+		clients: make(map[string]*rateLimitClient),
+	}
+}
+
+func (l *rateLimiter) handle(ginCtx *gin.Context) {
+	key := rateLimitKey(ginCtx)
+	now := time.Now()
+
+	reservation := l.client(key, now).ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return
+	}
+	// the request is rejected, so it must not use up a token
+	reservation.CancelAt(now)
+	l.reject(ginCtx, key, delay)
+}
+
+func (l *rateLimiter) handleFailedAuth(ginCtx *gin.Context) {
+	key := "ip:" + ginCtx.ClientIP()
+	now := time.Now()
+	limiter := l.client(key, now)
+
+	// only checked here, the token is used up below if the authentication fails
+	reservation := limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+	if delay > 0 {
+		l.reject(ginCtx, key, delay)
+		return
+	}
+
+	ginCtx.Next()
+
+	if status := ginCtx.Writer.Status(); status == http.StatusUnauthorized || status == http.StatusForbidden {
+		limiter.Allow()
+	}
+}
+
+func (l *rateLimiter) reject(ginCtx *gin.Context, key string, delay time.Duration) {
+	rateLimitRejectionCounter.WithLabelValues(l.budget).Inc()
+	log.Warnf("rate limit %s exceeded by %s", l.budget, key)
+	ginCtx.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	ginCtx.AbortWithStatus(http.StatusTooManyRequests)
+}
+
+func (l *rateLimiter) client(key string, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastCleanup) > rateLimitCleanupInterval {
+		for k, c := range l.clients {
+			if now.Sub(c.lastSeen) > rateLimitIdleTimeout {
+				delete(l.clients, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	c, ok := l.clients[key]
+	if !ok {
+		c = &rateLimitClient{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+	return c.limiter
+}
+
+func rateLimitKey(ginCtx *gin.Context) string {
+	if userID, ok := ginCtx.Request.Context().Value(filmkritiken.Context_UserId).(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return "ip:" + ginCtx.ClientIP()
+}
@@ -0,0 +1,184 @@
+package inbound
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(middlewares ...gin.HandlerFunc) *gin.Engine {
+		r := gin.New()
+		handlers := append(middlewares, func(ctx *gin.Context) {
+			ctx.Status(http.StatusOK)
+		})
+		r.GET("/test", handlers...)
+		return r
+	}
+	request := func(r *gin.Engine, remoteAddr string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = remoteAddr
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("requests above the burst return 429 with Retry-After", func(t *testing.T) {
+		r := newRouter(NewRateLimitMiddleware("test", 1, 2))
+
+		for i := 0; i < 2; i++ {
+			if w := request(r, "192.0.2.1:1234", nil); w.Code != http.StatusOK {
+				t.Fatalf("expected 200 for request %d, got %d", i, w.Code)
+			}
+		}
+		w := request(r, "192.0.2.1:1234", nil)
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+		if retryAfter := w.Header().Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+			t.Errorf("expected Retry-After, got %q", retryAfter)
+		}
+	})
+
+	t.Run("clients have separate budgets", func(t *testing.T) {
+		r := newRouter(NewRateLimitMiddleware("test", 1, 1))
+
+		if w := request(r, "192.0.2.1:1234", nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := request(r, "192.0.2.2:1234", nil); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for another client, got %d", w.Code)
+		}
+	})
+
+	t.Run("X-Forwarded-For of untrusted clients is ignored", func(t *testing.T) {
+		r := newRouter(NewRateLimitMiddleware("test", 1, 1))
+		_ = r.SetTrustedProxies(nil)
+
+		if w := request(r, "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := request(r, "192.0.2.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.2"}); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected 429 for spoofed client IP, got %d", w.Code)
+		}
+	})
+
+	t.Run("X-Forwarded-For of trusted proxies is used", func(t *testing.T) {
+		r := newRouter(NewRateLimitMiddleware("test", 1, 1))
+		_ = r.SetTrustedProxies([]string{"10.0.0.0/8"})
+
+		if w := request(r, "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := request(r, "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "198.51.100.2"}); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for another client behind the proxy, got %d", w.Code)
+		}
+	})
+
+	t.Run("authenticated users are limited by user instead of IP", func(t *testing.T) {
+		userID := "oid-stefan"
+		setUser := func(ctx *gin.Context) {
+			newCtx := context.WithValue(ctx.Request.Context(), filmkritiken.Context_UserId, userID)
+			ctx.Request = ctx.Request.WithContext(newCtx)
+		}
+		r := newRouter(setUser, NewRateLimitMiddleware("test", 1, 1))
+
+		if w := request(r, "192.0.2.1:1234", nil); w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		if w := request(r, "192.0.2.2:1234", nil); w.Code != http.StatusTooManyRequests {
+			t.Errorf("expected 429 for the same user from another IP, got %d", w.Code)
+		}
+		userID = "oid-other"
+		if w := request(r, "192.0.2.1:1234", nil); w.Code != http.StatusOK {
+			t.Errorf("expected 200 for another user, got %d", w.Code)
+		}
+	})
+
+	t.Run("disabled rate limit allows all requests", func(t *testing.T) {
+		r := newRouter(NewRateLimitMiddleware("test", 0, 0))
+
+		for i := 0; i < 5; i++ {
+			if w := request(r, "192.0.2.1:1234", nil); w.Code != http.StatusOK {
+				t.Fatalf("expected 200, got %d", w.Code)
+			}
+		}
+	})
+}
+
+func TestFailedAuthLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(r *gin.Engine, bearerToken string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	newRouter := func(t *testing.T) *gin.Engine {
+		ctrl := gomock.NewController(t)
+		mockPermissionService := mocks.NewMockPermissionService(ctrl)
+		mockTokenService := mocks.NewMockTokenService(ctrl)
+		user := &session.User{ID: "oid-stefan", IdpRoles: []string{"film.add"}}
+		token := &session.Token{ID: "t1", UserID: "oid-stefan", Scopes: []string{"film.add"}, ExpiresAt: time.Now().Add(time.Hour)}
+		mockTokenService.EXPECT().Authenticate(gomock.Any(), "fkt_valid").Return(token, user, nil).AnyTimes()
+		mockTokenService.EXPECT().Authenticate(gomock.Any(), gomock.Not("fkt_valid")).Return(nil, nil, errors.NewNotFoundErrorFromString("Token nicht gefunden.")).AnyTimes()
+		mockPermissionService.EXPECT().ResolvePermissions(gomock.Any(), "oid-stefan", gomock.Any()).Return([]string{"film.add"}, nil).AnyTimes()
+
+		r := gin.New()
+		r.GET("/test",
+			NewFailedAuthLimitMiddleware(1, 2),
+			NewAuthHandler(nil, mockPermissionService, mockTokenService, []string{"film.add"}),
+			func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+		return r
+	}
+
+	t.Run("repeated bad bearer tokens get 429", func(t *testing.T) {
+		r := newRouter(t)
+
+		for i := 0; i < 2; i++ {
+			if w := request(r, fmt.Sprintf("fkt_guess%d", i)); w.Code != http.StatusUnauthorized {
+				t.Fatalf("expected 401 for request %d, got %d", i, w.Code)
+			}
+		}
+		w := request(r, "fkt_guess2")
+		if w.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", w.Code)
+		}
+		if retryAfter := w.Header().Get("Retry-After"); retryAfter == "" || retryAfter == "0" {
+			t.Errorf("expected Retry-After, got %q", retryAfter)
+		}
+	})
+
+	t.Run("successful authentications do not use up the budget", func(t *testing.T) {
+		r := newRouter(t)
+
+		for i := 0; i < 5; i++ {
+			if w := request(r, "fkt_valid"); w.Code != http.StatusOK {
+				t.Fatalf("expected 200 for request %d, got %d", i, w.Code)
+			}
+		}
+		if w := request(r, "fkt_guess"); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401, got %d", w.Code)
+		}
+	})
+}
@@ -17,31 +17,50 @@ type ServerConfig struct {
 	CorsAllowOrigins        []string `env:"CORS_ALLOW_ORIGINS" envDefault:"https://filmkritiken.marsrover.418-teapot.de"`
 	MetricsEndpointUser     string   `env:"METRICS_ENDPOINT_USER"`
 	MetricsEndpointPassword string   `env:"METRICS_ENDPOINT_PASSWORD"`
+	// TrustedProxies (IPs or CIDRs) whose X-Forwarded-For header is used as client IP
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+	RateLimit      RateLimitConfig
 }
 
 var inFlightGauge prometheus.Gauge
 var requestCounter *prometheus.CounterVec
 var durationHistogram *prometheus.HistogramVec
+var rateLimitRejectionCounter *prometheus.CounterVec
 
 func init() {
 	initPrometheusMetrics()
 }
 
-func StartServer(serverConfig *ServerConfig, authConfig *AuthConfig, filmkritikenService filmkritiken.FilmkritikenService, sessionRepo session.SessionRepository) error {
+func StartServer(serverConfig *ServerConfig, authConfig *AuthConfig, filmkritikenService filmkritiken.FilmkritikenService, sessionRepo session.SessionRepository, userRepo session.UserRepository, permissionService session.PermissionService, tokenService session.TokenService) error {
 	filmkritikenHandler := NewFilmkritikenHandler(filmkritikenService)
+	permissionHandler := NewPermissionHandler(permissionService, sessionRepo, tokenService)
 
+	csrfMiddleware := NewCsrfMiddleware(serverConfig.CorsAllowOrigins)
+	rateLimit := serverConfig.RateLimit
+	// every limiter follows the authentication where there is one, so users are limited by user id instead of
+	// sharing the budget of their IP (e.g. behind NAT). Failed authentications have no user, they are limited
+	// by IP before the authentication.
+	readLimiter := NewRateLimitMiddleware("read", rateLimit.ReadPerMinute, rateLimit.ReadBurst)
+	writeLimiter := NewRateLimitMiddleware("write", rateLimit.WritePerMinute, rateLimit.WriteBurst)
+	authLimiter := NewRateLimitMiddleware("auth", rateLimit.AuthPerMinute, rateLimit.AuthBurst)
+	uploadLimiter := NewRateLimitMiddleware("upload", rateLimit.UploadPerMinute, rateLimit.UploadBurst)
 	handlers := []gin.HandlerFunc{
+		NewFailedAuthLimitMiddleware(rateLimit.FailedAuthPerMinute, rateLimit.FailedAuthBurst),
 		TraceIdMiddleware,
+		csrfMiddleware,
 	}
 
 	r := gin.Default()
+	if err := r.SetTrustedProxies(serverConfig.TrustedProxies); err != nil {
+		return err
+	}
 	r.MaxMultipartMemory = 8 << 20 // max 8 MB file size
 	r.Use(
 		cors.New(
 			cors.Config{
 				AllowOrigins:     serverConfig.CorsAllowOrigins,
-				AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "OPTIONS"},
-				AllowHeaders:     []string{"content-type", "Content-Length", "Accept-Encoding", "Authorization", "origin", "Cache-Control"},
+				AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowHeaders:     []string{"content-type", "Content-Length", "Accept-Encoding", "Authorization", "origin", "Cache-Control", CsrfHeaderName},
 				AllowCredentials: true,
 			},
 		),
@@ -58,37 +77,134 @@ func StartServer(serverConfig *ServerConfig, authConfig *AuthConfig, filmkritike
 	}
 	r.GET("/metrics", ginOmitLogMiddleware, metricsAuthHandler, gin.WrapH(promhttp.Handler()))
 
-	bffAuthHandler := NewBffAuthHandler(authConfig, sessionRepo)
-	r.GET("/auth/login", bffAuthHandler.handleLogin)
-	r.GET("/auth/callback", bffAuthHandler.handleCallback)
-	r.GET("/auth/me", bffAuthHandler.handleMe)
-	r.POST("/auth/logout", bffAuthHandler.handleLogout)
+	bffAuthHandler := NewBffAuthHandler(authConfig, sessionRepo, userRepo, permissionService, tokenService, filmkritikenService)
+	r.GET("/auth/providers", readLimiter, bffAuthHandler.handleProviders)
+	r.GET("/auth/login", authLimiter, bffAuthHandler.handleLogin)
+	r.GET("/auth/callback", authLimiter, bffAuthHandler.handleCallback)
+	r.GET("/auth/dev-login", authLimiter, bffAuthHandler.handleDevLoginPage)
+	r.POST("/auth/dev-login", authLimiter, bffAuthHandler.handleDevLogin)
+	r.GET("/auth/me", readLimiter, bffAuthHandler.handleMe)
+	r.GET("/auth/csrf", readLimiter, bffAuthHandler.handleCsrfToken)
+	r.POST("/auth/logout", writeLimiter, csrfMiddleware, bffAuthHandler.handleLogout)
+	r.GET("/auth/sessions", readLimiter, bffAuthHandler.handleGetSessions)
+	r.DELETE("/auth/sessions", writeLimiter, csrfMiddleware, bffAuthHandler.handleRevokeSessions)
+	r.DELETE("/auth/sessions/:sessionId", writeLimiter, csrfMiddleware, bffAuthHandler.handleRevokeSession)
+	r.GET("/auth/tokens", readLimiter, bffAuthHandler.handleGetTokens)
+	r.POST("/auth/tokens", writeLimiter, csrfMiddleware, bffAuthHandler.handleCreateToken)
+	r.DELETE("/auth/tokens/:tokenId", writeLimiter, csrfMiddleware, bffAuthHandler.handleRevokeToken)
 
 	api := r.Group("/api", handlers...)
-	api.GET("/filmkritiken", metricsHandlerWrapper(filmkritikenHandler.handleGetFilmkritiken, "getFilmkritiken"))
-	api.GET("/filmkritiken/filter-options", metricsHandlerWrapper(filmkritikenHandler.handleGetFilterOptions, "getFilterOptions"))
-	api.GET("/filmkritiken/:filmkritikenId", metricsHandlerWrapper(filmkritikenHandler.handleGetFilmkritikById, "getFilmkritikById"))
-	api.GET("/images/:imageId", metricsHandlerWrapper(filmkritikenHandler.loadImage, "loadImage"))
+	api.GET("/filmkritiken", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetFilmkritiken, "getFilmkritiken"))
+	api.GET("/filmkritiken/filter-options", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetFilterOptions, "getFilterOptions"))
+	api.GET("/filmkritiken/:filmkritikenId", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetFilmkritikById, "getFilmkritikById"))
+	api.GET("/statistiken/mitglieder", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetMitgliederStatistiken, "getMitgliederStatistiken"))
+	api.GET("/filme/:filmId", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetFilm, "getFilm"))
+	api.GET("/personen/:name", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetPerson, "getPerson"))
+	api.GET("/reihen", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetReihen, "getReihen"))
+	api.GET("/reihen/:reiheId", readLimiter, ConditionalGetMiddleware, metricsHandlerWrapper(filmkritikenHandler.handleGetReihe, "getReihe"))
+	api.GET("/images/:imageId", readLimiter, metricsHandlerWrapper(filmkritikenHandler.loadImage, "loadImage"))
 	api.POST(
 		"/filme",
-		NewAuthHandler(sessionRepo, []string{"film.add"}),
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		uploadLimiter,
 		metricsHandlerWrapper(filmkritikenHandler.handleCreateFilm, "createFilm"),
 	)
+	api.GET(
+		"/filmsuche",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		readLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleSearchFilms, "searchFilms"),
+	)
 	api.PUT(
 		"/filmkritiken/:filmkritikenId/bewertungen/:username",
-		NewAuthHandler(sessionRepo, []string{"bewertung.add"}),
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"bewertung.add"}),
+		writeLimiter,
 		metricsHandlerWrapper(filmkritikenHandler.handleSetBewertung, "setBewertung"),
 	)
+	api.PUT(
+		"/filmkritiken/:filmkritikenId/bewertungen",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"bewertung.moderieren"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleSetBewertungen, "setBewertungen"),
+	)
 	api.PATCH(
 		"/filmkritiken/:filmkritikenId/bewertungenoffen/:offen",
-		NewAuthHandler(sessionRepo, []string{"bewertung.openclose"}),
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"bewertung.openclose"}),
+		writeLimiter,
 		metricsHandlerWrapper(filmkritikenHandler.handleOpenCloseBewertungen, "openCloseBewertungen"),
 	)
 	api.PATCH(
 		"/filmkritiken/:filmkritikenId/besprochenAm",
-		NewAuthHandler(sessionRepo, []string{"film.add"}),
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		writeLimiter,
 		filmkritikenHandler.handleSetBesprochenAm,
 	)
+	api.PATCH(
+		"/filmkritiken/:filmkritikenId/kategorien",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleSetKategorien, "setKategorien"),
+	)
+	api.POST(
+		"/reihen",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleCreateReihe, "createReihe"),
+	)
+	api.PUT(
+		"/reihen/:reiheId/filmkritiken/:filmkritikenId",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleSetReiheEintrag, "setReiheEintrag"),
+	)
+	api.DELETE(
+		"/reihen/:reiheId/filmkritiken/:filmkritikenId",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"film.add"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleRemoveReiheEintrag, "removeReiheEintrag"),
+	)
+	api.POST(
+		"/mitglieder/zusammenfuehrungen",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"mitglieder.verwalten"}),
+		writeLimiter,
+		metricsHandlerWrapper(filmkritikenHandler.handleMergeMitglied, "mergeMitglied"),
+	)
+	api.GET(
+		"/rollen",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		readLimiter,
+		metricsHandlerWrapper(permissionHandler.handleGetRoles, "getRoles"),
+	)
+	api.PUT(
+		"/rollen/:role",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		writeLimiter,
+		metricsHandlerWrapper(permissionHandler.handleSaveRole, "saveRole"),
+	)
+	api.DELETE(
+		"/rollen/:role",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		writeLimiter,
+		metricsHandlerWrapper(permissionHandler.handleDeleteRole, "deleteRole"),
+	)
+	api.GET(
+		"/benutzer",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		readLimiter,
+		metricsHandlerWrapper(permissionHandler.handleGetUsers, "getUsers"),
+	)
+	api.PUT(
+		"/benutzer/:userId/rollen",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		writeLimiter,
+		metricsHandlerWrapper(permissionHandler.handleSetUserRoles, "setUserRoles"),
+	)
+	api.DELETE(
+		"/benutzer/:userId/sessions",
+		NewAuthHandler(sessionRepo, permissionService, tokenService, []string{"rollen.verwalten"}),
+		writeLimiter,
+		metricsHandlerWrapper(permissionHandler.handleRevokeUserSessions, "revokeUserSessions"),
+	)
 	err := r.Run()
 
 	if err != nil {
@@ -127,7 +243,15 @@ func initPrometheusMetrics() {
 		[]string{"handler", "method"},
 	)
 
-	prometheus.MustRegister(inFlightGauge, requestCounter, durationHistogram)
+	rateLimitRejectionCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "A counter for requests rejected by a rate limit.",
+		},
+		[]string{"budget"},
+	)
+
+	prometheus.MustRegister(inFlightGauge, requestCounter, durationHistogram, rateLimitRejectionCounter)
 }
 
 func metricsHandlerWrapper(handler func(*gin.Context), handlerName string) func(*gin.Context) {
@@ -0,0 +1,132 @@
+package inbound
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// sessionLastSeenMinimum limits the writes for the last request of a session
+	sessionLastSeenMinimum = time.Minute
+	maxUserAgentLength     = 256
+)
+
+// SessionInfo describes a session without its secret, the id is the hash of the session id
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Provider   string    `json:"provider"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	// Current is set for the session of the request
+	Current bool `json:"current"`
+}
+
+// touchSession records the time and IP of the last request of the session.
+func touchSession(ginCtx *gin.Context, sessionRepo session.SessionRepository, sess *session.Session) {
+	now := time.Now()
+	if now.Sub(sess.LastSeenAt) <= sessionLastSeenMinimum {
+		return
+	}
+	if err := sessionRepo.UpdateSessionLastSeen(ginCtx.Request.Context(), sess.ID, now, ginCtx.ClientIP()); err != nil {
+		log.Warnf("could not update last seen of session of %s: %v", sess.UserID, err)
+		return
+	}
+	sess.LastSeenAt = now
+}
+
+func userAgent(c *gin.Context) string {
+	ua := c.Request.UserAgent()
+	if len(ua) > maxUserAgentLength {
+		ua = ua[:maxUserAgentLength]
+	}
+	return ua
+}
+
+func (h *BffAuthHandler) handleGetSessions(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionRepo.GetSessions(c.Request.Context(), sess.UserID)
+	if err != nil {
+		log.Errorf("Failed to load sessions of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load sessions"})
+		return
+	}
+
+	currentHash := session.HashSessionID(sess.ID)
+	result := make([]*SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, &SessionInfo{
+			ID:         s.ID,
+			Provider:   s.Provider,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			ExpiresAt:  s.ExpiresAt,
+			Current:    s.ID == currentHash,
+		})
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *BffAuthHandler) handleRevokeSession(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	hashedID := c.Param("sessionId")
+	err := h.sessionRepo.DeleteSessionByHash(c.Request.Context(), sess.UserID, hashedID)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Errorf("Failed to revoke session of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	if hashedID == session.HashSessionID(sess.ID) {
+		h.setCookie(c, SessionCookieName, "", -1)
+	}
+	log.Infof("user %s revoked a session", sess.UserID)
+	c.Status(http.StatusNoContent)
+}
+
+// handleRevokeSessions logs the user out everywhere, including the current browser. Personal tokens are
+// revoked as well, as they would keep access otherwise.
+func (h *BffAuthHandler) handleRevokeSessions(c *gin.Context) {
+	sess, ok := h.browserSession(c)
+	if !ok {
+		return
+	}
+
+	count, err := h.sessionRepo.DeleteSessions(c.Request.Context(), sess.UserID)
+	if err != nil {
+		log.Errorf("Failed to revoke sessions of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke sessions"})
+		return
+	}
+	tokenCount, err := h.tokenService.RevokeTokens(c.Request.Context(), sess.UserID)
+	if err != nil {
+		log.Errorf("Failed to revoke tokens of %s: %v", sess.UserID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke tokens"})
+		return
+	}
+
+	h.setCookie(c, SessionCookieName, "", -1)
+	log.Infof("user %s revoked all %d sessions and %d tokens", sess.UserID, count, tokenCount)
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+package inbound
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"github.com/DerBlum/filmkritiken-backend/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBffAuthHandler_Sessions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	currentID := "current-session"
+	current := &session.Session{ID: currentID, UserID: "oid-stefan", ExpiresAt: time.Now().Add(time.Hour)}
+
+	newRouter := func(t *testing.T) (*gin.Engine, *mocks.MockSessionRepository, *mocks.MockTokenService) {
+		ctrl := gomock.NewController(t)
+		sessionRepo := mocks.NewMockSessionRepository(ctrl)
+		tokenService := mocks.NewMockTokenService(ctrl)
+		sessionRepo.EXPECT().FindSession(gomock.Any(), currentID).Return(current, nil)
+		handler := NewBffAuthHandler(&AuthConfig{FrontendURL: "http://frontend"}, sessionRepo, nil, nil, tokenService, nil)
+
+		r := gin.New()
+		r.GET("/auth/sessions", handler.handleGetSessions)
+		r.DELETE("/auth/sessions", handler.handleRevokeSessions)
+		r.DELETE("/auth/sessions/:sessionId", handler.handleRevokeSession)
+		return r, sessionRepo, tokenService
+	}
+	request := func(r *gin.Engine, method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(&http.Cookie{Name: SessionCookieName, Value: currentID})
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("sessions of the user are listed without secret", func(t *testing.T) {
+		r, sessionRepo, _ := newRouter(t)
+		sessionRepo.EXPECT().GetSessions(gomock.Any(), "oid-stefan").Return([]*session.Session{
+			{ID: session.HashSessionID(currentID), UserAgent: "Firefox"},
+			{ID: session.HashSessionID("other-session"), UserAgent: "Safari"},
+		}, nil)
+
+		w := request(r, http.MethodGet, "/auth/sessions")
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+		var sessions []*SessionInfo
+		_ = json.Unmarshal(w.Body.Bytes(), &sessions)
+		if len(sessions) != 2 || !sessions[0].Current || sessions[1].Current || sessions[0].ID == currentID {
+			t.Errorf("unexpected sessions %+v", sessions)
+		}
+	})
+
+	t.Run("unknown session returns 404", func(t *testing.T) {
+		r, sessionRepo, _ := newRouter(t)
+		sessionRepo.EXPECT().DeleteSessionByHash(gomock.Any(), "oid-stefan", "unknown").Return(errors.NewNotFoundErrorFromString("Session nicht gefunden."))
+
+		w := request(r, http.MethodDelete, "/auth/sessions/unknown")
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("log out everywhere deletes all sessions and tokens of the user", func(t *testing.T) {
+		r, sessionRepo, tokenService := newRouter(t)
+		sessionRepo.EXPECT().DeleteSessions(gomock.Any(), "oid-stefan").Return(int64(3), nil)
+		tokenService.EXPECT().RevokeTokens(gomock.Any(), "oid-stefan").Return(int64(1), nil)
+
+		w := request(r, http.MethodDelete, "/auth/sessions")
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("expected 204, got %d", w.Code)
+		}
+	})
+}
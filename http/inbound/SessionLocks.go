@@ -0,0 +1,42 @@
+package inbound
+
+import "sync"
+
+// sessionLocks serializes work on one session, e.g. its refresh, while other sessions proceed in parallel.
+type sessionLocks struct {
+	mutex sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	sync.Mutex
+	// holders counts the goroutines holding or waiting for the lock, it is dropped when no one needs it anymore
+	holders int
+}
+
+func newSessionLocks() *sessionLocks {
+	return &sessionLocks{locks: make(map[string]*sessionLock)}
+}
+
+// lock blocks until the session is free and returns the function releasing it.
+func (l *sessionLocks) lock(sessionID string) func() {
+	l.mutex.Lock()
+	lock, ok := l.locks[sessionID]
+	if !ok {
+		lock = &sessionLock{}
+		l.locks[sessionID] = lock
+	}
+	lock.holders++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mutex.Lock()
+		lock.holders--
+		if lock.holders == 0 {
+			delete(l.locks, sessionID)
+		}
+		l.mutex.Unlock()
+	}
+}
@@ -0,0 +1,63 @@
+package inbound
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// tokenCipher encrypts refresh tokens with AES-GCM before they are stored with the session, so a copy of
+// the database does not give access to the identity provider.
+type tokenCipher struct {
+	aead cipher.AEAD
+}
+
+// newTokenCipher expects a base64 encoded key of 32 bytes (e.g. from "openssl rand -base64 32").
+func newTokenCipher(encodedKey string) (*tokenCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("key is not base64 encoded: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key has %d bytes instead of 32", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenCipher{aead: aead}, nil
+}
+
+// Encrypt binds the ciphertext to the user id, so it can't be moved to the session of another user.
+func (t *tokenCipher) Encrypt(plaintext string, userID string) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := t.aead.Seal(nonce, nonce, []byte(plaintext), []byte(userID))
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (t *tokenCipher) Decrypt(ciphertext string, userID string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < t.aead.NonceSize() {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, data := sealed[:t.aead.NonceSize()], sealed[t.aead.NonceSize():]
+	plaintext, err := t.aead.Open(nil, nonce, data, []byte(userID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
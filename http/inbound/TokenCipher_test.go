@@ -0,0 +1,38 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestTokenCipher(t *testing.T) {
+	tokenCipher, err := newTokenCipher(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := tokenCipher.Encrypt("refresh-token", "oid-stefan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encrypted == "refresh-token" {
+		t.Fatal("expected ciphertext")
+	}
+
+	decrypted, err := tokenCipher.Decrypt(encrypted, "oid-stefan")
+	if err != nil || decrypted != "refresh-token" {
+		t.Errorf("expected refresh-token, got %q (%v)", decrypted, err)
+	}
+	if _, err := tokenCipher.Decrypt(encrypted, "oid-other"); err == nil {
+		t.Error("expected error for the token of another user")
+	}
+}
+
+func TestNewTokenCipher_InvalidKey(t *testing.T) {
+	if _, err := newTokenCipher("not base64!"); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+	if _, err := newTokenCipher(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+		t.Error("expected error for short key")
+	}
+}
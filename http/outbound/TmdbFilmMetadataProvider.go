@@ -0,0 +1,284 @@
+package outbound
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+const (
+	// details are loaded for every candidate, so only the best matches are returned
+	tmdbMaxCandidates = 5
+	// details of the candidates are loaded in parallel, but without flooding the API
+	tmdbMaxParallelRequests = 3
+	// only the leading actors are taken over, the complete cast can be very long
+	tmdbMaxDarsteller = 15
+	tmdbTimeout       = 10 * time.Second
+)
+
+// poster paths look like "/kqjL17yufvn9OVLyXYpvtyrFfak.jpg"; anything else could point elsewhere
+var tmdbPosterPath = regexp.MustCompile(`^/[a-zA-Z0-9]+\.(jpg|jpeg|png|webp)$`)
+
+type TmdbConfig struct {
+	// AccessToken is the TMDB API read access token, the film search is disabled without it
+	AccessToken  string `env:"TMDB_ACCESS_TOKEN,unset"`
+	BaseUrl      string `env:"TMDB_BASE_URL" envDefault:"https://api.themoviedb.org/3"`
+	ImageBaseUrl string `env:"TMDB_IMAGE_BASE_URL" envDefault:"https://image.tmdb.org/t/p/w780"`
+	Language     string `env:"TMDB_LANGUAGE" envDefault:"de-DE"`
+	Region       string `env:"TMDB_REGION" envDefault:"DE"`
+}
+
+type (
+	tmdbSearchResponse struct {
+		Results []*tmdbSearchResult `json:"results"`
+	}
+
+	tmdbSearchResult struct {
+		Id int `json:"id"`
+	}
+
+	tmdbMovie struct {
+		Id               int    `json:"id"`
+		Title            string `json:"title"`
+		OriginalTitle    string `json:"original_title"`
+		OriginalLanguage string `json:"original_language"`
+		ReleaseDate      string `json:"release_date"`
+		Runtime          int    `json:"runtime"`
+		PosterPath       string `json:"poster_path"`
+		Genres           []struct {
+			Name string `json:"name"`
+		} `json:"genres"`
+		ProductionCountries []struct {
+			Iso31661 string `json:"iso_3166_1"`
+			Name     string `json:"name"`
+		} `json:"production_countries"`
+		Credits struct {
+			Cast []struct {
+				Name      string `json:"name"`
+				Character string `json:"character"`
+				Order     int    `json:"order"`
+			} `json:"cast"`
+			Crew []struct {
+				Job  string `json:"job"`
+				Name string `json:"name"`
+			} `json:"crew"`
+		} `json:"credits"`
+		ReleaseDates struct {
+			Results []struct {
+				Iso31661     string `json:"iso_3166_1"`
+				ReleaseDates []struct {
+					Certification string `json:"certification"`
+				} `json:"release_dates"`
+			} `json:"results"`
+		} `json:"release_dates"`
+	}
+)
+
+// tmdbFilmMetadataProvider looks up films in The Movie Database (https://developer.themoviedb.org).
+type tmdbFilmMetadataProvider struct {
+	config     *TmdbConfig
+	httpClient *http.Client
+}
+
+func NewTmdbFilmMetadataProvider(config *TmdbConfig) *tmdbFilmMetadataProvider {
+	return &tmdbFilmMetadataProvider{
+		config:     config,
+		httpClient: &http.Client{Timeout: tmdbTimeout},
+	}
+}
+
+func (p *tmdbFilmMetadataProvider) SearchFilms(ctx context.Context, query string) ([]*filmkritiken.FilmCandidate, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("language", p.config.Language)
+	params.Set("include_adult", "false")
+
+	searchResponse := &tmdbSearchResponse{}
+	if err := p.getJson(ctx, "/search/movie", params, searchResponse); err != nil {
+		return nil, err
+	}
+
+	results := searchResponse.Results[:min(len(searchResponse.Results), tmdbMaxCandidates)]
+	movies := make([]*tmdbMovie, len(results))
+	errs := make([]error, len(results))
+	semaphore := make(chan struct{}, tmdbMaxParallelRequests)
+	var wg sync.WaitGroup
+	for i, result := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			movies[i], errs[i] = p.getMovie(ctx, result.Id)
+		}()
+	}
+	wg.Wait()
+
+	// a candidate without details is left out, the search only fails if no details could be loaded at all
+	candidates := make([]*filmkritiken.FilmCandidate, 0, len(results))
+	for i, movie := range movies {
+		if errs[i] != nil {
+			log.Warnf("could not load details of tmdb movie %d: %v", results[i].Id, errs[i])
+			continue
+		}
+		candidates = append(candidates, p.toFilmCandidate(movie))
+	}
+	if len(candidates) == 0 && len(results) > 0 {
+		return nil, errs[0]
+	}
+
+	return candidates, nil
+}
+
+func (p *tmdbFilmMetadataProvider) DownloadPoster(ctx context.Context, posterRef string) ([]byte, error) {
+	if !tmdbPosterPath.MatchString(posterRef) {
+		return nil, errors.NewInvalidInputErrorFromString("Ungültige Referenz auf ein Filmplakat.")
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.ImageBaseUrl+posterRef, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errors.NewNotFoundErrorFromString("Filmplakat konnte nicht gefunden werden.")
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not download poster %s: status %d", posterRef, response.StatusCode)
+	}
+
+	// read one byte more than allowed, so ValidateImage rejects posters that are too large
+	return io.ReadAll(io.LimitReader(response.Body, filmkritiken.MaxImageSize+1))
+}
+
+func (p *tmdbFilmMetadataProvider) getMovie(ctx context.Context, movieId int) (*tmdbMovie, error) {
+	params := url.Values{}
+	params.Set("language", p.config.Language)
+	params.Set("append_to_response", "credits,release_dates")
+
+	movie := &tmdbMovie{}
+	err := p.getJson(ctx, fmt.Sprintf("/movie/%d", movieId), params, movie)
+	return movie, err
+}
+
+func (p *tmdbFilmMetadataProvider) getJson(ctx context.Context, path string, params url.Values, result any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.BaseUrl+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+p.config.AccessToken)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("tmdb request %s failed: status %d", path, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(result)
+}
+
+func (p *tmdbFilmMetadataProvider) toFilmCandidate(movie *tmdbMovie) *filmkritiken.FilmCandidate {
+	film := &filmkritiken.Film{
+		Titel:           movie.Title,
+		Laenge:          movie.Runtime,
+		Originalsprache: languageName(movie.OriginalLanguage),
+		Image:           &filmkritiken.Image{Copyright: "TMDB"},
+	}
+	if movie.OriginalTitle != movie.Title {
+		film.Originaltitel = movie.OriginalTitle
+	}
+	if len(movie.ReleaseDate) >= 4 {
+		film.Erscheinungsjahr, _ = strconv.Atoi(movie.ReleaseDate[:4])
+	}
+
+	film.Genres = make([]string, 0, len(movie.Genres))
+	for _, genre := range movie.Genres {
+		film.Genres = append(film.Genres, genre.Name)
+	}
+
+	regie := make([]string, 0, 1)
+	for _, crew := range movie.Credits.Crew {
+		if crew.Job == "Director" {
+			regie = append(regie, crew.Name)
+		}
+	}
+	film.Regie = strings.Join(regie, ", ")
+
+	film.Besetzung = make([]*filmkritiken.Darsteller, 0, min(len(movie.Credits.Cast), tmdbMaxDarsteller))
+	for _, cast := range movie.Credits.Cast {
+		if len(film.Besetzung) == tmdbMaxDarsteller {
+			break
+		}
+		film.Besetzung = append(film.Besetzung, &filmkritiken.Darsteller{Name: cast.Name, Rolle: cast.Character, Reihenfolge: cast.Order})
+	}
+
+	laender := make([]string, 0, len(movie.ProductionCountries))
+	for _, country := range movie.ProductionCountries {
+		laender = append(laender, regionName(country.Iso31661, country.Name))
+	}
+	film.Produktionsland = strings.Join(laender, ", ")
+
+	for _, release := range movie.ReleaseDates.Results {
+		if release.Iso31661 != p.config.Region {
+			continue
+		}
+		for _, releaseDate := range release.ReleaseDates {
+			if fsk, err := strconv.Atoi(releaseDate.Certification); err == nil {
+				film.Altersfreigabe = fsk
+				break
+			}
+		}
+	}
+
+	candidate := &filmkritiken.FilmCandidate{
+		ExterneId: strconv.Itoa(movie.Id),
+		Film:      film,
+	}
+	if tmdbPosterPath.MatchString(movie.PosterPath) {
+		candidate.PosterRef = movie.PosterPath
+		candidate.PosterUrl = p.config.ImageBaseUrl + movie.PosterPath
+	}
+	return candidate
+}
+
+// languageName returns the German name of an ISO 639-1 language code, e.g. "Englisch" for "en".
+func languageName(code string) string {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return code
+	}
+	return display.German.Languages().Name(tag)
+}
+
+// regionName returns the German name of an ISO 3166-1 country code, e.g. "Vereinigte Staaten" for "US".
+func regionName(code string, fallback string) string {
+	region, err := language.ParseRegion(code)
+	if err != nil {
+		return fallback
+	}
+	return display.German.Regions().Name(region)
+}
@@ -0,0 +1,160 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	domainErrors "github.com/DerBlum/filmkritiken-backend/domain/errors"
+)
+
+const tmdbAlienDetails = `{
+	"id": 348,
+	"title": "Alien – Das unheimliche Wesen aus einer fremden Welt",
+	"original_title": "Alien",
+	"original_language": "en",
+	"release_date": "1979-05-25",
+	"runtime": 117,
+	"poster_path": "/aWbHUvWmgxaZHoDPXO0BxOVEKMU.jpg",
+	"genres": [{"id": 27, "name": "Horror"}, {"id": 878, "name": "Science Fiction"}],
+	"production_countries": [{"iso_3166_1": "GB", "name": "United Kingdom"}, {"iso_3166_1": "US", "name": "United States of America"}],
+	"credits": {"cast": [{"name": "Tom Skerritt", "character": "Dallas", "order": 0}, {"name": "Sigourney Weaver", "character": "Ripley", "order": 1}], "crew": [{"job": "Producer", "name": "Gordon Carroll"}, {"job": "Director", "name": "Ridley Scott"}]},
+	"release_dates": {"results": [
+		{"iso_3166_1": "US", "release_dates": [{"certification": "R"}]},
+		{"iso_3166_1": "DE", "release_dates": [{"certification": ""}, {"certification": "16"}]}
+	]}
+}`
+
+func newTmdbStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/3/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("query") != "Alien" || r.URL.Query().Get("language") != "de-DE" {
+			t.Errorf("unexpected search query %s", r.URL.RawQuery)
+		}
+		// the details of 1 are missing, its candidate is left out
+		_, _ = w.Write([]byte(`{"results": [{"id": 1}, {"id": 348}]}`))
+	})
+	mux.HandleFunc("/3/movie/1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/3/movie/348", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(tmdbAlienDetails))
+	})
+	mux.HandleFunc("/t/p/w780/aWbHUvWmgxaZHoDPXO0BxOVEKMU.jpg", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("poster"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestProvider(server *httptest.Server) *tmdbFilmMetadataProvider {
+	return NewTmdbFilmMetadataProvider(&TmdbConfig{
+		AccessToken:  "token",
+		BaseUrl:      server.URL + "/3",
+		ImageBaseUrl: server.URL + "/t/p/w780",
+		Language:     "de-DE",
+		Region:       "DE",
+	})
+}
+
+func TestTmdbFilmMetadataProvider_SearchFilms(t *testing.T) {
+	// given
+	server := newTmdbStandIn(t)
+	provider := newTestProvider(server)
+
+	// when
+	candidates, err := provider.SearchFilms(context.Background(), "Alien")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(candidates) != 1 {
+		t.Fatalf("expected 1 candidate, got %d", len(candidates))
+	}
+
+	candidate := candidates[0]
+	film := candidate.Film
+	if candidate.ExterneId != "348" || candidate.PosterRef != "/aWbHUvWmgxaZHoDPXO0BxOVEKMU.jpg" {
+		t.Errorf("unexpected candidate %+v", candidate)
+	}
+	if film.Titel != "Alien – Das unheimliche Wesen aus einer fremden Welt" || film.Originaltitel != "Alien" {
+		t.Errorf("unexpected titles %q / %q", film.Titel, film.Originaltitel)
+	}
+	if film.Erscheinungsjahr != 1979 || film.Laenge != 117 || film.Altersfreigabe != 16 {
+		t.Errorf("unexpected year / length / fsk %d / %d / %d", film.Erscheinungsjahr, film.Laenge, film.Altersfreigabe)
+	}
+	if len(film.Genres) != 2 || film.Genres[0] != "Horror" {
+		t.Errorf("unexpected genres %v", film.Genres)
+	}
+	if len(film.Besetzung) != 2 || film.Besetzung[1].Name != "Sigourney Weaver" || film.Besetzung[1].Rolle != "Ripley" || film.Besetzung[1].Reihenfolge != 1 {
+		t.Errorf("unexpected besetzung %+v", film.Besetzung)
+	}
+	if film.Regie != "Ridley Scott" {
+		t.Errorf("expected Regie to be Ridley Scott, got %q", film.Regie)
+	}
+	if film.Originalsprache != "Englisch" || film.Produktionsland != "Vereinigtes Königreich, Vereinigte Staaten" {
+		t.Errorf("unexpected language / countries %q / %q", film.Originalsprache, film.Produktionsland)
+	}
+}
+
+func TestTmdbFilmMetadataProvider_SearchFilms_NoDetails(t *testing.T) {
+	// given
+	mux := http.NewServeMux()
+	mux.HandleFunc("/3/search/movie", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"results": [{"id": 1}, {"id": 2}]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	provider := newTestProvider(server)
+
+	// when
+	candidates, err := provider.SearchFilms(context.Background(), "Alien")
+
+	// then
+	if err == nil {
+		t.Errorf("expected error if no details could be loaded, got %d candidates", len(candidates))
+	}
+}
+
+func TestTmdbFilmMetadataProvider_DownloadPoster(t *testing.T) {
+	server := newTmdbStandIn(t)
+	provider := newTestProvider(server)
+
+	t.Run("poster of the provider is downloaded", func(t *testing.T) {
+		poster, err := provider.DownloadPoster(context.Background(), "/aWbHUvWmgxaZHoDPXO0BxOVEKMU.jpg")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(poster) != "poster" {
+			t.Errorf("unexpected poster %q", poster)
+		}
+	})
+
+	t.Run("references to other hosts or paths are rejected", func(t *testing.T) {
+		for _, posterRef := range []string{"//evil.example/x.jpg", "/../../admin.jpg", "@evil.example/x.jpg", "https://evil.example/x.jpg"} {
+			_, err := provider.DownloadPoster(context.Background(), posterRef)
+			var iie *domainErrors.InvalidInputError
+			if !errors.As(err, &iie) {
+				t.Errorf("expected InvalidInputError for %q, got %v", posterRef, err)
+			}
+		}
+	})
+
+	t.Run("missing poster", func(t *testing.T) {
+		_, err := provider.DownloadPoster(context.Background(), "/missing.jpg")
+		var nfe *domainErrors.NotFoundError
+		if !errors.As(err, &nfe) {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+	})
+}
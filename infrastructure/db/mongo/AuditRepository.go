@@ -0,0 +1,36 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const auditCollectionName = "audit"
+
+// SaveFilmkritikenWithAudit needs a replica set (like the one of docker-compose.yml), standalone servers
+// do not support transactions.
+func (repo *mongoDbRepository) SaveFilmkritikenWithAudit(ctx context.Context, allFilmkritiken []*filmkritiken.Filmkritiken, auditEintrag *filmkritiken.AuditEintrag) error {
+	if auditEintrag.Id == "" {
+		auditEintrag.Id = bson.NewObjectID().Hex()
+	}
+
+	session, err := repo.database.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(ctx context.Context) (any, error) {
+		for _, fk := range allFilmkritiken {
+			if err := repo.SaveFilmkritiken(ctx, fk); err != nil {
+				return nil, err
+			}
+		}
+
+		return repo.database.Collection(auditCollectionName).InsertOne(ctx, auditEintrag)
+	})
+
+	return err
+}
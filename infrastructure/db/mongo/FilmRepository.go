@@ -0,0 +1,65 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const filmeCollectionName = "filme"
+
+func (repo *mongoDbRepository) FindFilm(ctx context.Context, filmId string) (*filmkritiken.Film, error) {
+	mongoFilter := bson.M{"_id": bson.M{"$eq": filmId}}
+	result := &filmkritiken.Film{}
+
+	err := repo.database.Collection(filmeCollectionName).FindOne(ctx, mongoFilter).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundErrorFromString("Film konnte nicht gefunden werden.")
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *mongoDbRepository) SaveFilm(ctx context.Context, film *filmkritiken.Film) error {
+	if film.Id == "" {
+		film.Id = bson.NewObjectID().Hex()
+	}
+
+	filter := bson.M{"_id": bson.M{"$eq": film.Id}}
+	update := bson.D{bson.E{Key: "$set", Value: film}}
+	_, err := repo.database.Collection(filmeCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+
+	return err
+}
+
+func (repo *mongoDbRepository) DeleteFilm(ctx context.Context, filmId string) error {
+	filter := bson.M{"_id": bson.M{"$eq": filmId}}
+	_, err := repo.database.Collection(filmeCollectionName).DeleteOne(ctx, filter)
+	return err
+}
+
+// GetFilmkritikenByFilm returns all screenings of the Film, oldest first.
+func (repo *mongoDbRepository) GetFilmkritikenByFilm(ctx context.Context, filmId string) ([]*filmkritiken.Filmkritiken, error) {
+	mongoFilter := bson.M{"film._id": bson.M{"$eq": filmId}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "details.besprochenam", Value: 1}})
+
+	cursor, err := repo.database.Collection(filmkritikenCollectionName).Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*filmkritiken.Filmkritiken, 0)
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,256 @@
+package mongo
+
+import (
+	"context"
+	stdErrors "errors"
+	"io"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const imagesBucketName = "images"
+
+type gridFsImageMetadata struct {
+	ContentType string `bson:"contentType"`
+	ImageId     string `bson:"imageId"`
+	Width       int    `bson:"width,omitempty"`
+}
+
+// gridFsImageRepository stores images (and their variants) as GridFS files, so they are not limited
+// by the 16 MB document size and can be streamed. Images that have not been migrated yet are read
+// from the images collection of the mongoDbRepository.
+type gridFsImageRepository struct {
+	bucket *mongo.GridFSBucket
+	legacy *mongoDbRepository
+}
+
+func NewGridFsImageRepository(repo *mongoDbRepository) *gridFsImageRepository {
+	return &gridFsImageRepository{
+		bucket: repo.database.GridFSBucket(options.GridFSBucket().SetName(imagesBucketName)),
+		legacy: repo,
+	}
+}
+
+func (repo *gridFsImageRepository) SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	metadata := &gridFsImageMetadata{ContentType: imageFile.ContentType, ImageId: imageId}
+	err := repo.upload(ctx, imageId, metadata, imageFile.Content)
+	if mongo.IsDuplicateKeyError(err) {
+		// an identical upload inserted the first chunk or the file of the same content id before
+		return filmkritiken.ErrImageAlreadyStored
+	}
+	return err
+}
+
+func (repo *gridFsImageRepository) SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	for _, variant := range variants {
+		fileId := filmkritiken.ImageVariantId(imageId, variant.Width)
+
+		// variants can be regenerated, GridFS does not allow overwriting files
+		err := repo.bucket.Delete(ctx, fileId)
+		if err != nil && !stdErrors.Is(err, mongo.ErrFileNotFound) {
+			return err
+		}
+
+		metadata := &gridFsImageMetadata{ContentType: variant.ContentType, ImageId: imageId, Width: variant.Width}
+		err = repo.upload(ctx, fileId, metadata, filmkritiken.NewBytesContent(*variant.Bites))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *gridFsImageRepository) upload(ctx context.Context, fileId string, metadata *gridFsImageMetadata, content io.Reader) error {
+	uploadOpts := options.GridFSUpload().SetMetadata(metadata)
+	return repo.bucket.UploadFromStreamWithID(ctx, fileId, fileId, content, uploadOpts)
+}
+
+func (repo *gridFsImageRepository) FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error) {
+	imageFile, err := repo.open(ctx, imageId)
+	if stdErrors.Is(err, mongo.ErrFileNotFound) {
+		return repo.legacy.FindImage(ctx, imageId)
+	}
+
+	return imageFile, err
+}
+
+func (repo *gridFsImageRepository) FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	imageFile, err := repo.open(ctx, filmkritiken.ImageVariantId(imageId, width))
+	if stdErrors.Is(err, mongo.ErrFileNotFound) {
+		return repo.legacy.FindImageVariant(ctx, imageId, width)
+	}
+
+	return imageFile, err
+}
+
+// ImageExists reports whether the image has already been stored in GridFS.
+func (repo *gridFsImageRepository) ImageExists(ctx context.Context, imageId string) (bool, error) {
+	count, err := repo.bucket.GetFilesCollection().CountDocuments(ctx, bson.M{"_id": bson.M{"$eq": imageId}})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetImageIds returns the ids of all images (without variants) stored in GridFS or not migrated yet.
+func (repo *gridFsImageRepository) GetImageIds(ctx context.Context) ([]string, error) {
+	cursor, err := repo.bucket.Find(ctx, bson.M{"metadata.width": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+
+	var files []struct {
+		Id string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return nil, err
+	}
+
+	legacyImageIds, err := repo.legacy.GetImageIds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	imageIds := make([]string, 0, len(files)+len(legacyImageIds))
+	seen := make(map[string]bool, len(files))
+	for _, file := range files {
+		imageIds = append(imageIds, file.Id)
+		seen[file.Id] = true
+	}
+	for _, imageId := range legacyImageIds {
+		if !seen[imageId] {
+			imageIds = append(imageIds, imageId)
+		}
+	}
+	return imageIds, nil
+}
+
+func (repo *gridFsImageRepository) DeleteImage(ctx context.Context, imageId string) error {
+	cursor, err := repo.bucket.Find(ctx, bson.M{"metadata.imageId": bson.M{"$eq": imageId}})
+	if err != nil {
+		return err
+	}
+
+	var files []struct {
+		Id string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &files); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		err := repo.bucket.Delete(ctx, file.Id)
+		if err != nil && !stdErrors.Is(err, mongo.ErrFileNotFound) {
+			return err
+		}
+	}
+
+	// image might not have been migrated yet
+	return repo.legacy.DeleteImage(ctx, imageId)
+}
+
+func (repo *gridFsImageRepository) open(ctx context.Context, fileId string) (*filmkritiken.ImageFile, error) {
+	stream, err := repo.bucket.OpenDownloadStream(ctx, fileId)
+	if err != nil {
+		return nil, err
+	}
+
+	file := stream.GetFile()
+	metadata := &gridFsImageMetadata{}
+	if file.Metadata != nil {
+		if err := bson.Unmarshal(file.Metadata, metadata); err != nil {
+			_ = stream.Close()
+			return nil, err
+		}
+	}
+
+	return &filmkritiken.ImageFile{
+		Id:          fileId,
+		ContentType: metadata.ContentType,
+		ModTime:     file.UploadDate,
+		Size:        file.Length,
+		Content: &gridFsReadSeeker{
+			ctx:    ctx,
+			bucket: repo.bucket,
+			fileId: fileId,
+			size:   file.Length,
+			stream: stream,
+		},
+	}, nil
+}
+
+// gridFsReadSeeker makes a GridFS download stream seekable (needed for range requests) by
+// reopening the stream and skipping to the requested offset when reading after a seek.
+type gridFsReadSeeker struct {
+	ctx          context.Context
+	bucket       *mongo.GridFSBucket
+	fileId       string
+	size         int64
+	offset       int64
+	stream       *mongo.GridFSDownloadStream
+	streamOffset int64
+}
+
+func (r *gridFsReadSeeker) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	if r.stream != nil && r.streamOffset != r.offset {
+		_ = r.stream.Close()
+		r.stream = nil
+	}
+	if r.stream == nil {
+		stream, err := r.bucket.OpenDownloadStream(r.ctx, r.fileId)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := stream.Skip(r.offset); err != nil {
+			_ = stream.Close()
+			return 0, err
+		}
+		r.stream = stream
+		r.streamOffset = r.offset
+	}
+
+	if remaining := r.size - r.offset; int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := r.stream.Read(p)
+	r.offset += int64(n)
+	r.streamOffset = r.offset
+	return n, err
+}
+
+func (r *gridFsReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = offset
+	case io.SeekCurrent:
+		newOffset = r.offset + offset
+	case io.SeekEnd:
+		newOffset = r.size + offset
+	default:
+		return 0, stdErrors.New("invalid whence")
+	}
+	if newOffset < 0 {
+		return 0, stdErrors.New("negative position")
+	}
+
+	r.offset = newOffset
+	return newOffset, nil
+}
+
+func (r *gridFsReadSeeker) Close() error {
+	if r.stream == nil {
+		return nil
+	}
+	err := r.stream.Close()
+	r.stream = nil
+	return err
+}
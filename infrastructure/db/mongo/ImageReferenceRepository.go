@@ -0,0 +1,107 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const imageReferencesCollectionName = "imagereferences"
+
+type imageReference struct {
+	ImageId string `bson:"_id"`
+	Count   int64  `bson:"count"`
+	// Deleting reserves the image for its deletion, no references can be added meanwhile
+	Deleting bool `bson:"deleting,omitempty"`
+}
+
+func (repo *mongoDbRepository) AddImageReference(ctx context.Context, imageId string) (int64, error) {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}, "deleting": bson.M{"$ne": true}}
+	update := bson.M{"$inc": bson.M{"count": 1}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	result := &imageReference{}
+	err := repo.database.Collection(imageReferencesCollectionName).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+	if mongo.IsDuplicateKeyError(err) {
+		// the upsert failed, because the image is reserved for its deletion or a concurrent upsert inserted it
+		deleting, findErr := repo.imageDeletionReserved(ctx, imageId)
+		if findErr != nil {
+			return 0, findErr
+		}
+		if deleting {
+			return 0, errors.NewUnavailableErrorFromString("Das Bild wird gerade gelöscht, bitte erneut versuchen.")
+		}
+		err = repo.database.Collection(imageReferencesCollectionName).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Count, nil
+}
+
+func (repo *mongoDbRepository) imageDeletionReserved(ctx context.Context, imageId string) (bool, error) {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}}
+
+	result := &imageReference{}
+	err := repo.database.Collection(imageReferencesCollectionName).FindOne(ctx, filter).Decode(result)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return result.Deleting, nil
+}
+
+func (repo *mongoDbRepository) ReserveImageDeletion(ctx context.Context, imageId string) (bool, error) {
+	// matches untracked images and images without references, for referenced images the upsert fails
+	filter := bson.M{"_id": bson.M{"$eq": imageId}, "count": bson.M{"$lte": 0}}
+	update := bson.M{"$set": bson.M{"count": 0, "deleting": true}}
+
+	_, err := repo.database.Collection(imageReferencesCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo *mongoDbRepository) RemoveImageReference(ctx context.Context, imageId string) (int64, error) {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}, "count": bson.M{"$gt": 0}}
+	update := bson.M{"$inc": bson.M{"count": -1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	result := &imageReference{}
+	err := repo.database.Collection(imageReferencesCollectionName).FindOneAndUpdate(ctx, filter, update, opts).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			// images stored before reference counting was introduced are referenced exactly once
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if result.Count <= 0 {
+		deleteFilter := bson.M{"_id": bson.M{"$eq": imageId}, "count": bson.M{"$lte": 0}, "deleting": bson.M{"$ne": true}}
+		_, err = repo.database.Collection(imageReferencesCollectionName).DeleteOne(ctx, deleteFilter)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return result.Count, nil
+}
+
+func (repo *mongoDbRepository) DeleteImageReferences(ctx context.Context, imageId string) error {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}}
+	_, err := repo.database.Collection(imageReferencesCollectionName).DeleteOne(ctx, filter)
+	return err
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"context"
+	"io"
 	"regexp"
 	"time"
 
@@ -13,13 +14,23 @@ import (
 )
 
 const (
-	filmkritikenCollectionName = "filmkritiken"
-	imagesCollectionName       = "images"
+	filmkritikenCollectionName  = "filmkritiken"
+	imagesCollectionName        = "images"
+	imageVariantsCollectionName = "imagevariants"
 )
 
 type image struct {
-	ImageId string `bson:"_id"`
-	Image   *[]byte
+	ImageId     string `bson:"_id"`
+	Image       *[]byte
+	ContentType string `bson:"contentType,omitempty"`
+}
+
+type imageVariant struct {
+	VariantId   string  `bson:"_id"`
+	ImageId     string  `bson:"imageId"`
+	Width       int     `bson:"width"`
+	ContentType string  `bson:"contentType"`
+	Image       *[]byte `bson:"image"`
 }
 
 var updateOpts = options.UpdateOne().SetUpsert(true)
@@ -82,6 +93,7 @@ func (repo *mongoDbRepository) GetFilmkritiken(ctx context.Context, filter *film
 			Value: bson.A{
 				bson.D{{Key: "film.titel", Value: bson.D{{Key: "$regex", Value: escaped}, {Key: "$options", Value: "i"}}}},
 				bson.D{{Key: "film.originaltitel", Value: bson.D{{Key: "$regex", Value: escaped}, {Key: "$options", Value: "i"}}}},
+				bson.D{{Key: "film.besetzung.name", Value: bson.D{{Key: "$regex", Value: escaped}, {Key: "$options", Value: "i"}}}},
 			},
 		})
 	}
@@ -109,6 +121,26 @@ func (repo *mongoDbRepository) GetFilmkritiken(ctx context.Context, filter *film
 		})
 	}
 
+	if filter != nil && filter.Genre != "" {
+		mongoFilter = append(mongoFilter, bson.E{Key: "film.genres", Value: equalsIgnoreCase(filter.Genre)})
+	}
+
+	if filter != nil && filter.Tag != "" {
+		mongoFilter = append(mongoFilter, bson.E{Key: "film.tags", Value: equalsIgnoreCase(filter.Tag)})
+	}
+
+	if filter != nil && filter.Darsteller != "" {
+		mongoFilter = append(mongoFilter, bson.E{Key: "film.besetzung.name", Value: equalsIgnoreCase(filter.Darsteller)})
+	}
+
+	if filter != nil && filter.Reihe != "" {
+		ids, err := repo.getReiheFilmkritikenIds(ctx, filter.Reihe)
+		if err != nil {
+			return nil, 0, err
+		}
+		mongoFilter = append(mongoFilter, bson.E{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}})
+	}
+
 	totalCount, err := repo.database.Collection(filmkritikenCollectionName).CountDocuments(ctx, mongoFilter)
 	if err != nil {
 		return nil, 0, err
@@ -177,26 +209,34 @@ func (repo *mongoDbRepository) GetFilmkritiken(ctx context.Context, filter *film
 	return results, totalCount, nil
 }
 
-func (repo *mongoDbRepository) SaveImage(ctx context.Context, imageBites *[]byte) (string, error) {
-	id := bson.NewObjectID().Hex()
+func (repo *mongoDbRepository) SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	imageBites, err := io.ReadAll(imageFile.Content)
+	if err != nil {
+		return err
+	}
 
 	image := &image{
-		ImageId: id,
-		Image:   imageBites,
+		ImageId:     imageId,
+		Image:       &imageBites,
+		ContentType: imageFile.ContentType,
 	}
 
 	filter := bson.M{"_id": bson.M{"$eq": image.ImageId}}
 	update := bson.D{bson.E{Key: "$set", Value: image}}
-	_, err := repo.database.Collection(imagesCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	_, err = repo.database.Collection(imagesCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+
+	return err
+}
 
+func (repo *mongoDbRepository) ImageExists(ctx context.Context, imageId string) (bool, error) {
+	count, err := repo.database.Collection(imagesCollectionName).CountDocuments(ctx, bson.M{"_id": bson.M{"$eq": imageId}})
 	if err != nil {
-		return "", err
+		return false, err
 	}
-
-	return id, nil
+	return count > 0, nil
 }
 
-func (repo *mongoDbRepository) FindImage(ctx context.Context, imageId string) (*[]byte, error) {
+func (repo *mongoDbRepository) FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error) {
 	mongoFilter := bson.M{"_id": bson.M{"$eq": imageId}}
 	result := &image{}
 
@@ -209,7 +249,27 @@ func (repo *mongoDbRepository) FindImage(ctx context.Context, imageId string) (*
 		return nil, err
 	}
 
-	return result.Image, nil
+	return newImageFile(imageId, imageId, result.ContentType, result.Image), nil
+}
+
+// GetImageIds returns the ids of all images stored in the images collection.
+func (repo *mongoDbRepository) GetImageIds(ctx context.Context) ([]string, error) {
+	findOptions := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := repo.database.Collection(imagesCollectionName).Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []image
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	imageIds := make([]string, 0, len(results))
+	for _, result := range results {
+		imageIds = append(imageIds, result.ImageId)
+	}
+	return imageIds, nil
 }
 
 func (repo *mongoDbRepository) DeleteImage(ctx context.Context, imageId string) error {
@@ -221,9 +281,76 @@ func (repo *mongoDbRepository) DeleteImage(ctx context.Context, imageId string)
 		return err
 	}
 
+	variantsFilter := bson.M{"imageId": bson.M{"$eq": imageId}}
+	_, err = repo.database.Collection(imageVariantsCollectionName).DeleteMany(ctx, variantsFilter)
+
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+func (repo *mongoDbRepository) SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	for _, variant := range variants {
+		doc := &imageVariant{
+			VariantId:   filmkritiken.ImageVariantId(imageId, variant.Width),
+			ImageId:     imageId,
+			Width:       variant.Width,
+			ContentType: variant.ContentType,
+			Image:       variant.Bites,
+		}
+
+		filter := bson.M{"_id": bson.M{"$eq": doc.VariantId}}
+		update := bson.D{bson.E{Key: "$set", Value: doc}}
+		_, err := repo.database.Collection(imageVariantsCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	mongoFilter := bson.M{"_id": bson.M{"$eq": filmkritiken.ImageVariantId(imageId, width)}}
+	result := &imageVariant{}
+
+	err := repo.database.Collection(imageVariantsCollectionName).FindOne(ctx, mongoFilter).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundErrorFromString("Bildvariante konnte nicht gefunden werden.")
+		}
+
+		return nil, err
+	}
+
+	return newImageFile(result.VariantId, imageId, result.ContentType, result.Image), nil
+}
+
+func newImageFile(fileId string, imageId string, contentType string, imageBites *[]byte) *filmkritiken.ImageFile {
+	var data []byte
+	if imageBites != nil {
+		data = *imageBites
+	}
+	return &filmkritiken.ImageFile{
+		Id:          fileId,
+		ContentType: contentType,
+		ModTime:     imageModTime(imageId),
+		Size:        int64(len(data)),
+		Content:     filmkritiken.NewBytesContent(data),
+	}
+}
+
+// imageModTime uses the creation time encoded in the ObjectID, as images are never modified.
+func imageModTime(imageId string) time.Time {
+	objectId, err := bson.ObjectIDFromHex(imageId)
+	if err != nil {
+		return time.Time{}
+	}
+	return objectId.Timestamp()
+}
+
 func (repo *mongoDbRepository) SaveFilmkritiken(ctx context.Context, filmkritiken *filmkritiken.Filmkritiken) error {
 
 	if filmkritiken.Id == "" {
@@ -308,8 +435,164 @@ func (repo *mongoDbRepository) GetFilterOptions(ctx context.Context) (*filmkriti
 		}
 	}
 
+	genres, err := repo.getDistinctValues(ctx, "film.genres")
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := repo.getDistinctValues(ctx, "film.tags")
+	if err != nil {
+		return nil, err
+	}
+
 	return &filmkritiken.FilterOptions{
 		Jahre:       jahre,
 		Beitragende: beitragende,
+		Genres:      genres,
+		Tags:        tags,
 	}, nil
 }
+
+// getDistinctValues returns the sorted distinct values of an array field of all filmkritiken.
+func (repo *mongoDbRepository) getDistinctValues(ctx context.Context, field string) ([]string, error) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$unwind", Value: "$" + field}},
+		bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$" + field}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+
+	cursor, err := repo.database.Collection(filmkritikenCollectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var results []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	values := make([]string, 0, len(results))
+	for _, result := range results {
+		if result.ID != "" {
+			values = append(values, result.ID)
+		}
+	}
+	return values, nil
+}
+
+func (repo *mongoDbRepository) UpdateKategorien(ctx context.Context, filmkritikenId string, genres []string, tags []string) error {
+	fk, err := repo.FindFilmkritiken(ctx, filmkritikenId)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": bson.M{"$eq": filmkritikenId}}
+	if fk.Film != nil && fk.Film.Id != "" {
+		// the Film is shared by all its screenings, so their copies are updated as well
+		filmFilter := bson.M{"_id": bson.M{"$eq": fk.Film.Id}}
+		filmUpdate := bson.D{bson.E{Key: "$set", Value: bson.D{
+			bson.E{Key: "genres", Value: genres},
+			bson.E{Key: "tags", Value: tags},
+		}}}
+		_, err = repo.database.Collection(filmeCollectionName).UpdateOne(ctx, filmFilter, filmUpdate)
+		if err != nil {
+			return err
+		}
+		filter = bson.M{"film._id": bson.M{"$eq": fk.Film.Id}}
+	}
+
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{
+		bson.E{Key: "film.genres", Value: genres},
+		bson.E{Key: "film.tags", Value: tags},
+	}}}
+	_, err = repo.database.Collection(filmkritikenCollectionName).UpdateMany(ctx, filter, update)
+	return err
+}
+
+// UpdateImagePlaceholder sets only size and blurhash of the image in all films and filmkritiken using it, so
+// concurrent changes of the rest of the documents are kept.
+func (repo *mongoDbRepository) UpdateImagePlaceholder(ctx context.Context, image *filmkritiken.Image) error {
+	filmFilter := bson.M{"image.id": bson.M{"$eq": image.Id}}
+	filmUpdate := bson.D{bson.E{Key: "$set", Value: bson.D{
+		bson.E{Key: "image.width", Value: image.Width},
+		bson.E{Key: "image.height", Value: image.Height},
+		bson.E{Key: "image.blurhash", Value: image.Blurhash},
+	}}}
+	_, err := repo.database.Collection(filmeCollectionName).UpdateMany(ctx, filmFilter, filmUpdate)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"film.image.id": bson.M{"$eq": image.Id}}
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{
+		bson.E{Key: "film.image.width", Value: image.Width},
+		bson.E{Key: "film.image.height", Value: image.Height},
+		bson.E{Key: "film.image.blurhash", Value: image.Blurhash},
+	}}}
+	_, err = repo.database.Collection(filmkritikenCollectionName).UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (repo *mongoDbRepository) UpdateBewertungenVon(ctx context.Context, vonId string, von string) error {
+	filter := bson.M{"bewertungen.vonid": bson.M{"$eq": vonId}}
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: "bewertungen.$[bewertung].von", Value: von}}}}
+	opts := options.UpdateMany().SetArrayFilters([]any{bson.M{"bewertung.vonid": bson.M{"$eq": vonId}}})
+	_, err := repo.database.Collection(filmkritikenCollectionName).UpdateMany(ctx, filter, update, opts)
+	return err
+}
+
+func (repo *mongoDbRepository) GetFilmkritikenByPerson(ctx context.Context, name string) ([]*filmkritiken.Filmkritiken, error) {
+	escaped := regexp.QuoteMeta(name)
+	mongoFilter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "film.besetzung.name", Value: equalsIgnoreCase(name)}},
+		// film.regie lists all directors separated by commas
+		bson.D{{Key: "film.regie", Value: bson.D{
+			{Key: "$regex", Value: `(^|,)\s*` + escaped + `\s*(,|$)`},
+			{Key: "$options", Value: "i"},
+		}}},
+	}}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "details.besprochenam", Value: -1}})
+
+	cursor, err := repo.database.Collection(filmkritikenCollectionName).Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*filmkritiken.Filmkritiken, 0)
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (repo *mongoDbRepository) GetFilmkritikenByMitglied(ctx context.Context, names []string) ([]*filmkritiken.Filmkritiken, error) {
+	conditions := bson.A{}
+	for _, name := range names {
+		conditions = append(conditions,
+			bson.D{{Key: "bewertungen.von", Value: equalsIgnoreCase(name)}},
+			bson.D{{Key: "details.beitragvon", Value: equalsIgnoreCase(name)}},
+		)
+	}
+	mongoFilter := bson.D{{Key: "$or", Value: conditions}}
+
+	cursor, err := repo.database.Collection(filmkritikenCollectionName).Find(ctx, mongoFilter)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*filmkritiken.Filmkritiken, 0)
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func equalsIgnoreCase(value string) bson.D {
+	return bson.D{
+		{Key: "$regex", Value: "^" + regexp.QuoteMeta(value) + "$"},
+		{Key: "$options", Value: "i"},
+	}
+}
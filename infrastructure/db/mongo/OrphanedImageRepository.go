@@ -0,0 +1,46 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const orphanedImagesCollectionName = "orphanedimages"
+
+type orphanedImage struct {
+	ImageId string    `bson:"_id"`
+	Since   time.Time `bson:"since"`
+}
+
+func (repo *mongoDbRepository) GetOrphanedImages(ctx context.Context) (map[string]time.Time, error) {
+	cursor, err := repo.database.Collection(orphanedImagesCollectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	var orphanedImages []*orphanedImage
+	if err := cursor.All(ctx, &orphanedImages); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]time.Time, len(orphanedImages))
+	for _, orphaned := range orphanedImages {
+		result[orphaned.ImageId] = orphaned.Since
+	}
+	return result, nil
+}
+
+func (repo *mongoDbRepository) MarkOrphanedImage(ctx context.Context, imageId string, since time.Time) error {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}}
+	update := bson.D{bson.E{Key: "$setOnInsert", Value: bson.M{"since": since}}}
+	_, err := repo.database.Collection(orphanedImagesCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	return err
+}
+
+func (repo *mongoDbRepository) UnmarkOrphanedImage(ctx context.Context, imageId string) error {
+	filter := bson.M{"_id": bson.M{"$eq": imageId}}
+	_, err := repo.database.Collection(orphanedImagesCollectionName).DeleteOne(ctx, filter)
+	return err
+}
@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const reihenCollectionName = "reihen"
+
+func (repo *mongoDbRepository) FindReihe(ctx context.Context, reiheId string) (*filmkritiken.Reihe, error) {
+	mongoFilter := bson.M{"_id": bson.M{"$eq": reiheId}}
+	result := &filmkritiken.Reihe{}
+
+	err := repo.database.Collection(reihenCollectionName).FindOne(ctx, mongoFilter).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundErrorFromString("Reihe konnte nicht gefunden werden.")
+		}
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (repo *mongoDbRepository) GetReihen(ctx context.Context) ([]*filmkritiken.Reihe, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	cursor, err := repo.database.Collection(reihenCollectionName).Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*filmkritiken.Reihe, 0)
+	err = cursor.All(ctx, &results)
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+func (repo *mongoDbRepository) SaveReihe(ctx context.Context, reihe *filmkritiken.Reihe) error {
+	if reihe.Id == "" {
+		reihe.Id = bson.NewObjectID().Hex()
+	}
+
+	filter := bson.M{"_id": bson.M{"$eq": reihe.Id}}
+	update := bson.D{bson.E{Key: "$set", Value: reihe}}
+	_, err := repo.database.Collection(reihenCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+
+	return err
+}
+
+// getReiheFilmkritikenIds returns the ids of all Filmkritiken of the Reihe, none if the Reihe does not exist.
+func (repo *mongoDbRepository) getReiheFilmkritikenIds(ctx context.Context, reiheId string) ([]string, error) {
+	reihe, err := repo.FindReihe(ctx, reiheId)
+	if err != nil {
+		if _, ok := err.(*errors.NotFoundError); ok {
+			return make([]string, 0), nil
+		}
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(reihe.Eintraege))
+	for _, eintrag := range reihe.Eintraege {
+		ids = append(ids, eintrag.FilmkritikenId)
+	}
+	return ids, nil
+}
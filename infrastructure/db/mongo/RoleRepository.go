@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const rolesCollectionName = "roles"
+
+func (repo *mongoDbRepository) GetRoles(ctx context.Context) ([]*session.Role, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cursor, err := repo.database.Collection(rolesCollectionName).Find(ctx, bson.M{}, findOptions)
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	results := make([]*session.Role, 0)
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	return results, nil
+}
+
+func (repo *mongoDbRepository) SaveRole(ctx context.Context, role *session.Role) error {
+	filter := bson.M{"_id": bson.M{"$eq": role.Name}}
+	update := bson.D{bson.E{Key: "$set", Value: role}}
+	_, err := repo.database.Collection(rolesCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) DeleteRole(ctx context.Context, name string) error {
+	filter := bson.M{"_id": bson.M{"$eq": name}}
+	result, err := repo.database.Collection(rolesCollectionName).DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+	if result.DeletedCount == 0 {
+		return errors.NewNotFoundErrorFromString("Rolle nicht gefunden.")
+	}
+
+	return nil
+}
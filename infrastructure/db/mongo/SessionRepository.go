@@ -21,7 +21,18 @@ func (repo *mongoDbRepository) ensureIndexes(ctx context.Context) error {
 		Options: options.Index().SetExpireAfterSeconds(0),
 	}
 	_, err := repo.database.Collection(sessionsCollectionName).Indexes().CreateOne(ctx, indexModel)
-	return err
+	if err != nil {
+		return err
+	}
+	userIndexModel := mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}},
+	}
+	_, err = repo.database.Collection(sessionsCollectionName).Indexes().CreateOne(ctx, userIndexModel)
+	if err != nil {
+		return err
+	}
+
+	return repo.ensureTokenIndexes(ctx)
 }
 
 func (repo *mongoDbRepository) SaveSession(ctx context.Context, s *session.Session) error {
@@ -31,15 +42,26 @@ func (repo *mongoDbRepository) SaveSession(ctx context.Context, s *session.Sessi
 	if s.CreatedAt.IsZero() {
 		s.CreatedAt = time.Now()
 	}
+	if s.LastSeenAt.IsZero() {
+		s.LastSeenAt = s.CreatedAt
+	}
 
 	hashedID := session.HashSessionID(s.ID)
 
 	doc := bson.M{
-		"_id":         hashedID,
-		"name":        s.Name,
-		"permissions": s.Permissions,
-		"expiresAt":   s.ExpiresAt,
-		"createdAt":   s.CreatedAt,
+		"_id":          hashedID,
+		"userId":       s.UserID,
+		"provider":     s.Provider,
+		"name":         s.Name,
+		"permissions":  s.Permissions,
+		"roles":        s.Roles,
+		"expiresAt":    s.ExpiresAt,
+		"createdAt":    s.CreatedAt,
+		"userAgent":    s.UserAgent,
+		"ip":           s.IP,
+		"lastSeenAt":   s.LastSeenAt,
+		"refreshToken": s.RefreshToken,
+		"refreshedAt":  s.RefreshedAt,
 	}
 
 	filter := bson.M{"_id": bson.M{"$eq": hashedID}}
@@ -114,3 +136,78 @@ func (repo *mongoDbRepository) RefreshSession(ctx context.Context, sessionID str
 
 	return nil
 }
+
+func (repo *mongoDbRepository) UpdateRefreshedSession(ctx context.Context, s *session.Session, previousRefreshToken string) (bool, error) {
+	// never upserted, a refresh must not bring back a session deleted in the meantime
+	filter := bson.M{
+		"_id":          bson.M{"$eq": session.HashSessionID(s.ID)},
+		"refreshToken": bson.M{"$eq": previousRefreshToken},
+	}
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{
+		bson.E{Key: "name", Value: s.Name},
+		bson.E{Key: "permissions", Value: s.Permissions},
+		bson.E{Key: "roles", Value: s.Roles},
+		bson.E{Key: "refreshToken", Value: s.RefreshToken},
+		bson.E{Key: "refreshedAt", Value: s.RefreshedAt},
+	}}}
+
+	result, err := repo.database.Collection(sessionsCollectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, errors.NewRepositoryError(err)
+	}
+
+	return result.MatchedCount == 1, nil
+}
+
+func (repo *mongoDbRepository) UpdateSessionLastSeen(ctx context.Context, sessionID string, lastSeenAt time.Time, ip string) error {
+	hashedID := session.HashSessionID(sessionID)
+	filter := bson.M{"_id": bson.M{"$eq": hashedID}}
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{
+		bson.E{Key: "lastSeenAt", Value: lastSeenAt},
+		bson.E{Key: "ip", Value: ip},
+	}}}
+	_, err := repo.database.Collection(sessionsCollectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) GetSessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	mongoFilter := bson.M{"userId": bson.M{"$eq": userID}, "expiresAt": bson.M{"$gt": time.Now()}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "lastSeenAt", Value: -1}})
+	cursor, err := repo.database.Collection(sessionsCollectionName).Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	results := make([]*session.Session, 0)
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	return results, nil
+}
+
+func (repo *mongoDbRepository) DeleteSessionByHash(ctx context.Context, userID string, hashedID string) error {
+	filter := bson.M{"_id": bson.M{"$eq": hashedID}, "userId": bson.M{"$eq": userID}}
+	result, err := repo.database.Collection(sessionsCollectionName).DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+	if result.DeletedCount == 0 {
+		return errors.NewNotFoundErrorFromString("Session nicht gefunden.")
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) DeleteSessions(ctx context.Context, userID string) (int64, error) {
+	filter := bson.M{"userId": bson.M{"$eq": userID}}
+	result, err := repo.database.Collection(sessionsCollectionName).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, errors.NewRepositoryError(err)
+	}
+
+	return result.DeletedCount, nil
+}
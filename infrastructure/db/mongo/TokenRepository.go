@@ -0,0 +1,104 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const tokensCollectionName = "tokens"
+
+func (repo *mongoDbRepository) ensureTokenIndexes(ctx context.Context) error {
+	indexModels := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	_, err := repo.database.Collection(tokensCollectionName).Indexes().CreateMany(ctx, indexModels)
+	return err
+}
+
+func (repo *mongoDbRepository) SaveToken(ctx context.Context, token *session.Token) error {
+	filter := bson.M{"_id": bson.M{"$eq": token.ID}}
+	update := bson.D{bson.E{Key: "$set", Value: token}}
+	_, err := repo.database.Collection(tokensCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) FindTokenByHash(ctx context.Context, hash string) (*session.Token, error) {
+	mongoFilter := bson.M{"hash": bson.M{"$eq": hash}}
+	result := &session.Token{}
+
+	err := repo.database.Collection(tokensCollectionName).FindOne(ctx, mongoFilter).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundErrorFromString("Token nicht gefunden.")
+		}
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	return result, nil
+}
+
+func (repo *mongoDbRepository) GetTokens(ctx context.Context, userID string) ([]*session.Token, error) {
+	mongoFilter := bson.M{"userId": bson.M{"$eq": userID}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	cursor, err := repo.database.Collection(tokensCollectionName).Find(ctx, mongoFilter, findOptions)
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	results := make([]*session.Token, 0)
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	return results, nil
+}
+
+func (repo *mongoDbRepository) DeleteToken(ctx context.Context, userID string, tokenID string) error {
+	filter := bson.M{"_id": bson.M{"$eq": tokenID}, "userId": bson.M{"$eq": userID}}
+	result, err := repo.database.Collection(tokensCollectionName).DeleteOne(ctx, filter)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+	if result.DeletedCount == 0 {
+		return errors.NewNotFoundErrorFromString("Token nicht gefunden.")
+	}
+
+	return nil
+}
+
+func (repo *mongoDbRepository) DeleteTokens(ctx context.Context, userID string) (int64, error) {
+	filter := bson.M{"userId": bson.M{"$eq": userID}}
+	result, err := repo.database.Collection(tokensCollectionName).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, errors.NewRepositoryError(err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (repo *mongoDbRepository) UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt time.Time) error {
+	filter := bson.M{"_id": bson.M{"$eq": tokenID}}
+	update := bson.D{bson.E{Key: "$set", Value: bson.D{bson.E{Key: "lastUsedAt", Value: lastUsedAt}}}}
+	_, err := repo.database.Collection(tokensCollectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/session"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const usersCollectionName = "users"
+
+func (repo *mongoDbRepository) FindUser(ctx context.Context, userID string) (*session.User, error) {
+	mongoFilter := bson.M{"_id": bson.M{"$eq": userID}}
+	result := &session.User{}
+
+	err := repo.database.Collection(usersCollectionName).FindOne(ctx, mongoFilter).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.NewNotFoundErrorFromString("Benutzer nicht gefunden.")
+		}
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	return result, nil
+}
+
+func (repo *mongoDbRepository) BenutzerExists(ctx context.Context, vonId string) (bool, error) {
+	count, err := repo.database.Collection(usersCollectionName).CountDocuments(ctx, bson.M{"_id": bson.M{"$eq": vonId}})
+	if err != nil {
+		return false, errors.NewRepositoryError(err)
+	}
+	return count > 0, nil
+}
+
+func (repo *mongoDbRepository) MergeBenutzer(ctx context.Context, benutzerId string, mergedBenutzerId string, names []string) error {
+	if mergedBenutzerId != "" {
+		mergedUser, err := repo.FindUser(ctx, mergedBenutzerId)
+		if err == nil {
+			names = append(names, mergedUser.Names...)
+		} else if _, ok := err.(*errors.NotFoundError); !ok {
+			return err
+		}
+	}
+
+	filter := bson.M{"_id": bson.M{"$eq": benutzerId}}
+	update := bson.M{"$addToSet": bson.M{"names": bson.M{"$each": names}}}
+	_, err := repo.database.Collection(usersCollectionName).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+	return nil
+}
+
+func (repo *mongoDbRepository) GetUsers(ctx context.Context) ([]*session.User, error) {
+	cursor, err := repo.database.Collection(usersCollectionName).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+
+	results := make([]*session.User, 0)
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, errors.NewRepositoryError(err)
+	}
+	return results, nil
+}
+
+func (repo *mongoDbRepository) SaveUser(ctx context.Context, user *session.User) error {
+	filter := bson.M{"_id": bson.M{"$eq": user.ID}}
+	update := bson.D{bson.E{Key: "$set", Value: user}}
+	_, err := repo.database.Collection(usersCollectionName).UpdateOne(ctx, filter, update, updateOpts)
+	if err != nil {
+		return errors.NewRepositoryError(err)
+	}
+
+	return nil
+}
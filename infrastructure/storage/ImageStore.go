@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage/filesystem"
+	"github.com/DerBlum/filmkritiken-backend/infrastructure/storage/s3"
+)
+
+const (
+	// ImageStoreMongo is the legacy store keeping every image as a document in the images collection.
+	ImageStoreMongo      = "mongo"
+	ImageStoreGridFs     = "gridfs"
+	ImageStoreFilesystem = "filesystem"
+	ImageStoreS3         = "s3"
+)
+
+type Config struct {
+	ImageStore string `env:"IMAGE_STORE" envDefault:"gridfs"`
+	Filesystem filesystem.Config
+	S3         s3.Config
+}
+
+// ImageStore is an ImageRepository that can be used as source and target of cmd/migrate-images.
+type ImageStore interface {
+	filmkritiken.ImageRepository
+}
+
+// NewImageStore returns the image store of the given kind (one of the ImageStore* constants).
+// The mongo based stores share the connection of the caller, so they are passed in.
+func NewImageStore(ctx context.Context, kind string, config *Config, mongoStore ImageStore, gridFsStore ImageStore) (ImageStore, error) {
+	switch kind {
+	case ImageStoreMongo:
+		return mongoStore, nil
+	case ImageStoreGridFs:
+		return gridFsStore, nil
+	case ImageStoreFilesystem:
+		return filesystem.NewFilesystemImageRepository(&config.Filesystem)
+	case ImageStoreS3:
+		return s3.NewS3ImageRepository(ctx, &config.S3)
+	default:
+		return nil, fmt.Errorf("unknown image store %q", kind)
+	}
+}
@@ -0,0 +1,211 @@
+package filesystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	log "github.com/sirupsen/logrus"
+)
+
+const metadataFileSuffix = ".json"
+
+// ids are generated by us, but end up in file paths -> never allow anything but plain ids
+var validFileId = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+type Config struct {
+	Directory string `env:"IMAGE_STORE_DIRECTORY" envDefault:"./data/images"`
+}
+
+type fileMetadata struct {
+	ContentType string `json:"contentType"`
+}
+
+// filesystemImageRepository stores every image and variant as a file in one directory,
+// next to a small JSON file holding the content type.
+type filesystemImageRepository struct {
+	directory string
+}
+
+func NewFilesystemImageRepository(config *Config) (*filesystemImageRepository, error) {
+	if err := os.MkdirAll(config.Directory, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &filesystemImageRepository{
+		directory: config.Directory,
+	}, nil
+}
+
+func (repo *filesystemImageRepository) SaveImage(_ context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	return repo.writeFile(imageId, imageFile.ContentType, imageFile.Content)
+}
+
+func (repo *filesystemImageRepository) SaveImageVariants(_ context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	for _, variant := range variants {
+		fileId := filmkritiken.ImageVariantId(imageId, variant.Width)
+		err := repo.writeFile(fileId, variant.ContentType, filmkritiken.NewBytesContent(*variant.Bites))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *filesystemImageRepository) FindImage(_ context.Context, imageId string) (*filmkritiken.ImageFile, error) {
+	return repo.openFile(imageId, "Bild konnte nicht gefunden werden.")
+}
+
+func (repo *filesystemImageRepository) FindImageVariant(_ context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	return repo.openFile(filmkritiken.ImageVariantId(imageId, width), "Bildvariante konnte nicht gefunden werden.")
+}
+
+func (repo *filesystemImageRepository) ImageExists(_ context.Context, imageId string) (bool, error) {
+	path, err := repo.path(imageId)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(path)
+	if stdErrors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (repo *filesystemImageRepository) GetImageIds(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(repo.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	imageIds := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, metadataFileSuffix) || strings.Contains(name, "_") || !validFileId.MatchString(name) {
+			continue
+		}
+		imageIds = append(imageIds, name)
+	}
+	return imageIds, nil
+}
+
+func (repo *filesystemImageRepository) DeleteImage(_ context.Context, imageId string) error {
+	fileIds := []string{imageId}
+	for _, width := range filmkritiken.ImageVariantWidths {
+		fileIds = append(fileIds, filmkritiken.ImageVariantId(imageId, width))
+	}
+
+	for _, fileId := range fileIds {
+		path, err := repo.path(fileId)
+		if err != nil {
+			return err
+		}
+		for _, p := range []string{path, path + metadataFileSuffix} {
+			if err := os.Remove(p); err != nil && !stdErrors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (repo *filesystemImageRepository) writeFile(fileId string, contentType string, content io.Reader) error {
+	path, err := repo.path(fileId)
+	if err != nil {
+		return err
+	}
+
+	metadata, err := json.Marshal(&fileMetadata{ContentType: contentType})
+	if err != nil {
+		return err
+	}
+	if err := writeAtomically(path+metadataFileSuffix, bytes.NewReader(metadata)); err != nil {
+		return err
+	}
+
+	return writeAtomically(path, content)
+}
+
+func (repo *filesystemImageRepository) openFile(fileId string, notFoundMessage string) (*filmkritiken.ImageFile, error) {
+	path, err := repo.path(fileId)
+	if err != nil {
+		return nil, errors.NewNotFoundErrorFromString(notFoundMessage)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if stdErrors.Is(err, fs.ErrNotExist) {
+			return nil, errors.NewNotFoundErrorFromString(notFoundMessage)
+		}
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	metadata := &fileMetadata{}
+	if metadataBites, err := os.ReadFile(path + metadataFileSuffix); err == nil {
+		if err := json.Unmarshal(metadataBites, metadata); err != nil {
+			log.Warnf("metadata of image %s is corrupt, detecting the content type: %v", fileId, err)
+		}
+	} else if !stdErrors.Is(err, fs.ErrNotExist) {
+		log.Warnf("could not read metadata of image %s, detecting the content type: %v", fileId, err)
+	}
+
+	imageFile := &filmkritiken.ImageFile{
+		Id:          fileId,
+		ContentType: metadata.ContentType,
+		ModTime:     info.ModTime(),
+		Size:        info.Size(),
+		Content:     file,
+	}
+	// without metadata the content type is sniffed with http.DetectContentType
+	imageFile.ContentType, err = filmkritiken.DetectImageContentType(imageFile)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return imageFile, nil
+}
+
+func (repo *filesystemImageRepository) path(fileId string) (string, error) {
+	if !validFileId.MatchString(fileId) {
+		return "", fmt.Errorf("invalid image id %q", fileId)
+	}
+	return filepath.Join(repo.directory, fileId), nil
+}
+
+// writeAtomically writes to a temporary file first, so readers never see partially written images.
+func writeAtomically(path string, content io.Reader) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := io.Copy(tmpFile, content); err != nil {
+		_ = tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
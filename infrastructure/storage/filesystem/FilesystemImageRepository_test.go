@@ -0,0 +1,190 @@
+package filesystem
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+func newTestRepository(t *testing.T) *filesystemImageRepository {
+	t.Helper()
+	repo, err := NewFilesystemImageRepository(&Config{Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("could not create repository: %v", err)
+	}
+	return repo
+}
+
+func readContent(t *testing.T, imageFile *filmkritiken.ImageFile) string {
+	t.Helper()
+	defer imageFile.Content.Close()
+	content, err := io.ReadAll(imageFile.Content)
+	if err != nil {
+		t.Fatalf("could not read image: %v", err)
+	}
+	return string(content)
+}
+
+func TestFilesystemImageRepository_SaveAndFind(t *testing.T) {
+	// given
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	// when
+	err := repo.SaveImage(ctx, "image1", filmkritiken.NewImageFile("image/jpeg", []byte("original")))
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	imageFile, err := repo.FindImage(ctx, "image1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imageFile.Id != "image1" || imageFile.ContentType != "image/jpeg" || imageFile.Size != 8 {
+		t.Errorf("unexpected image %+v", imageFile)
+	}
+	if content := readContent(t, imageFile); content != "original" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	imageIds, err := repo.GetImageIds(ctx)
+	if err != nil || len(imageIds) != 1 || imageIds[0] != "image1" {
+		t.Errorf("expected only the original in the image ids, got %v (%v)", imageIds, err)
+	}
+}
+
+func TestFilesystemImageRepository_ImageExists(t *testing.T) {
+	// given
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	if err := repo.SaveImage(ctx, "image1", filmkritiken.NewImageFile("image/jpeg", []byte("original"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	exists, err := repo.ImageExists(ctx, "image1")
+	missing, missingErr := repo.ImageExists(ctx, "image2")
+
+	// then
+	if err != nil || !exists {
+		t.Errorf("expected image1 to exist, got %v (%v)", exists, err)
+	}
+	if missingErr != nil || missing {
+		t.Errorf("expected image2 to be missing, got %v (%v)", missing, missingErr)
+	}
+}
+
+func TestFilesystemImageRepository_Variants(t *testing.T) {
+	// given
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	small := []byte("small")
+	large := []byte("large")
+
+	// when
+	err := repo.SaveImageVariants(ctx, "image1", []*filmkritiken.ImageVariant{
+		{Width: 200, ContentType: "image/png", Bites: &small},
+		{Width: 500, ContentType: "image/png", Bites: &large},
+	})
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	variant, err := repo.FindImageVariant(ctx, "image1", 500)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if variant.Id != "image1_w500" || variant.ContentType != "image/png" {
+		t.Errorf("unexpected variant %+v", variant)
+	}
+	if content := readContent(t, variant); content != "large" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	imageIds, err := repo.GetImageIds(ctx)
+	if err != nil || len(imageIds) != 0 {
+		t.Errorf("expected variants not to be listed as images, got %v (%v)", imageIds, err)
+	}
+}
+
+func TestFilesystemImageRepository_Delete(t *testing.T) {
+	// given
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	variantBites := []byte("small")
+	if err := repo.SaveImage(ctx, "image1", filmkritiken.NewImageFile("image/jpeg", []byte("original"))); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveImageVariants(ctx, "image1", []*filmkritiken.ImageVariant{{Width: 200, ContentType: "image/jpeg", Bites: &variantBites}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	err := repo.DeleteImage(ctx, "image1")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries, _ := os.ReadDir(repo.directory)
+	if len(entries) != 0 {
+		t.Errorf("expected image, variant and metadata to be deleted, found %d files", len(entries))
+	}
+	// deleting a missing image is not an error
+	if err := repo.DeleteImage(ctx, "image1"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestFilesystemImageRepository_NotFound(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+
+	for name, find := range map[string]func() (*filmkritiken.ImageFile, error){
+		"image":      func() (*filmkritiken.ImageFile, error) { return repo.FindImage(ctx, "missing") },
+		"variant":    func() (*filmkritiken.ImageFile, error) { return repo.FindImageVariant(ctx, "missing", 200) },
+		"invalid id": func() (*filmkritiken.ImageFile, error) { return repo.FindImage(ctx, "../secret") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := find()
+			if _, ok := err.(*errors.NotFoundError); !ok {
+				t.Errorf("expected NotFoundError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilesystemImageRepository_CorruptMetadata(t *testing.T) {
+	// given
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	if err := repo.SaveImage(ctx, "image1", filmkritiken.NewImageFile("image/png", pngHeader)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo.directory, "image1"+metadataFileSuffix), []byte("{corrupt"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	imageFile, err := repo.FindImage(ctx, "image1")
+
+	// then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if imageFile.ContentType != "image/png" {
+		t.Errorf("expected detected content type image/png, got %q", imageFile.ContentType)
+	}
+	if content := readContent(t, imageFile); content != string(pngHeader) {
+		t.Errorf("expected content to be read from the start, got %q", content)
+	}
+}
@@ -0,0 +1,165 @@
+package s3
+
+import (
+	"context"
+	"strings"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type Config struct {
+	Endpoint  string `env:"S3_ENDPOINT"`
+	Region    string `env:"S3_REGION"`
+	Bucket    string `env:"S3_BUCKET" envDefault:"filmkritiken"`
+	Prefix    string `env:"S3_PREFIX" envDefault:"images/"`
+	AccessKey string `env:"S3_ACCESS_KEY"`
+	SecretKey string `env:"S3_SECRET_KEY,unset"`
+	UseSSL    bool   `env:"S3_USE_SSL" envDefault:"true"`
+}
+
+// s3ImageRepository stores images and variants as objects in an S3 compatible object storage
+// (AWS S3, MinIO, ...). The content type is stored as the object's Content-Type.
+type s3ImageRepository struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+func NewS3ImageRepository(ctx context.Context, config *Config) (*s3ImageRepository, error) {
+	client, err := minio.New(config.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(config.AccessKey, config.SecretKey, ""),
+		Secure: config.UseSSL,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err = client.MakeBucket(ctx, config.Bucket, minio.MakeBucketOptions{Region: config.Region})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &s3ImageRepository{
+		client: client,
+		bucket: config.Bucket,
+		prefix: config.Prefix,
+	}, nil
+}
+
+func (repo *s3ImageRepository) SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	_, err := repo.client.PutObject(ctx, repo.bucket, repo.key(imageId), imageFile.Content, imageFile.Size, minio.PutObjectOptions{
+		ContentType: imageFile.ContentType,
+	})
+	return err
+}
+
+func (repo *s3ImageRepository) SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	for _, variant := range variants {
+		key := repo.key(filmkritiken.ImageVariantId(imageId, variant.Width))
+		_, err := repo.client.PutObject(ctx, repo.bucket, key, filmkritiken.NewBytesContent(*variant.Bites), int64(len(*variant.Bites)), minio.PutObjectOptions{
+			ContentType: variant.ContentType,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *s3ImageRepository) FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error) {
+	return repo.getObject(ctx, imageId, "Bild konnte nicht gefunden werden.")
+}
+
+func (repo *s3ImageRepository) FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	return repo.getObject(ctx, filmkritiken.ImageVariantId(imageId, width), "Bildvariante konnte nicht gefunden werden.")
+}
+
+func (repo *s3ImageRepository) ImageExists(ctx context.Context, imageId string) (bool, error) {
+	_, err := repo.client.StatObject(ctx, repo.bucket, repo.key(imageId), minio.StatObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (repo *s3ImageRepository) GetImageIds(ctx context.Context) ([]string, error) {
+	imageIds := make([]string, 0)
+	for object := range repo.client.ListObjects(ctx, repo.bucket, minio.ListObjectsOptions{Prefix: repo.prefix}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		fileId := strings.TrimPrefix(object.Key, repo.prefix)
+		if fileId == "" || strings.Contains(fileId, "_") || strings.Contains(fileId, "/") {
+			continue
+		}
+		imageIds = append(imageIds, fileId)
+	}
+	return imageIds, nil
+}
+
+func (repo *s3ImageRepository) DeleteImage(ctx context.Context, imageId string) error {
+	fileIds := []string{imageId}
+	for _, width := range filmkritiken.ImageVariantWidths {
+		fileIds = append(fileIds, filmkritiken.ImageVariantId(imageId, width))
+	}
+
+	for _, fileId := range fileIds {
+		// removing a missing object is not an error in S3
+		err := repo.client.RemoveObject(ctx, repo.bucket, repo.key(fileId), minio.RemoveObjectOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *s3ImageRepository) getObject(ctx context.Context, fileId string, notFoundMessage string) (*filmkritiken.ImageFile, error) {
+	object, err := repo.client.GetObject(ctx, repo.bucket, repo.key(fileId), minio.GetObjectOptions{})
+	if err != nil {
+		if isNotFound(err) {
+			return nil, errors.NewNotFoundErrorFromString(notFoundMessage)
+		}
+		return nil, err
+	}
+
+	info, err := object.Stat()
+	if err != nil {
+		_ = object.Close()
+		if isNotFound(err) {
+			return nil, errors.NewNotFoundErrorFromString(notFoundMessage)
+		}
+		return nil, err
+	}
+
+	return &filmkritiken.ImageFile{
+		Id:          fileId,
+		ContentType: info.ContentType,
+		ModTime:     info.LastModified,
+		Size:        info.Size,
+		Content:     object,
+	}, nil
+}
+
+func (repo *s3ImageRepository) key(fileId string) string {
+	return repo.prefix + fileId
+}
+
+func isNotFound(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == minio.NoSuchKey || code == "NotFound"
+}
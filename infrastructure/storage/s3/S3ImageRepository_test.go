@@ -0,0 +1,121 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/DerBlum/filmkritiken-backend/domain/errors"
+	"github.com/DerBlum/filmkritiken-backend/domain/filmkritiken"
+)
+
+// newTestRepository connects to the MinIO of S3_TEST_ENDPOINT (e.g. localhost:9000 of docker compose up minio),
+// the test is skipped without it. Every test uses its own prefix, so runs don't see each other's objects.
+func newTestRepository(t *testing.T) *s3ImageRepository {
+	t.Helper()
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+
+	config := &Config{
+		Endpoint:  endpoint,
+		Bucket:    "filmkritiken-test",
+		Prefix:    fmt.Sprintf("test-%d/", time.Now().UnixNano()),
+		AccessKey: envOrDefault("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOrDefault("S3_TEST_SECRET_KEY", "minioadmin"),
+		UseSSL:    os.Getenv("S3_TEST_USE_SSL") == "true",
+	}
+	repo, err := NewS3ImageRepository(context.Background(), config)
+	if err != nil {
+		t.Fatalf("could not connect to %s: %v", endpoint, err)
+	}
+	return repo
+}
+
+func envOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func TestS3ImageRepository(t *testing.T) {
+	repo := newTestRepository(t)
+	ctx := context.Background()
+	variantBites := []byte("small")
+	t.Cleanup(func() { _ = repo.DeleteImage(ctx, "image1") })
+
+	t.Run("save and find image with variant", func(t *testing.T) {
+		if err := repo.SaveImage(ctx, "image1", filmkritiken.NewImageFile("image/jpeg", []byte("original"))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := repo.SaveImageVariants(ctx, "image1", []*filmkritiken.ImageVariant{{Width: 200, ContentType: "image/png", Bites: &variantBites}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		imageFile, err := repo.FindImage(ctx, "image1")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content, _ := io.ReadAll(imageFile.Content)
+		_ = imageFile.Content.Close()
+		if imageFile.ContentType != "image/jpeg" || string(content) != "original" {
+			t.Errorf("unexpected image %+v with content %q", imageFile, content)
+		}
+
+		variant, err := repo.FindImageVariant(ctx, "image1", 200)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		content, _ = io.ReadAll(variant.Content)
+		_ = variant.Content.Close()
+		if variant.ContentType != "image/png" || string(content) != "small" {
+			t.Errorf("unexpected variant %+v with content %q", variant, content)
+		}
+	})
+
+	t.Run("exists and image ids", func(t *testing.T) {
+		exists, err := repo.ImageExists(ctx, "image1")
+		if err != nil || !exists {
+			t.Errorf("expected image1 to exist, got %v (%v)", exists, err)
+		}
+		exists, err = repo.ImageExists(ctx, "missing")
+		if err != nil || exists {
+			t.Errorf("expected missing image, got %v (%v)", exists, err)
+		}
+
+		imageIds, err := repo.GetImageIds(ctx)
+		if err != nil || len(imageIds) != 1 || imageIds[0] != "image1" {
+			t.Errorf("expected only the original in the image ids, got %v (%v)", imageIds, err)
+		}
+	})
+
+	t.Run("missing image returns NotFoundError", func(t *testing.T) {
+		_, err := repo.FindImage(ctx, "missing")
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+		_, err = repo.FindImageVariant(ctx, "image1", 500)
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			t.Errorf("expected NotFoundError, got %v", err)
+		}
+	})
+
+	t.Run("delete removes image and variants", func(t *testing.T) {
+		if err := repo.DeleteImage(ctx, "image1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err := repo.FindImageVariant(ctx, "image1", 200)
+		if _, ok := err.(*errors.NotFoundError); !ok {
+			t.Errorf("expected variant to be deleted, got %v", err)
+		}
+		exists, err := repo.ImageExists(ctx, "image1")
+		if err != nil || exists {
+			t.Errorf("expected image to be deleted, got %v (%v)", exists, err)
+		}
+	})
+}
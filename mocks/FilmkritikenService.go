@@ -51,6 +51,51 @@ func (mr *MockFilmkritikenServiceMockRecorder) CreateFilm(ctx, film, filmkritike
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFilm", reflect.TypeOf((*MockFilmkritikenService)(nil).CreateFilm), ctx, film, filmkritikenDetails, imageBites)
 }
 
+// CreateReihe mocks base method.
+func (m *MockFilmkritikenService) CreateReihe(ctx context.Context, name string) (*filmkritiken.Reihe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReihe", ctx, name)
+	ret0, _ := ret[0].(*filmkritiken.Reihe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReihe indicates an expected call of CreateReihe.
+func (mr *MockFilmkritikenServiceMockRecorder) CreateReihe(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReihe", reflect.TypeOf((*MockFilmkritikenService)(nil).CreateReihe), ctx, name)
+}
+
+// DownloadPoster mocks base method.
+func (m *MockFilmkritikenService) DownloadPoster(ctx context.Context, posterRef string) (*[]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadPoster", ctx, posterRef)
+	ret0, _ := ret[0].(*[]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadPoster indicates an expected call of DownloadPoster.
+func (mr *MockFilmkritikenServiceMockRecorder) DownloadPoster(ctx, posterRef interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadPoster", reflect.TypeOf((*MockFilmkritikenService)(nil).DownloadPoster), ctx, posterRef)
+}
+
+// GetFilm mocks base method.
+func (m *MockFilmkritikenService) GetFilm(ctx context.Context, filmId string) (*filmkritiken.FilmUebersicht, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilm", ctx, filmId)
+	ret0, _ := ret[0].(*filmkritiken.FilmUebersicht)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilm indicates an expected call of GetFilm.
+func (mr *MockFilmkritikenServiceMockRecorder) GetFilm(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilm", reflect.TypeOf((*MockFilmkritikenService)(nil).GetFilm), ctx, filmId)
+}
+
 // GetFilmkritikById mocks base method.
 func (m *MockFilmkritikenService) GetFilmkritikById(ctx context.Context, id string) (*filmkritiken.Filmkritiken, error) {
 	m.ctrl.T.Helper()
@@ -97,19 +142,94 @@ func (mr *MockFilmkritikenServiceMockRecorder) GetFilterOptions(ctx interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilterOptions", reflect.TypeOf((*MockFilmkritikenService)(nil).GetFilterOptions), ctx)
 }
 
+// GetMitgliederStatistiken mocks base method.
+func (m *MockFilmkritikenService) GetMitgliederStatistiken(ctx context.Context) ([]*filmkritiken.MitgliedStatistik, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMitgliederStatistiken", ctx)
+	ret0, _ := ret[0].([]*filmkritiken.MitgliedStatistik)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMitgliederStatistiken indicates an expected call of GetMitgliederStatistiken.
+func (mr *MockFilmkritikenServiceMockRecorder) GetMitgliederStatistiken(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMitgliederStatistiken", reflect.TypeOf((*MockFilmkritikenService)(nil).GetMitgliederStatistiken), ctx)
+}
+
+// GetPerson mocks base method.
+func (m *MockFilmkritikenService) GetPerson(ctx context.Context, name string) (*filmkritiken.Person, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPerson", ctx, name)
+	ret0, _ := ret[0].(*filmkritiken.Person)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPerson indicates an expected call of GetPerson.
+func (mr *MockFilmkritikenServiceMockRecorder) GetPerson(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPerson", reflect.TypeOf((*MockFilmkritikenService)(nil).GetPerson), ctx, name)
+}
+
+// GetReihe mocks base method.
+func (m *MockFilmkritikenService) GetReihe(ctx context.Context, reiheId string) (*filmkritiken.ReiheUebersicht, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReihe", ctx, reiheId)
+	ret0, _ := ret[0].(*filmkritiken.ReiheUebersicht)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReihe indicates an expected call of GetReihe.
+func (mr *MockFilmkritikenServiceMockRecorder) GetReihe(ctx, reiheId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReihe", reflect.TypeOf((*MockFilmkritikenService)(nil).GetReihe), ctx, reiheId)
+}
+
+// GetReihen mocks base method.
+func (m *MockFilmkritikenService) GetReihen(ctx context.Context) ([]*filmkritiken.Reihe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReihen", ctx)
+	ret0, _ := ret[0].([]*filmkritiken.Reihe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReihen indicates an expected call of GetReihen.
+func (mr *MockFilmkritikenServiceMockRecorder) GetReihen(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReihen", reflect.TypeOf((*MockFilmkritikenService)(nil).GetReihen), ctx)
+}
+
 // LoadImage mocks base method.
-func (m *MockFilmkritikenService) LoadImage(ctx context.Context, imageId string) (*[]byte, error) {
+func (m *MockFilmkritikenService) LoadImage(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LoadImage", ctx, imageId)
-	ret0, _ := ret[0].(*[]byte)
+	ret := m.ctrl.Call(m, "LoadImage", ctx, imageId, width)
+	ret0, _ := ret[0].(*filmkritiken.ImageFile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LoadImage indicates an expected call of LoadImage.
-func (mr *MockFilmkritikenServiceMockRecorder) LoadImage(ctx, imageId interface{}) *gomock.Call {
+func (mr *MockFilmkritikenServiceMockRecorder) LoadImage(ctx, imageId, width interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImage", reflect.TypeOf((*MockFilmkritikenService)(nil).LoadImage), ctx, imageId, width)
+}
+
+// MergeMitglied mocks base method.
+func (m *MockFilmkritikenService) MergeMitglied(ctx context.Context, zusammenfuehrung *filmkritiken.MitgliedZusammenfuehrung, vorschau bool) (*filmkritiken.ZusammenfuehrungErgebnis, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeMitglied", ctx, zusammenfuehrung, vorschau)
+	ret0, _ := ret[0].(*filmkritiken.ZusammenfuehrungErgebnis)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MergeMitglied indicates an expected call of MergeMitglied.
+func (mr *MockFilmkritikenServiceMockRecorder) MergeMitglied(ctx, zusammenfuehrung, vorschau interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadImage", reflect.TypeOf((*MockFilmkritikenService)(nil).LoadImage), ctx, imageId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeMitglied", reflect.TypeOf((*MockFilmkritikenService)(nil).MergeMitglied), ctx, zusammenfuehrung, vorschau)
 }
 
 // OpenCloseBewertungen mocks base method.
@@ -126,18 +246,89 @@ func (mr *MockFilmkritikenServiceMockRecorder) OpenCloseBewertungen(ctx, filmkri
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenCloseBewertungen", reflect.TypeOf((*MockFilmkritikenService)(nil).OpenCloseBewertungen), ctx, filmkritikenId, offen)
 }
 
+// RemoveReiheEintrag mocks base method.
+func (m *MockFilmkritikenService) RemoveReiheEintrag(ctx context.Context, reiheId, filmkritikenId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReiheEintrag", ctx, reiheId, filmkritikenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReiheEintrag indicates an expected call of RemoveReiheEintrag.
+func (mr *MockFilmkritikenServiceMockRecorder) RemoveReiheEintrag(ctx, reiheId, filmkritikenId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReiheEintrag", reflect.TypeOf((*MockFilmkritikenService)(nil).RemoveReiheEintrag), ctx, reiheId, filmkritikenId)
+}
+
+// RenameBenutzer mocks base method.
+func (m *MockFilmkritikenService) RenameBenutzer(ctx context.Context, vonId, von string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RenameBenutzer", ctx, vonId, von)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RenameBenutzer indicates an expected call of RenameBenutzer.
+func (mr *MockFilmkritikenServiceMockRecorder) RenameBenutzer(ctx, vonId, von interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenameBenutzer", reflect.TypeOf((*MockFilmkritikenService)(nil).RenameBenutzer), ctx, vonId, von)
+}
+
+// SearchFilms mocks base method.
+func (m *MockFilmkritikenService) SearchFilms(ctx context.Context, query string) ([]*filmkritiken.FilmCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFilms", ctx, query)
+	ret0, _ := ret[0].([]*filmkritiken.FilmCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFilms indicates an expected call of SearchFilms.
+func (mr *MockFilmkritikenServiceMockRecorder) SearchFilms(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilms", reflect.TypeOf((*MockFilmkritikenService)(nil).SearchFilms), ctx, query)
+}
+
 // SetKritik mocks base method.
-func (m *MockFilmkritikenService) SetKritik(ctx context.Context, filmkritikenId, von string, bewertung int, enthaltung bool) error {
+func (m *MockFilmkritikenService) SetKritik(ctx context.Context, filmkritikenId, vonId, von string, bewertung int, enthaltung bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetKritik", ctx, filmkritikenId, von, bewertung, enthaltung)
+	ret := m.ctrl.Call(m, "SetKritik", ctx, filmkritikenId, vonId, von, bewertung, enthaltung)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetKritik indicates an expected call of SetKritik.
-func (mr *MockFilmkritikenServiceMockRecorder) SetKritik(ctx, filmkritikenId, von, bewertung, enthaltung interface{}) *gomock.Call {
+func (mr *MockFilmkritikenServiceMockRecorder) SetKritik(ctx, filmkritikenId, vonId, von, bewertung, enthaltung interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKritik", reflect.TypeOf((*MockFilmkritikenService)(nil).SetKritik), ctx, filmkritikenId, vonId, von, bewertung, enthaltung)
+}
+
+// SetKritiken mocks base method.
+func (m *MockFilmkritikenService) SetKritiken(ctx context.Context, filmkritikenId string, eingaben []*filmkritiken.BewertungEingabe) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetKritiken", ctx, filmkritikenId, eingaben)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetKritiken indicates an expected call of SetKritiken.
+func (mr *MockFilmkritikenServiceMockRecorder) SetKritiken(ctx, filmkritikenId, eingaben interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKritiken", reflect.TypeOf((*MockFilmkritikenService)(nil).SetKritiken), ctx, filmkritikenId, eingaben)
+}
+
+// SetReiheEintrag mocks base method.
+func (m *MockFilmkritikenService) SetReiheEintrag(ctx context.Context, reiheId, filmkritikenId string, position int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetReiheEintrag", ctx, reiheId, filmkritikenId, position)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetReiheEintrag indicates an expected call of SetReiheEintrag.
+func (mr *MockFilmkritikenServiceMockRecorder) SetReiheEintrag(ctx, reiheId, filmkritikenId, position interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetKritik", reflect.TypeOf((*MockFilmkritikenService)(nil).SetKritik), ctx, filmkritikenId, von, bewertung, enthaltung)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetReiheEintrag", reflect.TypeOf((*MockFilmkritikenService)(nil).SetReiheEintrag), ctx, reiheId, filmkritikenId, position)
 }
 
 // UpdateBesprochenAm mocks base method.
@@ -154,6 +345,20 @@ func (mr *MockFilmkritikenServiceMockRecorder) UpdateBesprochenAm(ctx, filmkriti
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBesprochenAm", reflect.TypeOf((*MockFilmkritikenService)(nil).UpdateBesprochenAm), ctx, filmkritikenId, besprochenAm)
 }
 
+// UpdateKategorien mocks base method.
+func (m *MockFilmkritikenService) UpdateKategorien(ctx context.Context, filmkritikenId string, genres, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKategorien", ctx, filmkritikenId, genres, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateKategorien indicates an expected call of UpdateKategorien.
+func (mr *MockFilmkritikenServiceMockRecorder) UpdateKategorien(ctx, filmkritikenId, genres, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKategorien", reflect.TypeOf((*MockFilmkritikenService)(nil).UpdateKategorien), ctx, filmkritikenId, genres, tags)
+}
+
 // MockFilmkritikenRepository is a mock of FilmkritikenRepository interface.
 type MockFilmkritikenRepository struct {
 	ctrl     *gomock.Controller
@@ -208,6 +413,51 @@ func (mr *MockFilmkritikenRepositoryMockRecorder) GetFilmkritiken(ctx, filter in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmkritiken", reflect.TypeOf((*MockFilmkritikenRepository)(nil).GetFilmkritiken), ctx, filter)
 }
 
+// GetFilmkritikenByFilm mocks base method.
+func (m *MockFilmkritikenRepository) GetFilmkritikenByFilm(ctx context.Context, filmId string) ([]*filmkritiken.Filmkritiken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmkritikenByFilm", ctx, filmId)
+	ret0, _ := ret[0].([]*filmkritiken.Filmkritiken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmkritikenByFilm indicates an expected call of GetFilmkritikenByFilm.
+func (mr *MockFilmkritikenRepositoryMockRecorder) GetFilmkritikenByFilm(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmkritikenByFilm", reflect.TypeOf((*MockFilmkritikenRepository)(nil).GetFilmkritikenByFilm), ctx, filmId)
+}
+
+// GetFilmkritikenByMitglied mocks base method.
+func (m *MockFilmkritikenRepository) GetFilmkritikenByMitglied(ctx context.Context, names []string) ([]*filmkritiken.Filmkritiken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmkritikenByMitglied", ctx, names)
+	ret0, _ := ret[0].([]*filmkritiken.Filmkritiken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmkritikenByMitglied indicates an expected call of GetFilmkritikenByMitglied.
+func (mr *MockFilmkritikenRepositoryMockRecorder) GetFilmkritikenByMitglied(ctx, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmkritikenByMitglied", reflect.TypeOf((*MockFilmkritikenRepository)(nil).GetFilmkritikenByMitglied), ctx, names)
+}
+
+// GetFilmkritikenByPerson mocks base method.
+func (m *MockFilmkritikenRepository) GetFilmkritikenByPerson(ctx context.Context, name string) ([]*filmkritiken.Filmkritiken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilmkritikenByPerson", ctx, name)
+	ret0, _ := ret[0].([]*filmkritiken.Filmkritiken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilmkritikenByPerson indicates an expected call of GetFilmkritikenByPerson.
+func (mr *MockFilmkritikenRepositoryMockRecorder) GetFilmkritikenByPerson(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilmkritikenByPerson", reflect.TypeOf((*MockFilmkritikenRepository)(nil).GetFilmkritikenByPerson), ctx, name)
+}
+
 // GetFilterOptions mocks base method.
 func (m *MockFilmkritikenRepository) GetFilterOptions(ctx context.Context) (*filmkritiken.FilterOptions, error) {
 	m.ctrl.T.Helper()
@@ -237,6 +487,20 @@ func (mr *MockFilmkritikenRepositoryMockRecorder) SaveFilmkritiken(ctx, filmkrit
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFilmkritiken", reflect.TypeOf((*MockFilmkritikenRepository)(nil).SaveFilmkritiken), ctx, filmkritiken)
 }
 
+// SaveFilmkritikenWithAudit mocks base method.
+func (m *MockFilmkritikenRepository) SaveFilmkritikenWithAudit(ctx context.Context, filmkritiken []*filmkritiken.Filmkritiken, auditEintrag *filmkritiken.AuditEintrag) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFilmkritikenWithAudit", ctx, filmkritiken, auditEintrag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFilmkritikenWithAudit indicates an expected call of SaveFilmkritikenWithAudit.
+func (mr *MockFilmkritikenRepositoryMockRecorder) SaveFilmkritikenWithAudit(ctx, filmkritiken, auditEintrag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFilmkritikenWithAudit", reflect.TypeOf((*MockFilmkritikenRepository)(nil).SaveFilmkritikenWithAudit), ctx, filmkritiken, auditEintrag)
+}
+
 // UpdateBesprochenAm mocks base method.
 func (m *MockFilmkritikenRepository) UpdateBesprochenAm(ctx context.Context, filmkritikenId string, besprochenAm time.Time) error {
 	m.ctrl.T.Helper()
@@ -251,6 +515,167 @@ func (mr *MockFilmkritikenRepositoryMockRecorder) UpdateBesprochenAm(ctx, filmkr
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBesprochenAm", reflect.TypeOf((*MockFilmkritikenRepository)(nil).UpdateBesprochenAm), ctx, filmkritikenId, besprochenAm)
 }
 
+// UpdateBewertungenVon mocks base method.
+func (m *MockFilmkritikenRepository) UpdateBewertungenVon(ctx context.Context, vonId, von string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateBewertungenVon", ctx, vonId, von)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateBewertungenVon indicates an expected call of UpdateBewertungenVon.
+func (mr *MockFilmkritikenRepositoryMockRecorder) UpdateBewertungenVon(ctx, vonId, von interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBewertungenVon", reflect.TypeOf((*MockFilmkritikenRepository)(nil).UpdateBewertungenVon), ctx, vonId, von)
+}
+
+// UpdateKategorien mocks base method.
+func (m *MockFilmkritikenRepository) UpdateKategorien(ctx context.Context, filmkritikenId string, genres, tags []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateKategorien", ctx, filmkritikenId, genres, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateKategorien indicates an expected call of UpdateKategorien.
+func (mr *MockFilmkritikenRepositoryMockRecorder) UpdateKategorien(ctx, filmkritikenId, genres, tags interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateKategorien", reflect.TypeOf((*MockFilmkritikenRepository)(nil).UpdateKategorien), ctx, filmkritikenId, genres, tags)
+}
+
+// MockFilmRepository is a mock of FilmRepository interface.
+type MockFilmRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilmRepositoryMockRecorder
+}
+
+// MockFilmRepositoryMockRecorder is the mock recorder for MockFilmRepository.
+type MockFilmRepositoryMockRecorder struct {
+	mock *MockFilmRepository
+}
+
+// NewMockFilmRepository creates a new mock instance.
+func NewMockFilmRepository(ctrl *gomock.Controller) *MockFilmRepository {
+	mock := &MockFilmRepository{ctrl: ctrl}
+	mock.recorder = &MockFilmRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFilmRepository) EXPECT() *MockFilmRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteFilm mocks base method.
+func (m *MockFilmRepository) DeleteFilm(ctx context.Context, filmId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFilm", ctx, filmId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFilm indicates an expected call of DeleteFilm.
+func (mr *MockFilmRepositoryMockRecorder) DeleteFilm(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFilm", reflect.TypeOf((*MockFilmRepository)(nil).DeleteFilm), ctx, filmId)
+}
+
+// FindFilm mocks base method.
+func (m *MockFilmRepository) FindFilm(ctx context.Context, filmId string) (*filmkritiken.Film, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFilm", ctx, filmId)
+	ret0, _ := ret[0].(*filmkritiken.Film)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFilm indicates an expected call of FindFilm.
+func (mr *MockFilmRepositoryMockRecorder) FindFilm(ctx, filmId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFilm", reflect.TypeOf((*MockFilmRepository)(nil).FindFilm), ctx, filmId)
+}
+
+// SaveFilm mocks base method.
+func (m *MockFilmRepository) SaveFilm(ctx context.Context, film *filmkritiken.Film) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFilm", ctx, film)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFilm indicates an expected call of SaveFilm.
+func (mr *MockFilmRepositoryMockRecorder) SaveFilm(ctx, film interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFilm", reflect.TypeOf((*MockFilmRepository)(nil).SaveFilm), ctx, film)
+}
+
+// MockReiheRepository is a mock of ReiheRepository interface.
+type MockReiheRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockReiheRepositoryMockRecorder
+}
+
+// MockReiheRepositoryMockRecorder is the mock recorder for MockReiheRepository.
+type MockReiheRepositoryMockRecorder struct {
+	mock *MockReiheRepository
+}
+
+// NewMockReiheRepository creates a new mock instance.
+func NewMockReiheRepository(ctrl *gomock.Controller) *MockReiheRepository {
+	mock := &MockReiheRepository{ctrl: ctrl}
+	mock.recorder = &MockReiheRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReiheRepository) EXPECT() *MockReiheRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindReihe mocks base method.
+func (m *MockReiheRepository) FindReihe(ctx context.Context, reiheId string) (*filmkritiken.Reihe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReihe", ctx, reiheId)
+	ret0, _ := ret[0].(*filmkritiken.Reihe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReihe indicates an expected call of FindReihe.
+func (mr *MockReiheRepositoryMockRecorder) FindReihe(ctx, reiheId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReihe", reflect.TypeOf((*MockReiheRepository)(nil).FindReihe), ctx, reiheId)
+}
+
+// GetReihen mocks base method.
+func (m *MockReiheRepository) GetReihen(ctx context.Context) ([]*filmkritiken.Reihe, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReihen", ctx)
+	ret0, _ := ret[0].([]*filmkritiken.Reihe)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReihen indicates an expected call of GetReihen.
+func (mr *MockReiheRepositoryMockRecorder) GetReihen(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReihen", reflect.TypeOf((*MockReiheRepository)(nil).GetReihen), ctx)
+}
+
+// SaveReihe mocks base method.
+func (m *MockReiheRepository) SaveReihe(ctx context.Context, reihe *filmkritiken.Reihe) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReihe", ctx, reihe)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReihe indicates an expected call of SaveReihe.
+func (mr *MockReiheRepositoryMockRecorder) SaveReihe(ctx, reihe interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReihe", reflect.TypeOf((*MockReiheRepository)(nil).SaveReihe), ctx, reihe)
+}
+
 // MockImageRepository is a mock of ImageRepository interface.
 type MockImageRepository struct {
 	ctrl     *gomock.Controller
@@ -289,10 +714,10 @@ func (mr *MockImageRepositoryMockRecorder) DeleteImage(ctx, id interface{}) *gom
 }
 
 // FindImage mocks base method.
-func (m *MockImageRepository) FindImage(ctx context.Context, imageId string) (*[]byte, error) {
+func (m *MockImageRepository) FindImage(ctx context.Context, imageId string) (*filmkritiken.ImageFile, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "FindImage", ctx, imageId)
-	ret0, _ := ret[0].(*[]byte)
+	ret0, _ := ret[0].(*filmkritiken.ImageFile)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -303,17 +728,328 @@ func (mr *MockImageRepositoryMockRecorder) FindImage(ctx, imageId interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindImage", reflect.TypeOf((*MockImageRepository)(nil).FindImage), ctx, imageId)
 }
 
-// SaveImage mocks base method.
-func (m *MockImageRepository) SaveImage(ctx context.Context, imageBites *[]byte) (string, error) {
+// FindImageVariant mocks base method.
+func (m *MockImageRepository) FindImageVariant(ctx context.Context, imageId string, width int) (*filmkritiken.ImageFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindImageVariant", ctx, imageId, width)
+	ret0, _ := ret[0].(*filmkritiken.ImageFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindImageVariant indicates an expected call of FindImageVariant.
+func (mr *MockImageRepositoryMockRecorder) FindImageVariant(ctx, imageId, width interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindImageVariant", reflect.TypeOf((*MockImageRepository)(nil).FindImageVariant), ctx, imageId, width)
+}
+
+// GetImageIds mocks base method.
+func (m *MockImageRepository) GetImageIds(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetImageIds", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetImageIds indicates an expected call of GetImageIds.
+func (mr *MockImageRepositoryMockRecorder) GetImageIds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetImageIds", reflect.TypeOf((*MockImageRepository)(nil).GetImageIds), ctx)
+}
+
+// ImageExists mocks base method.
+func (m *MockImageRepository) ImageExists(ctx context.Context, imageId string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveImage", ctx, imageBites)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "ImageExists", ctx, imageId)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ImageExists indicates an expected call of ImageExists.
+func (mr *MockImageRepositoryMockRecorder) ImageExists(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImageExists", reflect.TypeOf((*MockImageRepository)(nil).ImageExists), ctx, imageId)
+}
+
+// SaveImage mocks base method.
+func (m *MockImageRepository) SaveImage(ctx context.Context, imageId string, imageFile *filmkritiken.ImageFile) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveImage", ctx, imageId, imageFile)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
 // SaveImage indicates an expected call of SaveImage.
-func (mr *MockImageRepositoryMockRecorder) SaveImage(ctx, imageBites interface{}) *gomock.Call {
+func (mr *MockImageRepositoryMockRecorder) SaveImage(ctx, imageId, imageFile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImage", reflect.TypeOf((*MockImageRepository)(nil).SaveImage), ctx, imageId, imageFile)
+}
+
+// SaveImageVariants mocks base method.
+func (m *MockImageRepository) SaveImageVariants(ctx context.Context, imageId string, variants []*filmkritiken.ImageVariant) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveImageVariants", ctx, imageId, variants)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveImageVariants indicates an expected call of SaveImageVariants.
+func (mr *MockImageRepositoryMockRecorder) SaveImageVariants(ctx, imageId, variants interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImageVariants", reflect.TypeOf((*MockImageRepository)(nil).SaveImageVariants), ctx, imageId, variants)
+}
+
+// MockImageReferenceRepository is a mock of ImageReferenceRepository interface.
+type MockImageReferenceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageReferenceRepositoryMockRecorder
+}
+
+// MockImageReferenceRepositoryMockRecorder is the mock recorder for MockImageReferenceRepository.
+type MockImageReferenceRepositoryMockRecorder struct {
+	mock *MockImageReferenceRepository
+}
+
+// NewMockImageReferenceRepository creates a new mock instance.
+func NewMockImageReferenceRepository(ctrl *gomock.Controller) *MockImageReferenceRepository {
+	mock := &MockImageReferenceRepository{ctrl: ctrl}
+	mock.recorder = &MockImageReferenceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageReferenceRepository) EXPECT() *MockImageReferenceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddImageReference mocks base method.
+func (m *MockImageReferenceRepository) AddImageReference(ctx context.Context, imageId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddImageReference", ctx, imageId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddImageReference indicates an expected call of AddImageReference.
+func (mr *MockImageReferenceRepositoryMockRecorder) AddImageReference(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddImageReference", reflect.TypeOf((*MockImageReferenceRepository)(nil).AddImageReference), ctx, imageId)
+}
+
+// DeleteImageReferences mocks base method.
+func (m *MockImageReferenceRepository) DeleteImageReferences(ctx context.Context, imageId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteImageReferences", ctx, imageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteImageReferences indicates an expected call of DeleteImageReferences.
+func (mr *MockImageReferenceRepositoryMockRecorder) DeleteImageReferences(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteImageReferences", reflect.TypeOf((*MockImageReferenceRepository)(nil).DeleteImageReferences), ctx, imageId)
+}
+
+// RemoveImageReference mocks base method.
+func (m *MockImageReferenceRepository) RemoveImageReference(ctx context.Context, imageId string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveImageReference", ctx, imageId)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveImageReference indicates an expected call of RemoveImageReference.
+func (mr *MockImageReferenceRepositoryMockRecorder) RemoveImageReference(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveImageReference", reflect.TypeOf((*MockImageReferenceRepository)(nil).RemoveImageReference), ctx, imageId)
+}
+
+// ReserveImageDeletion mocks base method.
+func (m *MockImageReferenceRepository) ReserveImageDeletion(ctx context.Context, imageId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveImageDeletion", ctx, imageId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveImageDeletion indicates an expected call of ReserveImageDeletion.
+func (mr *MockImageReferenceRepositoryMockRecorder) ReserveImageDeletion(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveImageDeletion", reflect.TypeOf((*MockImageReferenceRepository)(nil).ReserveImageDeletion), ctx, imageId)
+}
+
+// MockBenutzerRepository is a mock of BenutzerRepository interface.
+type MockBenutzerRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBenutzerRepositoryMockRecorder
+}
+
+// MockBenutzerRepositoryMockRecorder is the mock recorder for MockBenutzerRepository.
+type MockBenutzerRepositoryMockRecorder struct {
+	mock *MockBenutzerRepository
+}
+
+// NewMockBenutzerRepository creates a new mock instance.
+func NewMockBenutzerRepository(ctrl *gomock.Controller) *MockBenutzerRepository {
+	mock := &MockBenutzerRepository{ctrl: ctrl}
+	mock.recorder = &MockBenutzerRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBenutzerRepository) EXPECT() *MockBenutzerRepositoryMockRecorder {
+	return m.recorder
+}
+
+// BenutzerExists mocks base method.
+func (m *MockBenutzerRepository) BenutzerExists(ctx context.Context, vonId string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BenutzerExists", ctx, vonId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BenutzerExists indicates an expected call of BenutzerExists.
+func (mr *MockBenutzerRepositoryMockRecorder) BenutzerExists(ctx, vonId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BenutzerExists", reflect.TypeOf((*MockBenutzerRepository)(nil).BenutzerExists), ctx, vonId)
+}
+
+// MergeBenutzer mocks base method.
+func (m *MockBenutzerRepository) MergeBenutzer(ctx context.Context, benutzerId, mergedBenutzerId string, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeBenutzer", ctx, benutzerId, mergedBenutzerId, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeBenutzer indicates an expected call of MergeBenutzer.
+func (mr *MockBenutzerRepositoryMockRecorder) MergeBenutzer(ctx, benutzerId, mergedBenutzerId, names interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeBenutzer", reflect.TypeOf((*MockBenutzerRepository)(nil).MergeBenutzer), ctx, benutzerId, mergedBenutzerId, names)
+}
+
+// MockOrphanedImageRepository is a mock of OrphanedImageRepository interface.
+type MockOrphanedImageRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrphanedImageRepositoryMockRecorder
+}
+
+// MockOrphanedImageRepositoryMockRecorder is the mock recorder for MockOrphanedImageRepository.
+type MockOrphanedImageRepositoryMockRecorder struct {
+	mock *MockOrphanedImageRepository
+}
+
+// NewMockOrphanedImageRepository creates a new mock instance.
+func NewMockOrphanedImageRepository(ctrl *gomock.Controller) *MockOrphanedImageRepository {
+	mock := &MockOrphanedImageRepository{ctrl: ctrl}
+	mock.recorder = &MockOrphanedImageRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrphanedImageRepository) EXPECT() *MockOrphanedImageRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOrphanedImages mocks base method.
+func (m *MockOrphanedImageRepository) GetOrphanedImages(ctx context.Context) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrphanedImages", ctx)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrphanedImages indicates an expected call of GetOrphanedImages.
+func (mr *MockOrphanedImageRepositoryMockRecorder) GetOrphanedImages(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrphanedImages", reflect.TypeOf((*MockOrphanedImageRepository)(nil).GetOrphanedImages), ctx)
+}
+
+// MarkOrphanedImage mocks base method.
+func (m *MockOrphanedImageRepository) MarkOrphanedImage(ctx context.Context, imageId string, since time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOrphanedImage", ctx, imageId, since)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOrphanedImage indicates an expected call of MarkOrphanedImage.
+func (mr *MockOrphanedImageRepositoryMockRecorder) MarkOrphanedImage(ctx, imageId, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOrphanedImage", reflect.TypeOf((*MockOrphanedImageRepository)(nil).MarkOrphanedImage), ctx, imageId, since)
+}
+
+// UnmarkOrphanedImage mocks base method.
+func (m *MockOrphanedImageRepository) UnmarkOrphanedImage(ctx context.Context, imageId string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarkOrphanedImage", ctx, imageId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarkOrphanedImage indicates an expected call of UnmarkOrphanedImage.
+func (mr *MockOrphanedImageRepositoryMockRecorder) UnmarkOrphanedImage(ctx, imageId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarkOrphanedImage", reflect.TypeOf((*MockOrphanedImageRepository)(nil).UnmarkOrphanedImage), ctx, imageId)
+}
+
+// MockFilmMetadataProvider is a mock of FilmMetadataProvider interface.
+type MockFilmMetadataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilmMetadataProviderMockRecorder
+}
+
+// MockFilmMetadataProviderMockRecorder is the mock recorder for MockFilmMetadataProvider.
+type MockFilmMetadataProviderMockRecorder struct {
+	mock *MockFilmMetadataProvider
+}
+
+// NewMockFilmMetadataProvider creates a new mock instance.
+func NewMockFilmMetadataProvider(ctrl *gomock.Controller) *MockFilmMetadataProvider {
+	mock := &MockFilmMetadataProvider{ctrl: ctrl}
+	mock.recorder = &MockFilmMetadataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFilmMetadataProvider) EXPECT() *MockFilmMetadataProviderMockRecorder {
+	return m.recorder
+}
+
+// DownloadPoster mocks base method.
+func (m *MockFilmMetadataProvider) DownloadPoster(ctx context.Context, posterRef string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadPoster", ctx, posterRef)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadPoster indicates an expected call of DownloadPoster.
+func (mr *MockFilmMetadataProviderMockRecorder) DownloadPoster(ctx, posterRef interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadPoster", reflect.TypeOf((*MockFilmMetadataProvider)(nil).DownloadPoster), ctx, posterRef)
+}
+
+// SearchFilms mocks base method.
+func (m *MockFilmMetadataProvider) SearchFilms(ctx context.Context, query string) ([]*filmkritiken.FilmCandidate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchFilms", ctx, query)
+	ret0, _ := ret[0].([]*filmkritiken.FilmCandidate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchFilms indicates an expected call of SearchFilms.
+func (mr *MockFilmMetadataProviderMockRecorder) SearchFilms(ctx, query interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveImage", reflect.TypeOf((*MockImageRepository)(nil).SaveImage), ctx, imageBites)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchFilms", reflect.TypeOf((*MockFilmMetadataProvider)(nil).SearchFilms), ctx, query)
 }
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: PermissionService.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	session "github.com/DerBlum/filmkritiken-backend/domain/session"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPermissionService is a mock of PermissionService interface.
+type MockPermissionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockPermissionServiceMockRecorder
+}
+
+// MockPermissionServiceMockRecorder is the mock recorder for MockPermissionService.
+type MockPermissionServiceMockRecorder struct {
+	mock *MockPermissionService
+}
+
+// NewMockPermissionService creates a new mock instance.
+func NewMockPermissionService(ctrl *gomock.Controller) *MockPermissionService {
+	mock := &MockPermissionService{ctrl: ctrl}
+	mock.recorder = &MockPermissionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPermissionService) EXPECT() *MockPermissionServiceMockRecorder {
+	return m.recorder
+}
+
+// DeleteRole mocks base method.
+func (m *MockPermissionService) DeleteRole(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockPermissionServiceMockRecorder) DeleteRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockPermissionService)(nil).DeleteRole), ctx, name)
+}
+
+// GetRoles mocks base method.
+func (m *MockPermissionService) GetRoles(ctx context.Context) ([]*session.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].([]*session.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockPermissionServiceMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockPermissionService)(nil).GetRoles), ctx)
+}
+
+// GetUsers mocks base method.
+func (m *MockPermissionService) GetUsers(ctx context.Context) ([]*session.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx)
+	ret0, _ := ret[0].([]*session.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockPermissionServiceMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockPermissionService)(nil).GetUsers), ctx)
+}
+
+// ResolvePermissions mocks base method.
+func (m *MockPermissionService) ResolvePermissions(ctx context.Context, userID string, idpRoles []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolvePermissions", ctx, userID, idpRoles)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResolvePermissions indicates an expected call of ResolvePermissions.
+func (mr *MockPermissionServiceMockRecorder) ResolvePermissions(ctx, userID, idpRoles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolvePermissions", reflect.TypeOf((*MockPermissionService)(nil).ResolvePermissions), ctx, userID, idpRoles)
+}
+
+// SaveRole mocks base method.
+func (m *MockPermissionService) SaveRole(ctx context.Context, name string, permissions []string) (*session.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRole", ctx, name, permissions)
+	ret0, _ := ret[0].(*session.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveRole indicates an expected call of SaveRole.
+func (mr *MockPermissionServiceMockRecorder) SaveRole(ctx, name, permissions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRole", reflect.TypeOf((*MockPermissionService)(nil).SaveRole), ctx, name, permissions)
+}
+
+// SetUserRoles mocks base method.
+func (m *MockPermissionService) SetUserRoles(ctx context.Context, userID string, roles []string) (*session.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRoles", ctx, userID, roles)
+	ret0, _ := ret[0].(*session.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRoles indicates an expected call of SetUserRoles.
+func (mr *MockPermissionServiceMockRecorder) SetUserRoles(ctx, userID, roles interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockPermissionService)(nil).SetUserRoles), ctx, userID, roles)
+}
@@ -50,6 +50,35 @@ func (mr *MockSessionRepositoryMockRecorder) DeleteSession(ctx, sessionID interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSession", reflect.TypeOf((*MockSessionRepository)(nil).DeleteSession), ctx, sessionID)
 }
 
+// DeleteSessionByHash mocks base method.
+func (m *MockSessionRepository) DeleteSessionByHash(ctx context.Context, userID, hashedID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessionByHash", ctx, userID, hashedID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSessionByHash indicates an expected call of DeleteSessionByHash.
+func (mr *MockSessionRepositoryMockRecorder) DeleteSessionByHash(ctx, userID, hashedID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessionByHash", reflect.TypeOf((*MockSessionRepository)(nil).DeleteSessionByHash), ctx, userID, hashedID)
+}
+
+// DeleteSessions mocks base method.
+func (m *MockSessionRepository) DeleteSessions(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSessions", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteSessions indicates an expected call of DeleteSessions.
+func (mr *MockSessionRepositoryMockRecorder) DeleteSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSessions", reflect.TypeOf((*MockSessionRepository)(nil).DeleteSessions), ctx, userID)
+}
+
 // FindSession mocks base method.
 func (m *MockSessionRepository) FindSession(ctx context.Context, sessionID string) (*session.Session, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +94,21 @@ func (mr *MockSessionRepositoryMockRecorder) FindSession(ctx, sessionID interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSession", reflect.TypeOf((*MockSessionRepository)(nil).FindSession), ctx, sessionID)
 }
 
+// GetSessions mocks base method.
+func (m *MockSessionRepository) GetSessions(ctx context.Context, userID string) ([]*session.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", ctx, userID)
+	ret0, _ := ret[0].([]*session.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockSessionRepositoryMockRecorder) GetSessions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockSessionRepository)(nil).GetSessions), ctx, userID)
+}
+
 // RefreshSession mocks base method.
 func (m *MockSessionRepository) RefreshSession(ctx context.Context, sessionID string, duration time.Duration) error {
 	m.ctrl.T.Helper()
@@ -92,3 +136,275 @@ func (mr *MockSessionRepositoryMockRecorder) SaveSession(ctx, session interface{
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSession", reflect.TypeOf((*MockSessionRepository)(nil).SaveSession), ctx, session)
 }
+
+// UpdateRefreshedSession mocks base method.
+func (m *MockSessionRepository) UpdateRefreshedSession(ctx context.Context, session *session.Session, previousRefreshToken string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefreshedSession", ctx, session, previousRefreshToken)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRefreshedSession indicates an expected call of UpdateRefreshedSession.
+func (mr *MockSessionRepositoryMockRecorder) UpdateRefreshedSession(ctx, session, previousRefreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefreshedSession", reflect.TypeOf((*MockSessionRepository)(nil).UpdateRefreshedSession), ctx, session, previousRefreshToken)
+}
+
+// UpdateSessionLastSeen mocks base method.
+func (m *MockSessionRepository) UpdateSessionLastSeen(ctx context.Context, sessionID string, lastSeenAt time.Time, ip string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSessionLastSeen", ctx, sessionID, lastSeenAt, ip)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSessionLastSeen indicates an expected call of UpdateSessionLastSeen.
+func (mr *MockSessionRepositoryMockRecorder) UpdateSessionLastSeen(ctx, sessionID, lastSeenAt, ip interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSessionLastSeen", reflect.TypeOf((*MockSessionRepository)(nil).UpdateSessionLastSeen), ctx, sessionID, lastSeenAt, ip)
+}
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// FindUser mocks base method.
+func (m *MockUserRepository) FindUser(ctx context.Context, userID string) (*session.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUser", ctx, userID)
+	ret0, _ := ret[0].(*session.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUser indicates an expected call of FindUser.
+func (mr *MockUserRepositoryMockRecorder) FindUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUser", reflect.TypeOf((*MockUserRepository)(nil).FindUser), ctx, userID)
+}
+
+// GetUsers mocks base method.
+func (m *MockUserRepository) GetUsers(ctx context.Context) ([]*session.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsers", ctx)
+	ret0, _ := ret[0].([]*session.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsers indicates an expected call of GetUsers.
+func (mr *MockUserRepositoryMockRecorder) GetUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsers", reflect.TypeOf((*MockUserRepository)(nil).GetUsers), ctx)
+}
+
+// SaveUser mocks base method.
+func (m *MockUserRepository) SaveUser(ctx context.Context, user *session.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockUserRepositoryMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserRepository)(nil).SaveUser), ctx, user)
+}
+
+// MockRoleRepository is a mock of RoleRepository interface.
+type MockRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepositoryMockRecorder
+}
+
+// MockRoleRepositoryMockRecorder is the mock recorder for MockRoleRepository.
+type MockRoleRepositoryMockRecorder struct {
+	mock *MockRoleRepository
+}
+
+// NewMockRoleRepository creates a new mock instance.
+func NewMockRoleRepository(ctrl *gomock.Controller) *MockRoleRepository {
+	mock := &MockRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleRepository) EXPECT() *MockRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteRole mocks base method.
+func (m *MockRoleRepository) DeleteRole(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockRoleRepositoryMockRecorder) DeleteRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockRoleRepository)(nil).DeleteRole), ctx, name)
+}
+
+// GetRoles mocks base method.
+func (m *MockRoleRepository) GetRoles(ctx context.Context) ([]*session.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoles", ctx)
+	ret0, _ := ret[0].([]*session.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoles indicates an expected call of GetRoles.
+func (mr *MockRoleRepositoryMockRecorder) GetRoles(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoles", reflect.TypeOf((*MockRoleRepository)(nil).GetRoles), ctx)
+}
+
+// SaveRole mocks base method.
+func (m *MockRoleRepository) SaveRole(ctx context.Context, role *session.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRole", ctx, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRole indicates an expected call of SaveRole.
+func (mr *MockRoleRepositoryMockRecorder) SaveRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRole", reflect.TypeOf((*MockRoleRepository)(nil).SaveRole), ctx, role)
+}
+
+// MockTokenRepository is a mock of TokenRepository interface.
+type MockTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRepositoryMockRecorder
+}
+
+// MockTokenRepositoryMockRecorder is the mock recorder for MockTokenRepository.
+type MockTokenRepositoryMockRecorder struct {
+	mock *MockTokenRepository
+}
+
+// NewMockTokenRepository creates a new mock instance.
+func NewMockTokenRepository(ctrl *gomock.Controller) *MockTokenRepository {
+	mock := &MockTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRepository) EXPECT() *MockTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteToken mocks base method.
+func (m *MockTokenRepository) DeleteToken(ctx context.Context, userID, tokenID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteToken", ctx, userID, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteToken indicates an expected call of DeleteToken.
+func (mr *MockTokenRepositoryMockRecorder) DeleteToken(ctx, userID, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToken", reflect.TypeOf((*MockTokenRepository)(nil).DeleteToken), ctx, userID, tokenID)
+}
+
+// DeleteTokens mocks base method.
+func (m *MockTokenRepository) DeleteTokens(ctx context.Context, userID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTokens", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteTokens indicates an expected call of DeleteTokens.
+func (mr *MockTokenRepositoryMockRecorder) DeleteTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTokens", reflect.TypeOf((*MockTokenRepository)(nil).DeleteTokens), ctx, userID)
+}
+
+// FindTokenByHash mocks base method.
+func (m *MockTokenRepository) FindTokenByHash(ctx context.Context, hash string) (*session.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindTokenByHash", ctx, hash)
+	ret0, _ := ret[0].(*session.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindTokenByHash indicates an expected call of FindTokenByHash.
+func (mr *MockTokenRepositoryMockRecorder) FindTokenByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindTokenByHash", reflect.TypeOf((*MockTokenRepository)(nil).FindTokenByHash), ctx, hash)
+}
+
+// GetTokens mocks base method.
+func (m *MockTokenRepository) GetTokens(ctx context.Context, userID string) ([]*session.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokens", ctx, userID)
+	ret0, _ := ret[0].([]*session.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokens indicates an expected call of GetTokens.
+func (mr *MockTokenRepositoryMockRecorder) GetTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokens", reflect.TypeOf((*MockTokenRepository)(nil).GetTokens), ctx, userID)
+}
+
+// SaveToken mocks base method.
+func (m *MockTokenRepository) SaveToken(ctx context.Context, token *session.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveToken indicates an expected call of SaveToken.
+func (mr *MockTokenRepositoryMockRecorder) SaveToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveToken", reflect.TypeOf((*MockTokenRepository)(nil).SaveToken), ctx, token)
+}
+
+// UpdateTokenLastUsed mocks base method.
+func (m *MockTokenRepository) UpdateTokenLastUsed(ctx context.Context, tokenID string, lastUsedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTokenLastUsed", ctx, tokenID, lastUsedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTokenLastUsed indicates an expected call of UpdateTokenLastUsed.
+func (mr *MockTokenRepositoryMockRecorder) UpdateTokenLastUsed(ctx, tokenID, lastUsedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTokenLastUsed", reflect.TypeOf((*MockTokenRepository)(nil).UpdateTokenLastUsed), ctx, tokenID, lastUsedAt)
+}